@@ -1,22 +1,132 @@
 // Package api implements an API server.
 package api
 
-import "net/http"
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+)
 
 // Endpoint represents an API action which is served in
 // a path and constructs an HTTP response by http.Handler
+// Middlewares wrap the handler of the endpoint only.
+// If Uncleaned is set, the path must end with a slash and the requests under it are
+// served as they are rather than redirected to their cleaned paths, e.g. the paths
+// carrying percent-encoded keys which contain "//" or "..".
 type Endpoint struct{
 	Path string
 	Handler http.Handler
+	Middlewares []Middleware
+	Uncleaned bool
 }
 
-// Start creates the API actions given as Endpoint.
-// Then, serves the API on the specified address.
-func Start(address string, endpoints ...Endpoint) error {
+// Server serves the API actions given as Endpoint.
+// It can be stopped gracefully, waiting for the in-flight requests.
+type Server struct {
+	httpServer *http.Server
+	errs       chan error
+}
+
+// NewServer creates a server serving the API actions on the specified address.
+func NewServer(address string, endpoints ...Endpoint) *Server {
+	router := router{mux: http.NewServeMux()}
 	for _, endpoint := range endpoints {
-		http.Handle(endpoint.Path, endpoint.Handler)
+		handler := Chain(endpoint.Handler, endpoint.Middlewares...)
+		router.mux.Handle(endpoint.Path, handler)
+		if endpoint.Uncleaned {
+			router.uncleaned = append(router.uncleaned, Endpoint{Path: endpoint.Path, Handler: handler})
+		}
+	}
+
+	return &Server{
+		httpServer: &http.Server{Addr: address, Handler: router},
+		errs:       make(chan error, 1),
+	}
+}
+
+// router serves the requests by the mux, except the requests with uncleaned paths
+// under the uncleaned endpoints which the mux would redirect.
+type router struct {
+	mux       *http.ServeMux
+	uncleaned []Endpoint
+}
+
+func (r router) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != cleanPath(req.URL.Path) {
+		for _, endpoint := range r.uncleaned {
+			if strings.HasPrefix(req.URL.Path, endpoint.Path) {
+				endpoint.Handler.ServeHTTP(rw, req)
+				return
+			}
+		}
+	}
+
+	r.mux.ServeHTTP(rw, req)
+}
+
+// cleanPath returns the path as the mux cleans it, keeping the trailing slash.
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+
+	return cleaned
+}
+
+// Use wraps all the endpoints of the server by the middlewares, they run before
+// the middlewares of the endpoints. It must be called before the server is started.
+func (s *Server) Use(middlewares ...Middleware) {
+	s.httpServer.Handler = Chain(s.httpServer.Handler, middlewares...)
+}
+
+// UseTLS serves the requests over TLS by the configuration, which must provide the certificates
+// by Certificates or GetCertificate. It must be called before the server is started.
+func (s *Server) UseTLS(config *tls.Config) {
+	s.httpServer.TLSConfig = config
+}
+
+// Start listens on the address of the server and serves the requests in the background.
+// The errors occurred after the server started are sent to Errors channel.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		var err error
+		if s.httpServer.TLSConfig != nil {
+			err = s.httpServer.ServeTLS(listener, "", "")
+		} else {
+			err = s.httpServer.Serve(listener)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			s.errs <- err
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops accepting new requests and waits for the in-flight requests
+// until the deadline of the context. The connections still in use are closed
+// when the deadline is exceeded.
+func (s *Server) Stop(ctx context.Context) error {
+	err := s.httpServer.Shutdown(ctx)
+	if err != nil {
+		// the requests which are not completed in time are aborted.
+		s.httpServer.Close()
 	}
 
-	err := http.ListenAndServe(address, nil)
 	return err
-}
\ No newline at end of file
+}
+
+// Errors returns the channel receiving the error which stopped the server unexpectedly.
+func (s *Server) Errors() <-chan error {
+	return s.errs
+}
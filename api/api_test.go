@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServer_StopDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+		rw.Write([]byte("done"))
+	})
+
+	server := NewServer(freeAddress(t), Endpoint{Path: "/slow", Handler: handler})
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + server.httpServer.Addr + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+
+		content, _ := ioutil.ReadAll(resp.Body)
+		body <- string(content)
+	}()
+	<-started
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Stop(context.Background())
+	}()
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("server stopped before the in-flight request completed: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-stopped; err != nil {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, nil)
+	}
+
+	if got := <-body; got != "done" {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", got, "done")
+	}
+}
+
+func TestServer_StopDeadline(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-req.Context().Done()
+	})
+
+	server := NewServer(freeAddress(t), Endpoint{Path: "/stuck", Handler: handler})
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	go http.Get("http://" + server.httpServer.Addr + "/stuck")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := server.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, context.DeadlineExceeded)
+	}
+}
+
+// freeAddress returns a local address which is not in use.
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func TestServer_ServesUncleanedPaths(t *testing.T) {
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Write([]byte(name + " " + req.URL.EscapedPath()))
+		})
+	}
+
+	server := NewServer("",
+		Endpoint{Path: "/keys/", Handler: handler("keys"), Uncleaned: true},
+		Endpoint{Path: "/keys/list", Handler: handler("list")},
+		Endpoint{Path: "/records/", Handler: handler("records")},
+	)
+
+	tests := []struct {
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"/keys/a%2F%2Fb", http.StatusOK, "keys /keys/a%2F%2Fb"},
+		{"/keys/a/../b", http.StatusOK, "keys /keys/a/../b"},
+		{"/keys/a/./b", http.StatusOK, "keys /keys/a/./b"},
+		{"/keys/list", http.StatusOK, "list /keys/list"},
+		{"/keys/a/../list", http.StatusOK, "keys /keys/a/../list"},
+		{"/records/a//b", http.StatusMovedPermanently, ""},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		rw := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rw, req)
+
+		if rw.Code != test.expectedCode {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.path, rw.Code, test.expectedCode)
+		}
+
+		if test.expectedBody != "" && rw.Body.String() != test.expectedBody {
+			t.Errorf("returned incorrect response body for %v. got: %v, expected: %v", test.path, rw.Body.String(), test.expectedBody)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request
+// does not carry the credentials it authenticates.
+var ErrNoCredentials = errors.New("no credentials")
+
+// ErrInvalidCredentials is returned by an Authenticator when the credentials
+// of the request are not valid, expired or revoked.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Principal represents an authenticated client. Namespace is the in-memory
+// namespace the client is restricted to, it is empty if the client is not restricted.
+type Principal struct {
+	Subject   string
+	Scopes    []string
+	Namespace string
+}
+
+// namespaceNamePattern matches the valid names of the namespaces,
+// they must not contain the special characters of the key patterns.
+var namespaceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ValidNamespace checks whether the name is a valid name of an in-memory namespace.
+func ValidNamespace(name string) bool {
+	return namespaceNamePattern.MatchString(name)
+}
+
+// HasScope checks whether the principal is granted the scope.
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authenticator authenticates the client of a request by its credentials.
+type Authenticator interface {
+	Authenticate(req *http.Request) (Principal, error)
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal returns a copy of the context carrying the authenticated client.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the client authenticated by Authenticate middleware.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
+
+// Authenticate authenticates the requests by the first authenticator finding
+// its credentials in the request, and stores the client in the request context.
+// The requests without valid credentials are responded 401 Unauthorized, and
+// 503 Service Unavailable if the credentials could not be verified.
+func Authenticate(authenticators ...Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(req)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+
+				if err != nil {
+					log.Printf("Error on authenticating %v %v (request id: %v): %v",
+						req.Method, req.URL.Path, RequestIDFromContext(req.Context()), err)
+					// the credentials which cannot be looked up are not rejected as invalid.
+					if !errors.Is(err, ErrInvalidCredentials) {
+						writeError(rw, http.StatusServiceUnavailable, "credentials could not be verified.")
+						return
+					}
+					writeError(rw, http.StatusUnauthorized, "credentials are not valid.")
+					return
+				}
+
+				next.ServeHTTP(rw, req.WithContext(ContextWithPrincipal(req.Context(), principal)))
+				return
+			}
+
+			writeError(rw, http.StatusUnauthorized, "credentials are missing.")
+		})
+	}
+}
+
+// RequireScope rejects the requests of the clients which are not granted the scope
+// of the request with 403 Forbidden. The requests of the safe methods, GET, HEAD and
+// OPTIONS, require the read scope and the others require the write scope.
+// It must be used after Authenticate middleware.
+func RequireScope(read, write string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			scope := write
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				scope = read
+			}
+
+			principal, ok := PrincipalFromContext(req.Context())
+			if !ok {
+				writeError(rw, http.StatusUnauthorized, "credentials are missing.")
+				return
+			}
+
+			if !principal.HasScope(scope) {
+				writeError(rw, http.StatusForbidden, "credentials are not granted the "+scope+" scope.")
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// writeError responds the error message in JSON.
+func writeError(rw http.ResponseWriter, statusCode int, message string) {
+	body, err := json.Marshal(map[string]string{"error": message})
+	if err != nil {
+		log.Printf("Error on marshalling to JSON: %v", err)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(statusCode)
+	if _, err := rw.Write(body); err != nil {
+		log.Printf("Error on writing response: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockAuthenticator struct {
+	AuthenticateMock func(req *http.Request) (Principal, error)
+}
+
+func (m mockAuthenticator) Authenticate(req *http.Request) (Principal, error) {
+	return m.AuthenticateMock(req)
+}
+
+// headerAuthenticator authenticates the requests carrying the header with the value.
+func headerAuthenticator(header, value string, principal Principal) Authenticator {
+	return mockAuthenticator{AuthenticateMock: func(req *http.Request) (Principal, error) {
+		switch req.Header.Get(header) {
+		case "":
+			return Principal{}, ErrNoCredentials
+		case value:
+			return principal, nil
+		case "unavailable":
+			return Principal{}, errors.New("connection refused")
+		default:
+			return Principal{}, fmt.Errorf("%w: unknown %v", ErrInvalidCredentials, header)
+		}
+	}}
+}
+
+func TestAuthenticate(t *testing.T) {
+	handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		principal, _ := PrincipalFromContext(req.Context())
+		rw.Write([]byte(principal.Subject))
+	}), Authenticate(
+		headerAuthenticator("X-API-Key", "key", Principal{Subject: "apikey"}),
+		headerAuthenticator("Authorization", "Bearer token", Principal{Subject: "token"}),
+	))
+
+	tests := []struct {
+		header     string
+		value      string
+		statusCode int
+		body       string
+	}{
+		{"X-API-Key", "key", http.StatusOK, "apikey"},
+		{"Authorization", "Bearer token", http.StatusOK, "token"},
+		{"X-API-Key", "wrong", http.StatusUnauthorized, `{"error":"credentials are not valid."}`},
+		{"X-API-Key", "unavailable", http.StatusServiceUnavailable, `{"error":"credentials could not be verified."}`},
+		{"", "", http.StatusUnauthorized, `{"error":"credentials are missing."}`},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/records", nil)
+		if test.header != "" {
+			req.Header.Set(test.header, test.value)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.value, rr.Code, test.statusCode)
+		}
+
+		if rr.Body.String() != test.body {
+			t.Errorf("returned incorrect body for %v. got: %v, expected: %v", test.value, rr.Body.String(), test.body)
+		}
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		Authenticate(headerAuthenticator("X-API-Key", "key", Principal{Subject: "apikey", Scopes: []string{"kv:read"}})),
+		RequireScope("kv:read", "kv:write"),
+	)
+
+	tests := []struct {
+		method     string
+		statusCode int
+		body       string
+	}{
+		{http.MethodGet, http.StatusOK, ""},
+		{http.MethodHead, http.StatusOK, ""},
+		{http.MethodPost, http.StatusForbidden, `{"error":"credentials are not granted the kv:write scope."}`},
+		{http.MethodDelete, http.StatusForbidden, `{"error":"credentials are not granted the kv:write scope."}`},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/in-memory", nil)
+		req.Header.Set("X-API-Key", "key")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.method, rr.Code, test.statusCode)
+		}
+
+		if rr.Body.String() != test.body {
+			t.Errorf("returned incorrect body for %v. got: %v, expected: %v", test.method, rr.Body.String(), test.body)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Priority is the priority of a request while the concurrency limit is reached.
+type Priority int
+
+// Priorities of the requests. The sheddable requests are rejected as soon as the limit
+// is reached, the others wait in the queue where the critical requests are admitted
+// first and they take the places of the normal requests if the queue is full.
+const (
+	PrioritySheddable Priority = iota
+	PriorityNormal
+	PriorityCritical
+)
+
+// ErrLimitExceeded is returned by a ConcurrencyLimiter when a request is shed.
+var ErrLimitExceeded = errors.New("concurrency limit is exceeded")
+
+// ConcurrencyLimiter limits the number of the requests handled concurrently. Acquire blocks until
+// the request is admitted, the request must be released with whether the handler was overloaded.
+type ConcurrencyLimiter interface {
+	Acquire(ctx context.Context, priority Priority) (release func(overloaded bool), err error)
+}
+
+// LimitConcurrency limits the requests handled concurrently by the limiter. The requests which are
+// not admitted are responded 503 Service Unavailable with Retry-After header. The responses with
+// 5xx status codes are reported to the limiter as the signs of overload.
+func LimitConcurrency(limiter ConcurrencyLimiter, priority func(req *http.Request) Priority) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			release, err := limiter.Acquire(req.Context(), priority(req))
+			if err != nil {
+				log.Printf("Shedding %v %v (request id: %v): %v",
+					req.Method, req.URL.Path, RequestIDFromContext(req.Context()), err)
+				rw.Header().Set("Retry-After", "1")
+				writeError(rw, http.StatusServiceUnavailable, "the server is overloaded, the request is shed.")
+				return
+			}
+
+			recorder := newStatusRecorder(rw)
+			defer func() {
+				release(recorder.StatusCode() >= http.StatusInternalServerError)
+			}()
+
+			next.ServeHTTP(recorder, req)
+		})
+	}
+}
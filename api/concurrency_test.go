@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockConcurrencyLimiter struct {
+	AcquireMock func(ctx context.Context, priority Priority) (func(overloaded bool), error)
+}
+
+func (m mockConcurrencyLimiter) Acquire(ctx context.Context, priority Priority) (func(overloaded bool), error) {
+	return m.AcquireMock(ctx, priority)
+}
+
+func TestLimitConcurrency(t *testing.T) {
+	tests := []struct {
+		method     string
+		handler    int
+		admitted   bool
+		statusCode int
+		overloaded bool
+		body       string
+	}{
+		{http.MethodGet, http.StatusOK, true, http.StatusOK, false, ""},
+		{http.MethodPost, http.StatusInternalServerError, true, http.StatusInternalServerError, true, ""},
+		{http.MethodPost, http.StatusOK, false, http.StatusServiceUnavailable, false, `{"error":"the server is overloaded, the request is shed."}`},
+	}
+
+	for _, test := range tests {
+		var priority Priority
+		released, overloaded := false, false
+		limiter := mockConcurrencyLimiter{AcquireMock: func(ctx context.Context, p Priority) (func(overloaded bool), error) {
+			priority = p
+			if !test.admitted {
+				return nil, fmt.Errorf("%w: the queue is full", ErrLimitExceeded)
+			}
+
+			return func(o bool) {
+				released, overloaded = true, o
+			}, nil
+		}}
+
+		handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(test.handler)
+		}), LimitConcurrency(limiter, func(req *http.Request) Priority {
+			if req.Method == http.MethodGet {
+				return PriorityCritical
+			}
+			return PriorityNormal
+		}))
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(test.method, "/in-memory", nil))
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.method, rr.Code, test.statusCode)
+		}
+
+		if rr.Body.String() != test.body {
+			t.Errorf("returned incorrect response body for %v. got: %v, expected: %v", test.method, rr.Body.String(), test.body)
+		}
+
+		if expected := map[string]Priority{http.MethodGet: PriorityCritical, http.MethodPost: PriorityNormal}[test.method]; priority != expected {
+			t.Errorf("returned incorrect priority for %v. got: %v, expected: %v", test.method, priority, expected)
+		}
+
+		if released != test.admitted || overloaded != test.overloaded {
+			t.Errorf("returned incorrect release for %v. got: %v %v, expected: %v %v", test.method, released, overloaded, test.admitted, test.overloaded)
+		}
+
+		if retryAfter := rr.Header().Get("Retry-After"); (retryAfter == "1") == test.admitted {
+			t.Errorf("returned incorrect Retry-After header for %v. got: %q", test.method, retryAfter)
+		}
+	}
+}
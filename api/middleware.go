@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// RequestIDHeader is the header carrying the id of a request.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest request id accepted from the clients.
+const maxRequestIDLength = 128
+
+// Middleware wraps a handler to perform an action before or after it.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps the handler by the middlewares. The first middleware
+// is the outermost one, it receives the requests first.
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+type requestIDContextKey struct{}
+
+// RequestIDFromContext returns the id of the request set by RequestID middleware.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// RequestID assigns an id to each request, which is taken from X-Request-ID header
+// if the client sends a valid one, otherwise it is generated randomly.
+// The id is stored in the request context and sent back in the response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		rw.Header().Set(RequestIDHeader, id)
+		ctx := context.WithValue(req.Context(), requestIDContextKey{}, id)
+		next.ServeHTTP(rw, req.WithContext(ctx))
+	})
+}
+
+// validRequestID checks whether the id is short and consists of printable ASCII characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Printf("Error on generating a request id: %v", err)
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// JSON sets the content type of the responses to JSON, the handlers
+// serving other content types override it by setting the header.
+func JSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// Recover recovers the panics occurred while handling the requests and
+// responds an internal server error in JSON if the response is not sent yet.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recorder := newStatusRecorder(rw)
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			// the handlers abort the responses deliberately by ErrAbortHandler.
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			log.Printf("Panic while handling %v %v (request id: %v): %v\n%s",
+				req.Method, req.URL.Path, RequestIDFromContext(req.Context()), recovered, debug.Stack())
+			if recorder.status != 0 {
+				return
+			}
+
+			recorder.Header().Set("Content-Type", "application/json")
+			recorder.WriteHeader(http.StatusInternalServerError)
+			_, err := recorder.Write([]byte("{\"error\":\"internal server error occurred.\"}"))
+			if err != nil {
+				log.Printf("Error on writing response: %v", err)
+			}
+		}()
+
+		next.ServeHTTP(recorder, req)
+	})
+}
+
+// accessLogEntry represents a line of the access log.
+type accessLogEntry struct {
+	Time       string  `json:"time"`
+	RequestID  string  `json:"requestId,omitempty"`
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Query      string  `json:"query,omitempty"`
+	Status     int     `json:"status"`
+	Bytes      int64   `json:"bytes"`
+	LatencyMs  float64 `json:"latencyMs"`
+	RemoteAddr string  `json:"remoteAddr"`
+	UserAgent  string  `json:"userAgent,omitempty"`
+}
+
+// AccessLog writes a JSON line to w for each request with the status code,
+// the size and the latency of the response.
+func AccessLog(w io.Writer) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			recorder := newStatusRecorder(rw)
+			next.ServeHTTP(recorder, req)
+
+			entry := accessLogEntry{
+				Time:       start.UTC().Format(time.RFC3339Nano),
+				RequestID:  RequestIDFromContext(req.Context()),
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Query:      req.URL.RawQuery,
+				Status:     recorder.StatusCode(),
+				Bytes:      recorder.bytes,
+				LatencyMs:  float64(time.Since(start).Microseconds()) / 1000,
+				RemoteAddr: req.RemoteAddr,
+				UserAgent:  req.UserAgent(),
+			}
+
+			line, err := json.Marshal(entry)
+			if err != nil {
+				log.Printf("Error on marshalling to JSON: %v", err)
+				return
+			}
+
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				log.Printf("Error on writing access log: %v", err)
+			}
+		})
+	}
+}
+
+// statusRecorder records the status code and the size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func newStatusRecorder(rw http.ResponseWriter) *statusRecorder {
+	// the recorders of the outer middlewares are reused so that they see the same status.
+	if recorder, ok := rw.(*statusRecorder); ok {
+		return recorder
+	}
+
+	return &statusRecorder{ResponseWriter: rw}
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends the buffered data to the client if the underlying writer supports it.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// StatusCode returns the status code of the response, 200 if the handler did not write anything.
+func (r *statusRecorder) StatusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.status
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(rw, req)
+			})
+		}
+	}
+
+	handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		calls = append(calls, "handler")
+	}), middleware("first"), middleware("second"))
+
+	req := httptest.NewRequest(http.MethodGet, "/records", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	expected := "first,second,handler"
+	if got := strings.Join(calls, ","); got != expected {
+		t.Errorf("returned incorrect order. got: %v, expected: %v", got, expected)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", ""},
+		{"req-1", "req-1"},
+		{"has space", ""},
+		{strings.Repeat("a", maxRequestIDLength+1), ""},
+	}
+
+	for _, test := range tests {
+		var got string
+		handler := RequestID(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			got = RequestIDFromContext(req.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/records", nil)
+		if test.header != "" {
+			req.Header.Set(RequestIDHeader, test.header)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if test.expected != "" && got != test.expected {
+			t.Errorf("returned incorrect request id. got: %v, expected: %v", got, test.expected)
+		}
+
+		// the invalid ids are replaced by generated ones.
+		if test.expected == "" && len(got) != 32 {
+			t.Errorf("returned incorrect generated request id. got: %v", got)
+		}
+
+		if header := rr.Header().Get(RequestIDHeader); header != got {
+			t.Errorf("returned incorrect request id header. got: %v, expected: %v", header, got)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	handler := Recover(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		panic("nil map")
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/records", nil))
+
+	if status := rr.Code; status != http.StatusInternalServerError {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusInternalServerError)
+	}
+
+	expected := "{\"error\":\"internal server error occurred.\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("returned incorrect content type. got: %v, expected: %v", contentType, "application/json")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		handler  http.HandlerFunc
+		expected string
+	}{
+		{func(rw http.ResponseWriter, req *http.Request) {}, "application/json"},
+		{func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Type", "image/png")
+		}, "image/png"},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		Chain(test.handler, JSON).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/in-memory", nil))
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != test.expected {
+			t.Errorf("returned incorrect content type. got: %v, expected: %v", contentType, test.expected)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var logs bytes.Buffer
+	handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		panic("nil map")
+	}), RequestID, AccessLog(&logs), Recover)
+
+	req := httptest.NewRequest(http.MethodGet, "/in-memory?key=active-tabs", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry accessLogEntry
+	if err := json.Unmarshal(logs.Bytes(), &entry); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if entry.RequestID != "req-1" || entry.Method != http.MethodGet || entry.Path != "/in-memory" ||
+		entry.Query != "key=active-tabs" || entry.Status != http.StatusInternalServerError || entry.Bytes == 0 {
+		t.Errorf("returned incorrect access log. got: %+v", entry)
+	}
+}
+
+func TestNewServer_EndpointMiddlewares(t *testing.T) {
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusForbidden)
+		})
+	}
+	ok := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	server := NewServer(":0",
+		Endpoint{Path: "/records", Handler: ok},
+		Endpoint{Path: "/admin/", Handler: ok, Middlewares: []Middleware{deny}},
+	)
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/records", http.StatusOK},
+		{"/admin/in-memory/export", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		server.httpServer.Handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if status := rr.Code; status != test.expected {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.path, status, test.expected)
+		}
+	}
+}
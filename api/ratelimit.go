@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Limit is the rate limit of a token bucket holding Burst tokens at most,
+// which is refilled by Rate tokens per second. Each request takes a token.
+type Limit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+// Window returns the time it takes to refill an empty bucket.
+func (l Limit) Window() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+// RateLimitResult is the result of taking a token from a bucket. Remaining is the
+// number of the tokens left, the bucket is full again after Reset. If the request
+// is not allowed, a token is available after RetryAfter.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimiter takes tokens from the buckets identified by their keys.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (RateLimitResult, error)
+}
+
+// RateLimit limits the requests of each client to the endpoint by a token bucket. The clients
+// are identified by the authenticated principal, or by the IP address if the request is not
+// authenticated. The limit is sent in RateLimit-* headers, and the requests exceeding it are
+// responded 429 Too Many Requests with Retry-After header. The requests are not limited if
+// the rate of the limit is zero, or if the limiter fails.
+func RateLimit(limiter RateLimiter, endpoint string, limit Limit) Middleware {
+	return func(next http.Handler) http.Handler {
+		if limit.Rate <= 0 || limit.Burst <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			result, err := limiter.Allow(req.Context(), endpoint+":"+ClientOf(req), limit)
+			if err != nil {
+				log.Printf("Error on rate limiting %v %v (request id: %v), the request is allowed: %v",
+					req.Method, req.URL.Path, RequestIDFromContext(req.Context()), err)
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			rw.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			rw.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			rw.Header().Set("RateLimit-Reset", seconds(result.Reset))
+			rw.Header().Set("RateLimit-Policy", strconv.Itoa(limit.Burst)+";w="+seconds(limit.Window()))
+			if !result.Allowed {
+				rw.Header().Set("Retry-After", seconds(result.RetryAfter))
+				writeError(rw, http.StatusTooManyRequests, "rate limit is exceeded.")
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// ClientOf returns the identity of the client of the request, the subject of the
+// authenticated principal or the IP address of the client prefixed by "ip:".
+func ClientOf(req *http.Request) string {
+	if principal, ok := PrincipalFromContext(req.Context()); ok {
+		return principal.Subject
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// seconds returns the duration in seconds rounded up, as the headers are in whole seconds.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
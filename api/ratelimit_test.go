@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type mockRateLimiter struct {
+	AllowMock func(ctx context.Context, key string, limit Limit) (RateLimitResult, error)
+}
+
+func (m mockRateLimiter) Allow(ctx context.Context, key string, limit Limit) (RateLimitResult, error) {
+	return m.AllowMock(ctx, key, limit)
+}
+
+func TestRateLimit(t *testing.T) {
+	var keys []string
+	results := []RateLimitResult{
+		{Allowed: true, Remaining: 4, Reset: 500 * time.Millisecond},
+		{Allowed: false, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 300 * time.Millisecond},
+	}
+	limiter := mockRateLimiter{AllowMock: func(ctx context.Context, key string, limit Limit) (RateLimitResult, error) {
+		keys = append(keys, key)
+		result := results[0]
+		results = results[1:]
+		return result, nil
+	}}
+
+	handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		RateLimit(limiter, "/records", Limit{Rate: 2, Burst: 5}))
+
+	tests := []struct {
+		principal  *Principal
+		statusCode int
+		headers    map[string]string
+		body       string
+	}{
+		{&Principal{Subject: "apikey:team-a"}, http.StatusOK,
+			map[string]string{"RateLimit-Limit": "5", "RateLimit-Remaining": "4", "RateLimit-Reset": "1", "RateLimit-Policy": "5;w=3", "Retry-After": ""}, ""},
+		{nil, http.StatusTooManyRequests,
+			map[string]string{"RateLimit-Limit": "5", "RateLimit-Remaining": "0", "RateLimit-Reset": "3", "Retry-After": "1"}, `{"error":"rate limit is exceeded."}`},
+	}
+
+	for i, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/records", nil)
+		req.RemoteAddr = "192.0.2.1:51234"
+		if test.principal != nil {
+			req = req.WithContext(ContextWithPrincipal(req.Context(), *test.principal))
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code of request %v. got: %v, expected: %v", i, rr.Code, test.statusCode)
+		}
+
+		for header, expected := range test.headers {
+			if got := rr.Header().Get(header); got != expected {
+				t.Errorf("returned incorrect %v header of request %v. got: %v, expected: %v", header, i, got, expected)
+			}
+		}
+
+		if rr.Body.String() != test.body {
+			t.Errorf("returned incorrect body of request %v. got: %v, expected: %v", i, rr.Body.String(), test.body)
+		}
+	}
+
+	expectedKeys := []string{"/records:apikey:team-a", "/records:ip:192.0.2.1"}
+	if len(keys) != len(expectedKeys) || keys[0] != expectedKeys[0] || keys[1] != expectedKeys[1] {
+		t.Errorf("returned incorrect bucket keys. got: %v, expected: %v", keys, expectedKeys)
+	}
+}
+
+func TestRateLimit_LimiterError(t *testing.T) {
+	limiter := mockRateLimiter{AllowMock: func(ctx context.Context, key string, limit Limit) (RateLimitResult, error) {
+		return RateLimitResult{}, errors.New("connection refused")
+	}}
+
+	handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		RateLimit(limiter, "/records", Limit{Rate: 2, Burst: 5}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/records", nil))
+
+	// the requests are not rejected because the limits cannot be checked.
+	if rr.Code != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", rr.Code, http.StatusOK)
+	}
+}
+
+// TestRateLimit_BeforeAuthenticate checks that the requests with invalid credentials
+// are limited by the addresses of the clients when the limit precedes the authentication.
+func TestRateLimit_BeforeAuthenticate(t *testing.T) {
+	var keys []string
+	limiter := mockRateLimiter{AllowMock: func(ctx context.Context, key string, limit Limit) (RateLimitResult, error) {
+		keys = append(keys, key)
+		return RateLimitResult{Allowed: len(keys) <= limit.Burst}, nil
+	}}
+
+	handler := Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}),
+		RateLimit(limiter, "authenticate", Limit{Rate: 1, Burst: 1}),
+		Authenticate(headerAuthenticator("X-API-Key", "key", Principal{Subject: "apikey"})))
+
+	for i, expected := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodPost, "/records", nil)
+		req.RemoteAddr = "192.0.2.1:51234"
+		req.Header.Set("X-API-Key", "guess")
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != expected {
+			t.Errorf("returned incorrect status code of request %v. got: %v, expected: %v", i, rr.Code, expected)
+		}
+	}
+
+	expectedKeys := []string{"authenticate:ip:192.0.2.1", "authenticate:ip:192.0.2.1"}
+	if len(keys) != len(expectedKeys) || keys[0] != expectedKeys[0] || keys[1] != expectedKeys[1] {
+		t.Errorf("returned incorrect bucket keys. got: %v, expected: %v", keys, expectedKeys)
+	}
+}
@@ -0,0 +1,186 @@
+package apikey
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileDao stores the API keys in a JSON file as an array. The file is read
+// again when it is modified, so that the keys minted or revoked by the command
+// line are effective without restarting the application.
+type FileDao struct {
+	path string
+
+	mu      sync.Mutex
+	keys    map[string]Key
+	modTime time.Time
+	size    int64
+}
+
+// NewFileDao creates a dao of the API keys stored in the file at the path.
+// The file is created when the first key is inserted.
+func NewFileDao(path string) *FileDao {
+	return &FileDao{path: path}
+}
+
+// Find fetches the API key with the id.
+func (d *FileDao) Find(id string) (Key, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys, err := d.load()
+	if err != nil {
+		return Key{}, err
+	}
+
+	key, ok := keys[id]
+	if !ok {
+		return Key{}, ErrKeyNotFound
+	}
+
+	return key, nil
+}
+
+// Insert stores a new API key.
+func (d *FileDao) Insert(key Key) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := keys[key.Id]; ok {
+		return fmt.Errorf("api key %v already exists", key.Id)
+	}
+
+	keys[key.Id] = key
+	return d.save(keys)
+}
+
+// Revoke sets the revocation time of the API key unless it is already revoked.
+func (d *FileDao) Revoke(id string, at time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys, err := d.load()
+	if err != nil {
+		return err
+	}
+
+	key, ok := keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+		keys[id] = key
+	}
+
+	return d.save(keys)
+}
+
+// List fetches all the API keys in the order they are created.
+func (d *FileDao) List() ([]Key, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedKeys(keys), nil
+}
+
+// load returns a copy of the keys in the file, the file is read if it is modified since it is read last.
+func (d *FileDao) load() (map[string]Key, error) {
+	info, err := os.Stat(d.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]Key), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if d.keys == nil || !info.ModTime().Equal(d.modTime) || info.Size() != d.size {
+		data, err := ioutil.ReadFile(d.path)
+		if err != nil {
+			return nil, err
+		}
+
+		var list []Key
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("error on parsing the api key file: %w", err)
+		}
+
+		d.keys = make(map[string]Key, len(list))
+		for _, key := range list {
+			d.keys[key.Id] = key
+		}
+		d.modTime, d.size = info.ModTime(), info.Size()
+	}
+
+	keys := make(map[string]Key, len(d.keys))
+	for id, key := range d.keys {
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// save replaces the file by the keys atomically, so that the
+// application never reads a partially written file.
+func (d *FileDao) save(keys map[string]Key) error {
+	data, err := json.MarshalIndent(sortedKeys(keys), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(d.path), filepath.Base(d.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), d.path); err != nil {
+		return err
+	}
+
+	// the cache is cleared so that the file is read again with its new modification time.
+	d.keys = nil
+	return nil
+}
+
+func sortedKeys(keys map[string]Key) []Key {
+	list := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, key)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].Id < list[j].Id
+	})
+	return list
+}
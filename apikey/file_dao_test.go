@@ -0,0 +1,90 @@
+package apikey
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileDao(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apikeys.json")
+	dao := NewFileDao(path)
+
+	if _, err := dao.Find("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrKeyNotFound)
+	}
+
+	createdAt := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	first := Key{Id: "b", Name: "first", Hash: "hash", Scopes: []string{ScopeKVRead}, CreatedAt: createdAt}
+	second := Key{Id: "a", Name: "second", Hash: "hash", Scopes: []string{ScopeKVWrite}, CreatedAt: createdAt.Add(time.Second)}
+	for _, key := range []Key{first, second} {
+		if err := dao.Insert(key); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	if err := dao.Insert(first); err == nil {
+		t.Errorf("returned incorrect error. got: %v, expected: an error for the existing key", err)
+	}
+
+	revokedAt := createdAt.Add(time.Hour)
+	if err := dao.Revoke("a", revokedAt); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if err := dao.Revoke("missing", revokedAt); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrKeyNotFound)
+	}
+
+	// the keys are read by another dao as the application reads the keys minted by the command line.
+	keys, err := NewFileDao(path).List()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if len(keys) != 2 || keys[0].Name != "first" || keys[1].Name != "second" {
+		t.Fatalf("returned incorrect keys. got: %v, expected: first and second", keys)
+	}
+
+	if keys[1].RevokedAt == nil || !keys[1].RevokedAt.Equal(revokedAt) {
+		t.Errorf("returned incorrect revocation time. got: %v, expected: %v", keys[1].RevokedAt, revokedAt)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("returned incorrect file mode. got: %v, expected: %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+}
+
+func TestFileDao_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apikeys.json")
+	if err := ioutil.WriteFile(path, []byte(`[{"id": "a", "name": "first", "hash": "hash", "scopes": ["kv:read"]}]`), 0600); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	dao := NewFileDao(path)
+	if _, err := dao.Find("a"); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	// the key is revoked by another process.
+	if err := NewFileDao(path).Revoke("a", time.Now()); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	key, err := dao.Find("a")
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if !key.Revoked() {
+		t.Errorf("returned incorrect key. got: %v, expected: a revoked key", key)
+	}
+}
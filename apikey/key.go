@@ -0,0 +1,94 @@
+// Package apikey manages the API keys authenticating the clients of the API.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Scopes granted to the API keys.
+const (
+	ScopeRecordsRead = "records:read"
+	ScopeKVRead      = "kv:read"
+	ScopeKVWrite     = "kv:write"
+	ScopeQueuesRead  = "queues:read"
+	ScopeQueuesWrite = "queues:write"
+	ScopeAdmin       = "admin"
+)
+
+// Scopes lists the scopes which can be granted to the API keys.
+var Scopes = []string{ScopeRecordsRead, ScopeKVRead, ScopeKVWrite, ScopeQueuesRead, ScopeQueuesWrite, ScopeAdmin}
+
+// tokenPrefix is the prefix of the API keys, so that they can be recognized in the logs and the secret scanners.
+const tokenPrefix = "gcc_"
+
+// idLength and secretLength are the lengths of the hex encoded parts of the API keys.
+const (
+	idLength     = 16
+	secretLength = 64
+)
+
+// ErrMalformedKey is returned when an API key is not in the format of the keys minted.
+var ErrMalformedKey = errors.New("malformed api key")
+
+// Key represents a minted API key. The key itself is not stored,
+// the clients are authenticated by its SHA-256 hash. The key never expires
+// if ExpiresAt is nil, and it is revoked if RevokedAt is set.
+type Key struct {
+	Id        string     `json:"id" bson:"_id"`
+	Name      string     `json:"name" bson:"name"`
+	Hash      string     `json:"hash,omitempty" bson:"hash"`
+	Scopes    []string   `json:"scopes" bson:"scopes"`
+	Namespace string     `json:"namespace,omitempty" bson:"namespace,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+}
+
+// Expired checks whether the key is expired at the time.
+func (k Key) Expired(at time.Time) bool {
+	return k.ExpiresAt != nil && !at.Before(*k.ExpiresAt)
+}
+
+// Revoked checks whether the key is revoked.
+func (k Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// newToken generates an API key as "gcc_<id>_<secret>". The id is
+// used to look the key up, the secret is only known by the client.
+func newToken() (id string, token string, err error) {
+	random := make([]byte, (idLength+secretLength)/2)
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+
+	encoded := hex.EncodeToString(random)
+	id = encoded[:idLength]
+	return id, tokenPrefix + id + "_" + encoded[idLength:], nil
+}
+
+// parseToken returns the id of the API key.
+func parseToken(token string) (string, error) {
+	if len(token) != len(tokenPrefix)+idLength+1+secretLength || !strings.HasPrefix(token, tokenPrefix) {
+		return "", ErrMalformedKey
+	}
+
+	id := token[len(tokenPrefix) : len(tokenPrefix)+idLength]
+	if token[len(tokenPrefix)+idLength] != '_' {
+		return "", ErrMalformedKey
+	}
+
+	return id, nil
+}
+
+// hashToken returns the hex encoded SHA-256 hash of the API key. The keys are
+// random, so that they do not need a salt or a slow hash against guessing.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
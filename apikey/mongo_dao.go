@@ -0,0 +1,69 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// collectionName is the collection of the API keys in the default database.
+const collectionName = "apiKeys"
+
+// MongoDao stores the API keys in MongoDB by their ids.
+type MongoDao struct {
+	Db *mongodb.Connection
+}
+
+func (d MongoDao) collection() *mongo.Collection {
+	return d.Db.Database(d.Db.DatabaseName()).Collection(collectionName)
+}
+
+// Find fetches the API key with the id.
+func (d MongoDao) Find(id string) (Key, error) {
+	var key Key
+	err := d.collection().FindOne(context.Background(), bson.M{"_id": id}).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return key, ErrKeyNotFound
+	}
+
+	return key, err
+}
+
+// Insert stores a new API key.
+func (d MongoDao) Insert(key Key) error {
+	_, err := d.collection().InsertOne(context.Background(), key)
+	return err
+}
+
+// Revoke sets the revocation time of the API key unless it is already revoked.
+func (d MongoDao) Revoke(id string, at time.Time) error {
+	result, err := d.collection().UpdateOne(context.Background(),
+		bson.M{"_id": id},
+		[]bson.M{{"$set": bson.M{"revokedAt": bson.M{"$ifNull": bson.A{"$revokedAt", at}}}}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// List fetches all the API keys in the order they are created.
+func (d MongoDao) List() ([]Key, error) {
+	cursor, err := d.collection().Find(context.Background(), bson.M{}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	err = cursor.All(context.Background(), &keys)
+	return keys, err
+}
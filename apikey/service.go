@@ -0,0 +1,128 @@
+package apikey
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"net/http"
+	"time"
+)
+
+// Header is the request header carrying the API key.
+const Header = "X-API-Key"
+
+// ErrKeyNotFound is returned when there is no API key with the id.
+var ErrKeyNotFound = errors.New("api key does not exist")
+
+// ErrUnknownScope is returned when a scope which is not in Scopes is granted.
+var ErrUnknownScope = errors.New("unknown scope")
+
+// Dao interface is used by a Service to access the API keys.
+type Dao interface {
+	Find(id string) (Key, error)
+	Insert(key Key) error
+	Revoke(id string, at time.Time) error
+	List() ([]Key, error)
+}
+
+// Service mints, revokes and authenticates the API keys stored by Dao.
+// It implements api.Authenticator, the clients send their keys in X-API-Key header.
+type Service struct {
+	Dao Dao
+}
+
+// Mint creates an API key granted the scopes. The requests of the key are restricted to
+// the namespace if it is not empty, and the key expires after ttl if it is not zero.
+// The key is returned only once, it cannot be recovered from the stored Key.
+func (s Service) Mint(name string, scopes []string, namespace string, ttl time.Duration) (string, Key, error) {
+	if len(scopes) == 0 {
+		return "", Key{}, fmt.Errorf("%w: at least one scope must be granted", ErrUnknownScope)
+	}
+
+	for _, scope := range scopes {
+		if !contains(Scopes, scope) {
+			return "", Key{}, fmt.Errorf("%w %q", ErrUnknownScope, scope)
+		}
+	}
+
+	id, token, err := newToken()
+	if err != nil {
+		return "", Key{}, err
+	}
+
+	key := Key{
+		Id:        id,
+		Name:      name,
+		Hash:      hashToken(token),
+		Scopes:    scopes,
+		Namespace: namespace,
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		expiresAt := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.Dao.Insert(key); err != nil {
+		return "", Key{}, err
+	}
+
+	return token, key, nil
+}
+
+// Revoke revokes the API key with the id, the key cannot authenticate from then on.
+func (s Service) Revoke(id string) error {
+	return s.Dao.Revoke(id, time.Now().UTC())
+}
+
+// List returns the API keys including the expired and the revoked ones.
+func (s Service) List() ([]Key, error) {
+	return s.Dao.List()
+}
+
+// Authenticate authenticates the client by the API key in X-API-Key header.
+// The client is granted the scopes of the key and restricted to its namespace.
+func (s Service) Authenticate(req *http.Request) (api.Principal, error) {
+	token := req.Header.Get(Header)
+	if token == "" {
+		return api.Principal{}, api.ErrNoCredentials
+	}
+
+	id, err := parseToken(token)
+	if err != nil {
+		return api.Principal{}, fmt.Errorf("%w: %v", api.ErrInvalidCredentials, err)
+	}
+
+	key, err := s.Dao.Find(id)
+	if errors.Is(err, ErrKeyNotFound) {
+		return api.Principal{}, fmt.Errorf("%w: api key %v does not exist", api.ErrInvalidCredentials, id)
+	}
+	if err != nil {
+		return api.Principal{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(key.Hash)) != 1 {
+		return api.Principal{}, fmt.Errorf("%w: secret of api key %v does not match", api.ErrInvalidCredentials, id)
+	}
+
+	if key.Revoked() {
+		return api.Principal{}, fmt.Errorf("%w: api key %v is revoked", api.ErrInvalidCredentials, id)
+	}
+
+	if key.Expired(time.Now()) {
+		return api.Principal{}, fmt.Errorf("%w: api key %v is expired", api.ErrInvalidCredentials, id)
+	}
+
+	return api.Principal{Subject: "apikey:" + key.Id, Scopes: key.Scopes, Namespace: key.Namespace}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,179 @@
+package apikey
+
+import (
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockDao struct {
+	FindMock   func(id string) (Key, error)
+	InsertMock func(key Key) error
+	RevokeMock func(id string, at time.Time) error
+	ListMock   func() ([]Key, error)
+}
+
+func (m mockDao) Find(id string) (Key, error) {
+	return m.FindMock(id)
+}
+
+func (m mockDao) Insert(key Key) error {
+	return m.InsertMock(key)
+}
+
+func (m mockDao) Revoke(id string, at time.Time) error {
+	return m.RevokeMock(id, at)
+}
+
+func (m mockDao) List() ([]Key, error) {
+	return m.ListMock()
+}
+
+// memoryDao returns a dao storing the keys in the map.
+func memoryDao(keys map[string]Key) mockDao {
+	return mockDao{
+		FindMock: func(id string) (Key, error) {
+			key, ok := keys[id]
+			if !ok {
+				return Key{}, ErrKeyNotFound
+			}
+			return key, nil
+		},
+		InsertMock: func(key Key) error {
+			keys[key.Id] = key
+			return nil
+		},
+		RevokeMock: func(id string, at time.Time) error {
+			key, ok := keys[id]
+			if !ok {
+				return ErrKeyNotFound
+			}
+			key.RevokedAt = &at
+			keys[id] = key
+			return nil
+		},
+	}
+}
+
+func TestService_Mint(t *testing.T) {
+	keys := make(map[string]Key)
+	service := Service{Dao: memoryDao(keys)}
+
+	token, key, err := service.Mint("billing", []string{ScopeRecordsRead, ScopeKVRead}, "billing", time.Hour)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if !strings.HasPrefix(token, tokenPrefix+key.Id+"_") {
+		t.Errorf("returned incorrect token. got: %v, expected prefix: %v", token, tokenPrefix+key.Id+"_")
+	}
+
+	if stored := keys[key.Id]; !reflect.DeepEqual(stored, key) {
+		t.Errorf("returned incorrect stored key. got: %v, expected: %v", stored, key)
+	}
+
+	if key.Hash == token || key.Hash != hashToken(token) {
+		t.Errorf("returned incorrect hash. got: %v, expected: %v", key.Hash, hashToken(token))
+	}
+
+	if key.ExpiresAt == nil || !key.ExpiresAt.Equal(key.CreatedAt.Add(time.Hour)) {
+		t.Errorf("returned incorrect expiry. got: %v, expected: %v", key.ExpiresAt, key.CreatedAt.Add(time.Hour))
+	}
+}
+
+func TestService_MintUnknownScope(t *testing.T) {
+	service := Service{Dao: memoryDao(make(map[string]Key))}
+
+	for _, scopes := range [][]string{nil, {ScopeKVRead, "kv:delete"}} {
+		if _, _, err := service.Mint("billing", scopes, "", 0); !errors.Is(err, ErrUnknownScope) {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: %v", scopes, err, ErrUnknownScope)
+		}
+	}
+}
+
+func TestService_Authenticate(t *testing.T) {
+	keys := make(map[string]Key)
+	service := Service{Dao: memoryDao(keys)}
+
+	valid, key, err := service.Mint("billing", []string{ScopeKVRead}, "billing", 0)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	expired, expiredKey, err := service.Mint("expired", []string{ScopeKVRead}, "", time.Hour)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	past := time.Now().Add(-time.Minute)
+	expiredKey.ExpiresAt = &past
+	keys[expiredKey.Id] = expiredKey
+
+	revoked, revokedKey, err := service.Mint("revoked", []string{ScopeKVRead}, "", 0)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if err := service.Revoke(revokedKey.Id); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	// a key with the id of a valid key and another secret.
+	forged := valid[:len(valid)-1] + "0"
+	if forged == valid {
+		forged = valid[:len(valid)-1] + "1"
+	}
+
+	tests := []struct {
+		token     string
+		principal api.Principal
+		err       error
+	}{
+		{valid, api.Principal{Subject: "apikey:" + key.Id, Scopes: []string{ScopeKVRead}, Namespace: "billing"}, nil},
+		{"", api.Principal{}, api.ErrNoCredentials},
+		{"gcc_short", api.Principal{}, api.ErrInvalidCredentials},
+		{forged, api.Principal{}, api.ErrInvalidCredentials},
+		{expired, api.Principal{}, api.ErrInvalidCredentials},
+		{revoked, api.Principal{}, api.ErrInvalidCredentials},
+		{tokenPrefix + strings.Repeat("a", idLength) + "_" + strings.Repeat("b", secretLength), api.Principal{}, api.ErrInvalidCredentials},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/in-memory", nil)
+		if test.token != "" {
+			req.Header.Set(Header, test.token)
+		}
+
+		principal, err := service.Authenticate(req)
+		if !errors.Is(err, test.err) {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: %v", test.token, err, test.err)
+		}
+
+		if !reflect.DeepEqual(principal, test.principal) {
+			t.Errorf("returned incorrect principal for %v. got: %v, expected: %v", test.token, principal, test.principal)
+		}
+	}
+}
+
+func TestService_AuthenticateDaoError(t *testing.T) {
+	daoErr := errors.New("connection refused")
+	service := Service{Dao: mockDao{FindMock: func(id string) (Key, error) {
+		return Key{}, daoErr
+	}}}
+
+	_, token, err := newToken()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/in-memory", nil)
+	req.Header.Set(Header, token)
+
+	// the keys which cannot be looked up are not considered invalid.
+	if _, err := service.Authenticate(req); err != daoErr {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, daoErr)
+	}
+}
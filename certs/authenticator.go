@@ -0,0 +1,26 @@
+package certs
+
+import (
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"net/http"
+)
+
+// Authenticator authenticates the clients by their certificates verified during the TLS handshake.
+// The client is identified by the common name of the certificate and granted the scopes in the
+// organizational units of its subject, e.g. OU=records:read. The clients are not restricted to a namespace.
+type Authenticator struct{}
+
+// Authenticate authenticates the client by the verified certificate of the connection.
+func (a Authenticator) Authenticate(req *http.Request) (api.Principal, error) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return api.Principal{}, api.ErrNoCredentials
+	}
+
+	certificate := req.TLS.VerifiedChains[0][0]
+	if certificate.Subject.CommonName == "" {
+		return api.Principal{}, fmt.Errorf("%w: client certificate does not have a common name", api.ErrInvalidCredentials)
+	}
+
+	return api.Principal{Subject: "cert:" + certificate.Subject.CommonName, Scopes: certificate.Subject.OrganizationalUnit}, nil
+}
@@ -0,0 +1,267 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// issuer is a certificate and its key issuing the other certificates.
+type issuer struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+// issue creates a certificate of the subject signed by the issuer, or a self-signed CA if the issuer is nil.
+func issue(t *testing.T, parent *issuer, serial int64, subject pkix.Name) (*issuer, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer := &issuer{certificate: template, key: key}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer = parent
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer.certificate, &key.PublicKey, signer.key)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	return &issuer{certificate: certificate, key: key},
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+// writeFile writes the file and moves its modification time so that it is read again.
+func writeFile(t *testing.T, path string, data []byte) {
+	modTime := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	}
+
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+}
+
+// serialOf returns the serial of the certificate served after the files are checked for modifications.
+func serialOf(t *testing.T, files *Files) int64 {
+	_ = files.reload()
+	return serialOfLoaded(t, files)
+}
+
+func TestFiles_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca, _, _ := issue(t, nil, 1, pkix.Name{CommonName: "ca"})
+
+	_, cert, key := issue(t, ca, 2, pkix.Name{CommonName: "server"})
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+
+	files, err := Load(certFile, keyFile, "", false)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if serial := serialOf(t, files); serial != 2 {
+		t.Errorf("returned incorrect certificate. got: %v, expected: %v", serial, 2)
+	}
+
+	// the renewed certificate is served once both of the files are written.
+	_, cert, key = issue(t, ca, 3, pkix.Name{CommonName: "server"})
+	writeFile(t, certFile, cert)
+	if serial := serialOf(t, files); serial != 2 {
+		t.Errorf("returned incorrect certificate before the key is written. got: %v, expected: %v", serial, 2)
+	}
+
+	writeFile(t, keyFile, key)
+	if serial := serialOf(t, files); serial != 3 {
+		t.Errorf("returned incorrect certificate after the renewal. got: %v, expected: %v", serial, 3)
+	}
+
+	// the certificate read last is kept if the files are not valid.
+	writeFile(t, certFile, []byte("not a certificate"))
+	if serial := serialOf(t, files); serial != 3 {
+		t.Errorf("returned incorrect certificate after an invalid renewal. got: %v, expected: %v", serial, 3)
+	}
+
+	if _, err := Load(certFile, keyFile, "", false); err == nil {
+		t.Errorf("returned incorrect error of the invalid files. got: %v, expected: an error", err)
+	}
+}
+
+func TestFiles_Watch(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	ca, _, _ := issue(t, nil, 1, pkix.Name{CommonName: "ca"})
+
+	_, cert, key := issue(t, ca, 2, pkix.Name{CommonName: "server"})
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+
+	files, err := Load(certFile, keyFile, "", false)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go files.Watch(ctx, 10*time.Millisecond)
+
+	// the renewed certificate is published by the watcher, the handshakes only read it.
+	_, cert, key = issue(t, ca, 3, pkix.Name{CommonName: "server"})
+	writeFile(t, certFile, cert)
+	writeFile(t, keyFile, key)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for serialOfLoaded(t, files) != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if serial := serialOfLoaded(t, files); serial != 3 {
+		t.Errorf("returned incorrect certificate after the renewal. got: %v, expected: %v", serial, 3)
+	}
+
+	// the handshakes do not access the files.
+	if err := os.Remove(certFile); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if serial := serialOfLoaded(t, files); serial != 3 {
+		t.Errorf("returned incorrect certificate after the files are removed. got: %v, expected: %v", serial, 3)
+	}
+}
+
+// serialOfLoaded returns the serial of the certificate served without checking the files.
+func serialOfLoaded(t *testing.T, files *Files) int64 {
+	config, err := files.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	certificate, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	return certificate.SerialNumber.Int64()
+}
+
+func TestAuthenticator_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caCert, _ := issue(t, nil, 1, pkix.Name{CommonName: "ca"})
+	_, serverCert, serverKey := issue(t, ca, 2, pkix.Name{CommonName: "server"})
+	_, clientCert, clientKey := issue(t, ca, 3, pkix.Name{CommonName: "billing", OrganizationalUnit: []string{"records:read", "kv:read"}})
+	for name, data := range map[string][]byte{"ca.crt": caCert, "tls.crt": serverCert, "tls.key": serverKey} {
+		writeFile(t, filepath.Join(dir, name), data)
+	}
+
+	files, err := Load(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt"), false)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	server := api.NewServer(address, api.Endpoint{
+		Path: "/whoami",
+		Handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			principal, _ := api.PrincipalFromContext(req.Context())
+			fmt.Fprintf(rw, "%v %v", principal.Subject, strings.Join(principal.Scopes, ","))
+		}),
+		Middlewares: []api.Middleware{api.Authenticate(Authenticator{})},
+	})
+	server.UseTLS(files.TLSConfig())
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+	certificate, err := tls.X509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tests := []struct {
+		certificates []tls.Certificate
+		statusCode   int
+		expected     string
+	}{
+		{[]tls.Certificate{certificate}, http.StatusOK, "cert:billing kv:read,records:read"},
+		{nil, http.StatusUnauthorized, `{"error":"credentials are missing."}`},
+	}
+
+	for _, test := range tests {
+		client := &http.Client{Transport: &http.Transport{ForceAttemptHTTP2: true, TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: test.certificates}}}
+		resp, err := client.Get("https://" + address + "/whoami")
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != test.statusCode {
+			t.Errorf("returned incorrect status code with %v certificates. got: %v, expected: %v", len(test.certificates), resp.StatusCode, test.statusCode)
+		}
+
+		if strings.TrimSpace(string(body)) != test.expected {
+			t.Errorf("returned incorrect response body with %v certificates. got: %v, expected: %v", len(test.certificates), string(body), test.expected)
+		}
+
+		if resp.ProtoMajor != 2 {
+			t.Errorf("returned incorrect protocol. got: %v, expected: HTTP/2", resp.Proto)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Package certs serves TLS by the certificates in files which are reloaded when they change,
+// and authenticates the clients by their certificates.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// file is a file whose modification is detected by its modification time and size.
+type file struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// modified checks whether the file is modified since it is checked last.
+func (f *file) modified() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+
+	if f.modTime.IsZero() || !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+		f.modTime, f.size = info.ModTime(), info.Size()
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Files is the certificate and the key of a TLS server, and the CA bundle verifying the
+// certificates of the clients if it is given. The files are read again by Watch when they
+// are modified, so that the certificates can be renewed without restarting the application.
+// If the modified files are not valid, the certificates read last are kept.
+// The handshakes only read the configuration published last, they do not access the files.
+type Files struct {
+	certFile     file
+	keyFile      file
+	clientCaFile *file
+	clientAuth   tls.ClientAuthType
+
+	// mu serializes the reloads, config holds the *tls.Config read last.
+	mu     sync.Mutex
+	config atomic.Value
+}
+
+// Load reads the certificate and the key of the server. If clientCaFile is set, the certificates
+// of the clients are verified by the CAs in it, they are required if requireClientCert is true.
+func Load(certFile, keyFile, clientCaFile string, requireClientCert bool) (*Files, error) {
+	f := &Files{certFile: file{path: certFile}, keyFile: file{path: keyFile}, clientAuth: tls.NoClientCert}
+	if clientCaFile != "" {
+		f.clientCaFile = &file{path: clientCaFile}
+		f.clientAuth = tls.VerifyClientCertIfGiven
+		if requireClientCert {
+			f.clientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Watch checks the files every interval and reloads them when they are modified, until the context is done.
+func (f *Files) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := f.reload(); err != nil {
+				log.Printf("Error on reading the TLS certificate files, the certificates read last are used: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// TLSConfig returns the configuration of a TLS server serving the certificates in the files.
+// The configuration of each connection is the one read last by GetConfigForClient,
+// GetCertificate is set as well since the servers require a certificate to be configured.
+func (f *Files) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			return f.load(), nil
+		},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &f.load().Certificates[0], nil
+		},
+	}
+}
+
+// load returns the configuration of the certificates read last. The configuration is
+// reused until the files are modified, so that the sessions can be resumed.
+func (f *Files) load() *tls.Config {
+	return f.config.Load().(*tls.Config)
+}
+
+// reload reads the files if they are modified and publishes the configuration of their certificates.
+// The configuration read last is kept if the files cannot be read.
+func (f *Files) reload() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	modified, err := f.modified()
+	if err != nil || !modified {
+		return err
+	}
+
+	// the files are not read again until they are modified even if they are not valid.
+	config, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	if f.config.Load() != nil {
+		log.Printf("Reloaded the TLS certificate files.")
+	}
+	f.config.Store(config)
+	return nil
+}
+
+// modified checks whether any of the files is modified, all of them are checked
+// so that a renewal writing the files one by one is detected once they are all written.
+func (f *Files) modified() (bool, error) {
+	files := []*file{&f.certFile, &f.keyFile}
+	if f.clientCaFile != nil {
+		files = append(files, f.clientCaFile)
+	}
+
+	modified := false
+	for _, each := range files {
+		m, err := each.modified()
+		if err != nil {
+			return false, err
+		}
+		modified = modified || m
+	}
+
+	return modified, nil
+}
+
+// read reads the files and creates the configuration of their certificates.
+func (f *Files) read() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(f.certFile.path, f.keyFile.path)
+	if err != nil {
+		return nil, fmt.Errorf("error on loading the TLS certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+		NextProtos:   []string{"h2", "http/1.1"},
+		ClientAuth:   f.clientAuth,
+	}
+
+	if f.clientCaFile != nil {
+		bundle, err := ioutil.ReadFile(f.clientCaFile.path)
+		if err != nil {
+			return nil, err
+		}
+
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(bundle) {
+			return nil, errors.New("the client CA file does not contain any PEM certificates")
+		}
+	}
+
+	return config, nil
+}
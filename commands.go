@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"github.com/skarakasoglu/g-case-challenge/apikey"
+	"github.com/skarakasoglu/g-case-challenge/config"
+	"github.com/skarakasoglu/g-case-challenge/inmem"
+	"github.com/skarakasoglu/g-case-challenge/mongodb"
+	"github.com/skarakasoglu/g-case-challenge/queue"
+	"github.com/skarakasoglu/g-case-challenge/ratelimit"
+	"github.com/skarakasoglu/g-case-challenge/record"
+	rediscl "github.com/skarakasoglu/g-case-challenge/redis"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// runCommand runs the subcommand given as the first argument of the application
+// instead of serving the API.
+func runCommand(name string, args []string) error {
+	switch name {
+	case "export":
+		return exportCommand(args)
+	case "import":
+		return importCommand(args)
+	case "apikey":
+		return apiKeyCommand(args)
+	case "migrate-namespaces":
+		return migrateNamespacesCommand(args)
+	default:
+		return fmt.Errorf("unknown command %q, available commands: export, import, apikey, migrate-namespaces", name)
+	}
+}
+
+// exportCommand writes the keys of the in-memory database to an NDJSON archive.
+func exportCommand(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	redisUrl := flags.String("redis", os.Getenv("REDIS_URL"), "in-memory database connection string")
+	pattern := flags.String("pattern", "*", "pattern of the keys to export")
+	output := flags.String("output", "-", "archive file to write, - for standard output")
+	_ = flags.Parse(args)
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	service := inmem.SnapshotService{Dao: inmem.RedisDao{Db: rediscl.NewClient(*redisUrl)}}
+	exported, err := service.Export(*pattern, w)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Exported %v keys.", exported)
+	return nil
+}
+
+// importCommand writes the keys in an NDJSON archive to the in-memory database.
+func importCommand(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	redisUrl := flags.String("redis", os.Getenv("REDIS_URL"), "in-memory database connection string")
+	policy := flags.String("policy", string(inmem.ConflictSkip), "what to do with the existing keys: skip, overwrite or fail")
+	input := flags.String("input", "-", "archive file to read, - for standard input")
+	_ = flags.Parse(args)
+
+	switch inmem.ConflictPolicy(*policy) {
+	case inmem.ConflictSkip, inmem.ConflictOverwrite, inmem.ConflictFail:
+	default:
+		return fmt.Errorf("policy must be skip, overwrite or fail")
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	service := inmem.SnapshotService{Dao: inmem.RedisDao{Db: rediscl.NewClient(*redisUrl)}}
+	resp, err := service.Import(r, inmem.ConflictPolicy(*policy))
+	log.Printf("Imported %v keys, skipped %v keys.", resp.Imported, resp.Skipped)
+	return err
+}
+
+// migrateNamespacesCommand moves the keys of the default namespace, which were stored
+// without a prefix, under the prefix of the default namespace. The keys of the queues,
+// the rate limits and the records cache sharing the database are not moved.
+func migrateNamespacesCommand(args []string) error {
+	flags := flag.NewFlagSet("migrate-namespaces", flag.ExitOnError)
+	redisUrl := flags.String("redis", os.Getenv("REDIS_URL"), "in-memory database connection string")
+	_ = flags.Parse(args)
+
+	dao := inmem.RedisDao{Db: rediscl.NewClient(*redisUrl)}
+	moved, err := dao.MigrateDefaultNamespace(context.Background(), []string{queue.KeyPrefix, ratelimit.KeyPrefix, record.CacheKeyPrefix})
+	log.Printf("Moved %v keys to the default namespace.", moved)
+	return err
+}
+
+// apiKeyCommand mints, revokes and lists the API keys by the subcommand given as its first argument.
+func apiKeyCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("apikey subcommand is missing, available subcommands: mint, revoke, list")
+	}
+
+	flags := flag.NewFlagSet("apikey "+args[0], flag.ExitOnError)
+	store := flags.String("store", os.Getenv("API_KEY_STORE"), "store of the API keys: mongo or file")
+	file := flags.String("file", os.Getenv("API_KEY_FILE"), "file of the API keys if the store is file")
+	dbUrl := flags.String("db", os.Getenv("DB_CONNECTION_STRING"), "MongoDB connection string if the store is mongo")
+	dbName := flags.String("dbname", os.Getenv("DB_NAME"), "MongoDB database name if the store is mongo")
+
+	switch args[0] {
+	case "mint":
+		name := flags.String("name", "", "name describing the client of the key")
+		scopes := flags.String("scopes", "", "comma separated scopes granted to the key: "+strings.Join(apikey.Scopes, ", "))
+		namespace := flags.String("namespace", "", "in-memory namespace the key is restricted to, not restricted if it is empty")
+		ttl := flags.Duration("ttl", 0, "time the key is valid for such as 720h, it never expires if it is zero")
+		_ = flags.Parse(args[1:])
+
+		if *namespace != "" && !api.ValidNamespace(*namespace) {
+			return fmt.Errorf("namespace must consist of 1 to 64 letters, digits, '_', '.' or '-'")
+		}
+
+		return withApiKeyService(*store, *file, *dbUrl, *dbName, func(service apikey.Service) error {
+			token, key, err := service.Mint(*name, splitScopes(*scopes), *namespace, *ttl)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("Minted API key %v, it is not shown again.", key.Id)
+			fmt.Println(token)
+			return nil
+		})
+	case "revoke":
+		id := flags.String("id", "", "id of the key to revoke")
+		_ = flags.Parse(args[1:])
+
+		return withApiKeyService(*store, *file, *dbUrl, *dbName, func(service apikey.Service) error {
+			if err := service.Revoke(*id); err != nil {
+				return err
+			}
+
+			log.Printf("Revoked API key %v.", *id)
+			return nil
+		})
+	case "list":
+		_ = flags.Parse(args[1:])
+
+		return withApiKeyService(*store, *file, *dbUrl, *dbName, func(service apikey.Service) error {
+			keys, err := service.List()
+			if err != nil {
+				return err
+			}
+
+			// the hashes are not listed, they are not needed to manage the keys.
+			encoder := json.NewEncoder(os.Stdout)
+			for _, key := range keys {
+				key.Hash = ""
+				if err := encoder.Encode(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	default:
+		return fmt.Errorf("unknown apikey subcommand %q, available subcommands: mint, revoke, list", args[0])
+	}
+}
+
+// withApiKeyService runs the function with a service of the API keys in the store.
+func withApiKeyService(store, file, dbUrl, dbName string, run func(service apikey.Service) error) error {
+	switch store {
+	case config.ApiKeyStoreFile:
+		if file == "" {
+			return fmt.Errorf("file of the API keys is missing")
+		}
+		return run(apikey.Service{Dao: apikey.NewFileDao(file)})
+	case config.ApiKeyStoreMongo:
+		conn := mongodb.NewConnection(dbUrl, dbName)
+		if err := conn.Connect(context.Background()); err != nil {
+			return err
+		}
+		defer conn.Disconnect(context.Background())
+
+		return run(apikey.Service{Dao: apikey.MongoDao{Db: conn}})
+	default:
+		return fmt.Errorf("store must be %v or %v", config.ApiKeyStoreMongo, config.ApiKeyStoreFile)
+	}
+}
+
+// splitScopes splits the comma separated scopes.
+func splitScopes(scopes string) []string {
+	var split []string
+	for _, scope := range strings.Split(scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			split = append(split, scope)
+		}
+	}
+
+	return split
+}
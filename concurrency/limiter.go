@@ -0,0 +1,243 @@
+// Package concurrency limits the requests handled concurrently by a limit adapting to their latencies.
+package concurrency
+
+import (
+	"context"
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"math"
+	"sync"
+	"time"
+)
+
+// Options configures a Limiter. The limit starts at InitialLimit and stays between
+// MinLimit and MaxLimit. The requests taking longer than LatencyThreshold decrease
+// the limit by Backoff, which is 0.9 if it is zero. At most QueueSize requests wait
+// for MaxWait to be admitted while the limit is reached. The sheddable requests take
+// at most SheddableShare of the limit, which is 1 if it is zero, so that the rest of
+// the limit is kept for the other requests sharing the limiter.
+type Options struct {
+	InitialLimit     int
+	MinLimit         int
+	MaxLimit         int
+	LatencyThreshold time.Duration
+	Backoff          float64
+	QueueSize        int
+	MaxWait          time.Duration
+	SheddableShare   float64
+}
+
+type waiter struct {
+	priority api.Priority
+	// admitted receives nil when the request is admitted, or the error when it is shed from the queue.
+	admitted chan error
+}
+
+// Limiter limits the requests handled concurrently by additive increase, multiplicative
+// decrease (AIMD). The limit increases by one per limit of the requests completed in time
+// while the limit is used, and it decreases by Backoff when a request is slow or overloaded.
+// The requests completed after a decrease are taken into account only if they were admitted
+// after it, so that the requests admitted by the previous limit do not decrease it again.
+type Limiter struct {
+	options Options
+
+	mu          sync.Mutex
+	limit       float64
+	inFlight    int
+	sheddable   int
+	queue       []*waiter
+	decreasedAt time.Time
+	now         func() time.Time
+}
+
+// NewLimiter creates a limiter with the options.
+func NewLimiter(options Options) *Limiter {
+	if options.MinLimit < 1 {
+		options.MinLimit = 1
+	}
+	if options.MaxLimit < options.MinLimit {
+		options.MaxLimit = options.MinLimit
+	}
+	if options.Backoff <= 0 || options.Backoff >= 1 {
+		options.Backoff = 0.9
+	}
+	if options.SheddableShare <= 0 || options.SheddableShare > 1 {
+		options.SheddableShare = 1
+	}
+
+	limit := math.Min(math.Max(float64(options.InitialLimit), float64(options.MinLimit)), float64(options.MaxLimit))
+	return &Limiter{options: options, limit: limit, now: time.Now}
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return int(l.limit)
+}
+
+// InFlight returns the number of the requests admitted and not released yet.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inFlight
+}
+
+// Acquire admits the request if the limit is not reached. Otherwise, the sheddable
+// requests are rejected immediately and the others wait in the queue for MaxWait.
+// The sheddable requests are rejected when their share of the limit is reached as well.
+func (l *Limiter) Acquire(ctx context.Context, priority api.Priority) (func(overloaded bool), error) {
+	l.mu.Lock()
+	sheddable := priority <= api.PrioritySheddable
+	if l.inFlight < int(l.limit) && len(l.queue) == 0 && (!sheddable || l.sheddable < l.sheddableLimit()) {
+		return l.admit(sheddable), nil
+	}
+
+	if sheddable || l.options.QueueSize <= 0 {
+		inFlight := l.inFlight
+		l.mu.Unlock()
+		return nil, fmt.Errorf("%w: %v requests are in flight", api.ErrLimitExceeded, inFlight)
+	}
+
+	if len(l.queue) >= l.options.QueueSize && !l.evict(priority) {
+		l.mu.Unlock()
+		return nil, fmt.Errorf("%w: the queue is full", api.ErrLimitExceeded)
+	}
+
+	w := &waiter{priority: priority, admitted: make(chan error, 1)}
+	l.enqueue(w)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.options.MaxWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case err = <-w.admitted:
+		if err != nil {
+			return nil, err
+		}
+		return l.release(l.now(), true, false), nil
+	case <-timer.C:
+		err = fmt.Errorf("%w: the request waited for %v", api.ErrLimitExceeded, l.options.MaxWait)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	removed := l.remove(w)
+	l.mu.Unlock()
+
+	// the request is admitted or evicted while it is giving up, the place it is admitted to is released.
+	if !removed {
+		if admitErr := <-w.admitted; admitErr == nil {
+			l.release(l.now(), false, false)(false)
+		}
+	}
+	return nil, err
+}
+
+// sheddableLimit returns the number of the places the sheddable requests can take, at least one.
+func (l *Limiter) sheddableLimit() int {
+	return int(math.Max(1, math.Floor(l.limit*l.options.SheddableShare)))
+}
+
+// admit takes a place for the request and unlocks the limiter.
+func (l *Limiter) admit(sheddable bool) func(overloaded bool) {
+	l.inFlight++
+	if sheddable {
+		l.sheddable++
+	}
+	admittedAt := l.now()
+	l.mu.Unlock()
+
+	return l.release(admittedAt, true, sheddable)
+}
+
+// release returns the function releasing the place of a request admitted at the time.
+// The latency of the request is taken into account only if it is observed.
+func (l *Limiter) release(admittedAt time.Time, observed bool, sheddable bool) func(overloaded bool) {
+	var once sync.Once
+	return func(overloaded bool) {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			now := l.now()
+			saturated := l.inFlight >= int(l.limit)
+			l.inFlight--
+			if sheddable {
+				l.sheddable--
+			}
+
+			switch {
+			case !observed:
+			case overloaded || now.Sub(admittedAt) > l.options.LatencyThreshold:
+				if admittedAt.After(l.decreasedAt) {
+					l.limit = math.Max(float64(l.options.MinLimit), l.limit*l.options.Backoff)
+					l.decreasedAt = now
+				}
+			case saturated:
+				l.limit = math.Min(float64(l.options.MaxLimit), l.limit+1/l.limit)
+			}
+
+			l.admitWaiters()
+		})
+	}
+}
+
+// admitWaiters admits the waiting requests in the order of their priorities while the limit is not reached.
+func (l *Limiter) admitWaiters() {
+	for len(l.queue) > 0 && l.inFlight < int(l.limit) {
+		w := l.queue[0]
+		l.queue = l.queue[1:]
+		l.inFlight++
+		w.admitted <- nil
+	}
+}
+
+// enqueue inserts the waiter after the waiters of the same or higher priorities.
+func (l *Limiter) enqueue(w *waiter) {
+	i := len(l.queue)
+	for i > 0 && l.queue[i-1].priority < w.priority {
+		i--
+	}
+
+	l.queue = append(l.queue, nil)
+	copy(l.queue[i+1:], l.queue[i:])
+	l.queue[i] = w
+}
+
+// evict sheds the last waiter of the queue if its priority is lower than the priority.
+func (l *Limiter) evict(priority api.Priority) bool {
+	last := l.queue[len(l.queue)-1]
+	if last.priority >= priority {
+		return false
+	}
+
+	l.queue = l.queue[:len(l.queue)-1]
+	last.admitted <- fmt.Errorf("%w: a request of a higher priority took the place in the queue", api.ErrLimitExceeded)
+	return true
+}
+
+// remove removes the waiter from the queue, it returns false if the waiter is not in the queue.
+func (l *Limiter) remove(w *waiter) bool {
+	for i, queued := range l.queue {
+		if queued == w {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// queued returns the number of the waiting requests.
+func (l *Limiter) queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.queue)
+}
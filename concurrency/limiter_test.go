@@ -0,0 +1,213 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func acquire(t *testing.T, l *Limiter, priority api.Priority) func(overloaded bool) {
+	release, err := l.Acquire(context.Background(), priority)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	return release
+}
+
+func TestLimiter_Adapt(t *testing.T) {
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(Options{InitialLimit: 2, MinLimit: 1, MaxLimit: 3, LatencyThreshold: 100 * time.Millisecond})
+	limiter.now = func() time.Time { return now }
+
+	// the requests completed in time while the limit is used increase the limit.
+	for i := 0; i < 4; i++ {
+		first, second := acquire(t, limiter, api.PriorityNormal), acquire(t, limiter, api.PriorityNormal)
+		now = now.Add(10 * time.Millisecond)
+		first(false)
+		second(false)
+	}
+
+	if limit := limiter.Limit(); limit != 3 {
+		t.Errorf("returned incorrect limit after the requests in time. got: %v, expected: %v", limit, 3)
+	}
+
+	// the slow requests admitted at the same time decrease the limit once.
+	releases := []func(bool){acquire(t, limiter, api.PriorityNormal), acquire(t, limiter, api.PriorityNormal), acquire(t, limiter, api.PriorityNormal)}
+	now = now.Add(time.Second)
+	for _, release := range releases {
+		release(false)
+	}
+
+	if limit := limiter.Limit(); limit != 2 {
+		t.Errorf("returned incorrect limit after the slow requests. got: %v, expected: %v", limit, 2)
+	}
+
+	// the overloaded requests decrease the limit down to the minimum.
+	for i := 0; i < 20; i++ {
+		now = now.Add(time.Millisecond)
+		release := acquire(t, limiter, api.PriorityNormal)
+		now = now.Add(time.Millisecond)
+		release(true)
+	}
+
+	if limit := limiter.Limit(); limit != 1 {
+		t.Errorf("returned incorrect limit after the overloaded requests. got: %v, expected: %v", limit, 1)
+	}
+
+	if inFlight := limiter.InFlight(); inFlight != 0 {
+		t.Errorf("returned incorrect requests in flight. got: %v, expected: %v", inFlight, 0)
+	}
+}
+
+func TestLimiter_Shed(t *testing.T) {
+	limiter := NewLimiter(Options{InitialLimit: 1, MaxLimit: 1, LatencyThreshold: time.Second, QueueSize: 1, MaxWait: 50 * time.Millisecond})
+	release := acquire(t, limiter, api.PriorityNormal)
+
+	// the sheddable requests are not queued.
+	if _, err := limiter.Acquire(context.Background(), api.PrioritySheddable); !errors.Is(err, api.ErrLimitExceeded) {
+		t.Errorf("returned incorrect error of the sheddable request. got: %v, expected: %v", err, api.ErrLimitExceeded)
+	}
+
+	// the queued requests give up after waiting for MaxWait.
+	start := time.Now()
+	if _, err := limiter.Acquire(context.Background(), api.PriorityNormal); !errors.Is(err, api.ErrLimitExceeded) {
+		t.Errorf("returned incorrect error of the queued request. got: %v, expected: %v", err, api.ErrLimitExceeded)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("returned incorrect wait of the queued request. got: %v, expected: at least %v", elapsed, 50*time.Millisecond)
+	}
+
+	// the critical request takes the place of the normal request in the full queue.
+	normal := make(chan error, 1)
+	go func() {
+		_, err := limiter.Acquire(context.Background(), api.PriorityNormal)
+		normal <- err
+	}()
+	for limiter.queued() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	critical := make(chan error, 1)
+	go func() {
+		release, err := limiter.Acquire(context.Background(), api.PriorityCritical)
+		if err == nil {
+			release(false)
+		}
+		critical <- err
+	}()
+
+	if err := <-normal; !errors.Is(err, api.ErrLimitExceeded) {
+		t.Errorf("returned incorrect error of the evicted request. got: %v, expected: %v", err, api.ErrLimitExceeded)
+	}
+
+	release(false)
+	if err := <-critical; err != nil {
+		t.Errorf("returned incorrect error of the critical request. got: %v, expected: nil", err)
+	}
+
+	if inFlight := limiter.InFlight(); inFlight != 0 {
+		t.Errorf("returned incorrect requests in flight. got: %v, expected: %v", inFlight, 0)
+	}
+}
+
+func TestLimiter_Priority(t *testing.T) {
+	limiter := NewLimiter(Options{InitialLimit: 1, MaxLimit: 1, LatencyThreshold: time.Second, QueueSize: 2, MaxWait: time.Second})
+	release := acquire(t, limiter, api.PriorityNormal)
+
+	admitted := make(chan api.Priority, 2)
+	for i, priority := range []api.Priority{api.PriorityNormal, api.PriorityCritical} {
+		go func(priority api.Priority) {
+			release, err := limiter.Acquire(context.Background(), priority)
+			if err != nil {
+				t.Errorf("Error on testing: %v", err)
+				admitted <- priority
+				return
+			}
+
+			admitted <- priority
+			release(false)
+		}(priority)
+
+		for limiter.queued() != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	release(false)
+	for _, expected := range []api.Priority{api.PriorityCritical, api.PriorityNormal} {
+		if priority := <-admitted; priority != expected {
+			t.Errorf("returned incorrect order of the admitted requests. got: %v, expected: %v", priority, expected)
+		}
+	}
+}
+
+func TestLimiter_Cancel(t *testing.T) {
+	limiter := NewLimiter(Options{InitialLimit: 1, MaxLimit: 1, LatencyThreshold: time.Second, QueueSize: 1, MaxWait: time.Minute})
+	release := acquire(t, limiter, api.PriorityNormal)
+	defer release(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.Acquire(ctx, api.PriorityCritical); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, context.DeadlineExceeded)
+	}
+
+	if queued := limiter.queued(); queued != 0 {
+		t.Errorf("returned incorrect queued requests. got: %v, expected: %v", queued, 0)
+	}
+}
+
+func TestLimiter_SharedByEndpoints(t *testing.T) {
+	limiter := NewLimiter(Options{InitialLimit: 4, MaxLimit: 4, LatencyThreshold: time.Minute, QueueSize: 1, MaxWait: time.Second, SheddableShare: 0.5})
+	priority := func(req *http.Request) api.Priority {
+		if req.URL.Path == "/records" {
+			return api.PrioritySheddable
+		}
+		return api.PriorityCritical
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	records := api.Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+	}), api.LimitConcurrency(limiter, priority))
+	inMemory := api.Chain(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}), api.LimitConcurrency(limiter, priority))
+
+	// the record queries in flight take their share of the limit.
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			records.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/records", nil))
+			done <- struct{}{}
+		}()
+		<-started
+	}
+
+	rr := httptest.NewRecorder()
+	records.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/records", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("returned incorrect status code of the record query. got: %v, expected: %v", rr.Code, http.StatusServiceUnavailable)
+	}
+
+	rr = httptest.NewRecorder()
+	inMemory.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/in-memory?key=active-tabs", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("returned incorrect status code of the in-memory read. got: %v, expected: %v", rr.Code, http.StatusOK)
+	}
+
+	close(release)
+	<-done
+	<-done
+	if inFlight := limiter.InFlight(); inFlight != 0 {
+		t.Errorf("returned incorrect requests in flight. got: %v, expected: %v", inFlight, 0)
+	}
+}
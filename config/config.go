@@ -4,7 +4,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"strconv"
+	"time"
 )
 
 // App general application configuration variables.
@@ -12,11 +15,52 @@ type App struct {
 	Api Api
 	Database Database
 	RedisConnectionString string
+	InMemory InMemory
+	Queue Queue
+	Auth Auth
+	RateLimit RateLimit
+	Concurrency Concurrency
+	Records Records
 }
 
 // Api represents api settings.
+// The in-flight requests are waited for ShutdownTimeout
+// while the application is shutting down. The metrics are served
+// on MetricsAddress if it is set, otherwise on Address.
+// The application reports not ready for DrainDelay before it stops accepting
+// requests, the dependencies are checked within ReadinessTimeout and the results
+// are reused for ReadinessCacheDuration.
+// The requests are served over TLS by TlsCertFile and TlsKeyFile if they are set. The client
+// certificates are verified by the CAs in TlsClientCaFile if it is set, they are required if
+// TlsClientCertRequired is true. The files are checked every TlsReloadInterval and read again when they are modified.
 type Api struct{
 	Address string
+	ShutdownTimeout time.Duration
+	MetricsAddress string
+	DrainDelay time.Duration
+	ReadinessTimeout time.Duration
+	ReadinessCacheDuration time.Duration
+	TlsCertFile string
+	TlsKeyFile string
+	TlsClientCaFile string
+	TlsClientCertRequired bool
+	TlsReloadInterval time.Duration
+}
+
+// Records represents records endpoint settings.
+// The records of each filter are cached in Redis for CacheTtl, zero disables the cache.
+// The cache is stored in the database of CacheRedisConnectionString, which is the database
+// of the in-memory keys by default.
+// The last records found for StaleMaxEntries filters within StaleMaxAge are served while
+// MongoDB is not available, zero StaleMaxEntries disables them. MongoDB is not queried for
+// BreakerCooldown after BreakerThreshold consecutive failures, zero BreakerThreshold disables it.
+type Records struct {
+	CacheTtl                   time.Duration
+	CacheRedisConnectionString string
+	StaleMaxEntries  int
+	StaleMaxAge      time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
 }
 
 // Database represents database connection settings.
@@ -25,6 +69,84 @@ type Database struct{
 	DefaultDatabaseName string
 }
 
+// InMemory represents in-memory database value settings.
+// Sizes are in bytes, zero disables the compression and the size limit.
+// The values are encrypted by the keys in EncryptionKeyfile if it is set.
+// QuotaMaxKeys and QuotaMaxBytes limit each namespace unless QuotaFile
+// configures a quota of its own, zero disables the limit.
+// The accesses to the keys are authorized by the rules in AclFile if it is set.
+type InMemory struct {
+	CompressionThreshold int
+	MaxValueSize         int
+	EncryptionKeyfile    string
+	QuotaMaxKeys         int
+	QuotaMaxBytes        int
+	QuotaFile            string
+	AclFile              string
+}
+
+// Queue represents work queue settings.
+type Queue struct {
+	MaxAttempts       int
+	VisibilityTimeout time.Duration
+	RetryDelay        time.Duration
+}
+
+// Auth represents authentication settings. The API keys are stored in MongoDB
+// if ApiKeyStore is "mongo", or in ApiKeyFile if it is "file". The requests are
+// not authenticated by API keys if ApiKeyStore is empty.
+// The bearer tokens are verified by the keys in JwksFile and by JwtSecret, they
+// must be issued by JwtIssuer for JwtAudience. The clients are granted the scopes
+// in JwtScopeClaim and restricted to the namespace in JwtNamespaceClaim if it is set.
+// The requests are not authenticated by bearer tokens if neither JwksFile nor JwtSecret is set.
+type Auth struct {
+	ApiKeyStore       string
+	ApiKeyFile        string
+	JwksFile          string
+	JwtSecret         string
+	JwtIssuer         string
+	JwtAudience       string
+	JwtClockSkew      time.Duration
+	JwtScopeClaim     string
+	JwtNamespaceClaim string
+}
+
+// RateLimit represents rate limit settings. The requests of each client to an
+// endpoint are limited to Rate per second with bursts of Burst requests, unless
+// the endpoint has a limit of its own in File. Zero Rate disables the limits.
+// The requests of each IP address to the authenticated endpoints are limited to AddressRate
+// per second with bursts of AddressBurst requests before they are authenticated,
+// zero AddressRate disables the limit. The limits are kept in memory for FallbackCooldown after Redis fails.
+type RateLimit struct {
+	Rate             float64
+	Burst            int
+	File             string
+	AddressRate      float64
+	AddressBurst     int
+	FallbackCooldown time.Duration
+}
+
+// Concurrency represents concurrency limit settings. The requests the endpoints
+// handle concurrently are limited, the limit starts at InitialLimit and adapts
+// between MinLimit and MaxLimit to keep the latencies below LatencyThreshold.
+// At most QueueSize requests wait for MaxWait while the limit is reached.
+// The sheddable requests take at most SheddableShare of the limit.
+// Zero MaxLimit disables the limit.
+type Concurrency struct {
+	InitialLimit     int
+	MinLimit         int
+	MaxLimit         int
+	LatencyThreshold time.Duration
+	QueueSize        int
+	MaxWait          time.Duration
+	SheddableShare   float64
+}
+
+// API key stores.
+const (
+	ApiKeyStoreMongo = "mongo"
+	ApiKeyStoreFile  = "file"
+)
 
 // ReadFromEnvironmentVariables reads environment variables to set application configuration settings.
 func ReadFromEnvironmentVariables() App {
@@ -34,13 +156,206 @@ func ReadFromEnvironmentVariables() App {
 	}
 
 	cnf := App{
-		Api: Api{Address: fmt.Sprintf(":%v", port)},
+		Api: Api{
+			Address:                fmt.Sprintf(":%v", port),
+			ShutdownTimeout:        durationFromEnv("SHUTDOWN_TIMEOUT", 30*time.Second),
+			MetricsAddress:         os.Getenv("METRICS_ADDRESS"),
+			DrainDelay:             durationFromEnv("SHUTDOWN_DRAIN_DELAY", 0),
+			ReadinessTimeout:       durationFromEnv("READINESS_TIMEOUT", time.Second),
+			ReadinessCacheDuration: durationFromEnv("READINESS_CACHE_DURATION", time.Second),
+			TlsCertFile:            os.Getenv("TLS_CERT_FILE"),
+			TlsKeyFile:             os.Getenv("TLS_KEY_FILE"),
+			TlsClientCaFile:        os.Getenv("TLS_CLIENT_CA_FILE"),
+			TlsClientCertRequired:  boolFromEnv("TLS_CLIENT_CERT_REQUIRED", false),
+			TlsReloadInterval:      durationFromEnv("TLS_RELOAD_INTERVAL", 30*time.Second),
+		},
 		Database: Database{
 			ConnectionString:    os.Getenv("DB_CONNECTION_STRING"),
 			DefaultDatabaseName: os.Getenv("DB_NAME"),
 		},
 		RedisConnectionString: os.Getenv("REDIS_URL"),
+		Records: Records{
+			CacheTtl:                   durationFromEnv("RECORDS_CACHE_TTL", 0),
+			CacheRedisConnectionString: os.Getenv("RECORDS_CACHE_REDIS_URL"),
+			StaleMaxEntries:  intFromEnv("RECORDS_STALE_MAX_ENTRIES", 1000),
+			StaleMaxAge:      durationFromEnv("RECORDS_STALE_MAX_AGE", 24*time.Hour),
+			BreakerThreshold: intFromEnv("RECORDS_BREAKER_THRESHOLD", 5),
+			BreakerCooldown:  durationFromEnv("RECORDS_BREAKER_COOLDOWN", 10*time.Second),
+		},
+		InMemory: InMemory{
+			CompressionThreshold: intFromEnv("INMEM_COMPRESSION_THRESHOLD", 1024),
+			MaxValueSize:         intFromEnv("INMEM_MAX_VALUE_SIZE", 0),
+			EncryptionKeyfile:    os.Getenv("INMEM_ENCRYPTION_KEYFILE"),
+			QuotaMaxKeys:         intFromEnv("INMEM_QUOTA_MAX_KEYS", 0),
+			QuotaMaxBytes:        intFromEnv("INMEM_QUOTA_MAX_BYTES", 0),
+			QuotaFile:            os.Getenv("INMEM_QUOTA_FILE"),
+			AclFile:              os.Getenv("INMEM_ACL_FILE"),
+		},
+		Queue: Queue{
+			MaxAttempts:       intFromEnv("QUEUE_MAX_ATTEMPTS", 5),
+			VisibilityTimeout: durationFromEnv("QUEUE_VISIBILITY_TIMEOUT", 30*time.Second),
+			RetryDelay:        durationFromEnv("QUEUE_RETRY_DELAY", 5*time.Second),
+		},
+		Auth: Auth{
+			ApiKeyStore:       os.Getenv("API_KEY_STORE"),
+			ApiKeyFile:        os.Getenv("API_KEY_FILE"),
+			JwksFile:          os.Getenv("JWT_JWKS_FILE"),
+			JwtSecret:         os.Getenv("JWT_SECRET"),
+			JwtIssuer:         os.Getenv("JWT_ISSUER"),
+			JwtAudience:       os.Getenv("JWT_AUDIENCE"),
+			JwtClockSkew:      durationFromEnv("JWT_CLOCK_SKEW", 30*time.Second),
+			JwtScopeClaim:     stringFromEnv("JWT_SCOPE_CLAIM", "scope"),
+			JwtNamespaceClaim: os.Getenv("JWT_NAMESPACE_CLAIM"),
+		},
+		RateLimit: RateLimit{
+			Rate:             floatFromEnv("RATE_LIMIT_RATE", 0),
+			Burst:            intFromEnv("RATE_LIMIT_BURST", 0),
+			File:             os.Getenv("RATE_LIMIT_FILE"),
+			AddressRate:      floatFromEnv("RATE_LIMIT_ADDRESS_RATE", 0),
+			AddressBurst:     intFromEnv("RATE_LIMIT_ADDRESS_BURST", 0),
+			FallbackCooldown: durationFromEnv("RATE_LIMIT_FALLBACK_COOLDOWN", 5*time.Second),
+		},
+		Concurrency: Concurrency{
+			InitialLimit:     intFromEnv("CONCURRENCY_INITIAL_LIMIT", 10),
+			MinLimit:         intFromEnv("CONCURRENCY_MIN_LIMIT", 1),
+			MaxLimit:         intFromEnv("CONCURRENCY_MAX_LIMIT", 0),
+			LatencyThreshold: durationFromEnv("CONCURRENCY_LATENCY_THRESHOLD", time.Second),
+			QueueSize:        intFromEnv("CONCURRENCY_QUEUE_SIZE", 100),
+			MaxWait:          durationFromEnv("CONCURRENCY_MAX_WAIT", 200*time.Millisecond),
+			SheddableShare:   floatFromEnv("CONCURRENCY_SHEDDABLE_SHARE", 0.8),
+		},
+	}
+
+	if cnf.Records.CacheRedisConnectionString == "" {
+		cnf.Records.CacheRedisConnectionString = cnf.RedisConnectionString
+	}
+
+	if cnf.RateLimit.Rate < 0 || cnf.RateLimit.Burst < 0 {
+		log.Fatalln("RATE_LIMIT_RATE and RATE_LIMIT_BURST variables must not be negative.")
+	}
+	// the bucket holds the requests of a second by default.
+	if cnf.RateLimit.Burst == 0 && cnf.RateLimit.Rate > 0 {
+		cnf.RateLimit.Burst = int(math.Ceil(cnf.RateLimit.Rate))
+	}
+
+	if cnf.RateLimit.AddressRate < 0 || cnf.RateLimit.AddressBurst < 0 {
+		log.Fatalln("RATE_LIMIT_ADDRESS_RATE and RATE_LIMIT_ADDRESS_BURST variables must not be negative.")
+	}
+	if cnf.RateLimit.AddressBurst == 0 && cnf.RateLimit.AddressRate > 0 {
+		cnf.RateLimit.AddressBurst = int(math.Ceil(cnf.RateLimit.AddressRate))
+	}
+
+	if (cnf.Api.TlsCertFile == "") != (cnf.Api.TlsKeyFile == "") {
+		log.Fatalln("TLS_CERT_FILE and TLS_KEY_FILE variables must be set together.")
+	}
+	if cnf.Api.TlsClientCaFile != "" && cnf.Api.TlsCertFile == "" {
+		log.Fatalln("TLS_CERT_FILE and TLS_KEY_FILE variables must be set if TLS_CLIENT_CA_FILE is set.")
+	}
+	if cnf.Api.TlsCertFile != "" && cnf.Api.TlsReloadInterval <= 0 {
+		log.Fatalln("TLS_RELOAD_INTERVAL variable must be positive.")
+	}
+
+	if cnf.Records.StaleMaxEntries < 0 || cnf.Records.BreakerThreshold < 0 {
+		log.Fatalln("RECORDS_STALE_MAX_ENTRIES and RECORDS_BREAKER_THRESHOLD variables must not be negative.")
+	}
+
+	if cnf.Concurrency.MaxLimit < 0 || cnf.Concurrency.QueueSize < 0 {
+		log.Fatalln("CONCURRENCY_MAX_LIMIT and CONCURRENCY_QUEUE_SIZE variables must not be negative.")
+	}
+	if cnf.Concurrency.MaxLimit > 0 && (cnf.Concurrency.MinLimit < 1 || cnf.Concurrency.MinLimit > cnf.Concurrency.MaxLimit) {
+		log.Fatalln("CONCURRENCY_MIN_LIMIT variable must be between 1 and CONCURRENCY_MAX_LIMIT.")
+	}
+	if cnf.Concurrency.SheddableShare <= 0 || cnf.Concurrency.SheddableShare > 1 {
+		log.Fatalln("CONCURRENCY_SHEDDABLE_SHARE variable must be greater than 0 and at most 1.")
+	}
+
+	switch cnf.Auth.ApiKeyStore {
+	case "", ApiKeyStoreMongo:
+	case ApiKeyStoreFile:
+		if cnf.Auth.ApiKeyFile == "" {
+			log.Fatalln("API_KEY_FILE variable must be set if API_KEY_STORE is file.")
+		}
+	default:
+		log.Fatalf("API_KEY_STORE variable must be %v or %v.", ApiKeyStoreMongo, ApiKeyStoreFile)
+	}
+
+	if (cnf.Auth.JwksFile != "" || cnf.Auth.JwtSecret != "") && (cnf.Auth.JwtIssuer == "" || cnf.Auth.JwtAudience == "") {
+		log.Fatalln("JWT_ISSUER and JWT_AUDIENCE variables must be set if JWT_JWKS_FILE or JWT_SECRET is set.")
 	}
 
 	return cnf
 }
+
+// intFromEnv reads an integer environment variable.
+// If the variable is not set, it returns the default value.
+func intFromEnv(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("%v variable must be an integer: %v", name, err)
+	}
+
+	return i
+}
+
+// floatFromEnv reads a floating point number environment variable.
+// If the variable is not set, it returns the default value.
+func floatFromEnv(name string, defaultValue float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		log.Fatalf("%v variable must be a number: %v", name, value)
+	}
+
+	return f
+}
+
+// durationFromEnv reads a duration environment variable such as "30s" or "1m".
+// If the variable is not set, it returns the default value.
+func durationFromEnv(name string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("%v variable must be a duration: %v", name, err)
+	}
+
+	return d
+}
+
+// stringFromEnv reads a string environment variable.
+// If the variable is not set, it returns the default value.
+func stringFromEnv(name string, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+// boolFromEnv reads a boolean environment variable such as "true" or "false".
+// If the variable is not set, it returns the default value.
+func boolFromEnv(name string, defaultValue bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("%v variable must be a boolean: %v", name, err)
+	}
+
+	return b
+}
@@ -0,0 +1,167 @@
+// Package health reports whether the application is alive and ready to serve requests.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Statuses of the application and its dependencies.
+const (
+	StatusOK           = "ok"
+	StatusReady        = "ready"
+	StatusNotReady     = "not ready"
+	StatusShuttingDown = "shutting down"
+	StatusUp           = "up"
+	StatusDown         = "down"
+)
+
+// Check represents a dependency of the application, Ping
+// returns an error if the dependency cannot be reached.
+type Check struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+// Result represents the result of a check.
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Response represents the response of the health endpoints.
+type Response struct {
+	Status    string     `json:"status"`
+	Checks    []Result   `json:"checks,omitempty"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+}
+
+// Checker runs the checks of the dependencies for the readiness probes.
+type Checker struct {
+	timeout       time.Duration
+	cacheDuration time.Duration
+	checks        []Check
+
+	mu           sync.Mutex
+	results      []Result
+	checkedAt    time.Time
+	shuttingDown bool
+
+	// now is replaced in the tests.
+	now func() time.Time
+}
+
+// NewChecker creates a checker running the checks concurrently. Each check is given
+// the timeout to complete, the results are reused for the cache duration so that
+// the dependencies are not pinged for every probe.
+func NewChecker(timeout, cacheDuration time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		timeout:       timeout,
+		cacheDuration: cacheDuration,
+		checks:        checks,
+		now:           time.Now,
+	}
+}
+
+// ShutDown marks the application as shutting down, it is not ready from then on
+// so that the traffic is routed to the other instances while it is drained.
+func (c *Checker) ShutDown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.shuttingDown = true
+}
+
+// Ready runs the checks unless the cached results are recent enough,
+// the application is ready if all of its dependencies are up.
+// The concurrent probes wait for the checks of the first one.
+func (c *Checker) Ready(ctx context.Context) Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shuttingDown {
+		return Response{Status: StatusShuttingDown}
+	}
+
+	if c.results == nil || c.now().Sub(c.checkedAt) >= c.cacheDuration {
+		c.results = c.run(ctx)
+		c.checkedAt = c.now()
+	}
+
+	status := StatusReady
+	for _, result := range c.results {
+		if result.Status != StatusUp {
+			status = StatusNotReady
+		}
+	}
+
+	checkedAt := c.checkedAt
+	return Response{Status: status, Checks: c.results, CheckedAt: &checkedAt}
+}
+
+// run runs the checks concurrently, each of them within the timeout.
+func (c *Checker) run(ctx context.Context) []Result {
+	results := make([]Result, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Ping(checkCtx)
+			results[i] = Result{
+				Name:      check.Name,
+				Status:    StatusUp,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				log.Printf("Error on checking %v: %v", check.Name, err)
+				results[i].Status = StatusDown
+				results[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Liveness handles the liveness probes, the application is alive as long as it can respond.
+func Liveness(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, http.StatusOK, Response{Status: StatusOK})
+}
+
+// Readiness returns the handler of the readiness probes. It responds
+// 503 Service Unavailable if the application is not ready.
+func (c *Checker) Readiness() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := c.Ready(r.Context())
+
+		statusCode := http.StatusOK
+		if response.Status != StatusReady {
+			statusCode = http.StatusServiceUnavailable
+		}
+		writeResponse(w, statusCode, response)
+	})
+}
+
+func writeResponse(w http.ResponseWriter, statusCode int, response Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error on encoding health response: %v", err)
+	}
+}
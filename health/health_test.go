@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLiveness(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	http.HandlerFunc(Liveness).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", recorder.Code, http.StatusOK)
+	}
+
+	expected := `{"status":"ok"}` + "\n"
+	if recorder.Body.String() != expected {
+		t.Errorf("returned incorrect body. got: %v, expected: %v", recorder.Body.String(), expected)
+	}
+}
+
+func TestChecker_Readiness(t *testing.T) {
+	tests := []struct {
+		mongoErr   error
+		statusCode int
+		status     string
+	}{
+		{nil, http.StatusOK, StatusReady},
+		{errors.New("connection refused"), http.StatusServiceUnavailable, StatusNotReady},
+	}
+
+	for _, test := range tests {
+		checker := NewChecker(time.Second, 0,
+			Check{Name: "mongodb", Ping: func(ctx context.Context) error { return test.mongoErr }},
+			Check{Name: "redis", Ping: func(ctx context.Context) error { return nil }},
+		)
+
+		recorder := httptest.NewRecorder()
+		checker.Readiness().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		if recorder.Code != test.statusCode {
+			t.Errorf("returned incorrect status code. got: %v, expected: %v", recorder.Code, test.statusCode)
+		}
+
+		var response Response
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if response.Status != test.status {
+			t.Errorf("returned incorrect status. got: %v, expected: %v", response.Status, test.status)
+		}
+
+		if len(response.Checks) != 2 || response.Checks[0].Name != "mongodb" || response.Checks[1].Status != StatusUp {
+			t.Fatalf("returned incorrect checks. got: %v, expected: mongodb and redis", response.Checks)
+		}
+
+		if test.mongoErr != nil && (response.Checks[0].Status != StatusDown || response.Checks[0].Error != test.mongoErr.Error()) {
+			t.Errorf("returned incorrect check. got: %v, expected: down with %v", response.Checks[0], test.mongoErr)
+		}
+	}
+}
+
+func TestChecker_ReadyTimeout(t *testing.T) {
+	checker := NewChecker(10*time.Millisecond, 0, Check{Name: "mongodb", Ping: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	response := checker.Ready(context.Background())
+	if response.Status != StatusNotReady || response.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("returned incorrect response. got: %v, expected: not ready by the timeout", response)
+	}
+}
+
+func TestChecker_ReadyCached(t *testing.T) {
+	var pings int32
+	checker := NewChecker(time.Second, time.Minute, Check{Name: "redis", Ping: func(ctx context.Context) error {
+		atomic.AddInt32(&pings, 1)
+		return nil
+	}})
+
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time { return now }
+
+	checker.Ready(context.Background())
+	now = now.Add(30 * time.Second)
+	response := checker.Ready(context.Background())
+	if pings != 1 {
+		t.Errorf("returned incorrect number of pings. got: %v, expected: %v", pings, 1)
+	}
+
+	if !response.CheckedAt.Equal(now.Add(-30 * time.Second)) {
+		t.Errorf("returned incorrect check time. got: %v, expected: %v", response.CheckedAt, now.Add(-30*time.Second))
+	}
+
+	now = now.Add(30 * time.Second)
+	checker.Ready(context.Background())
+	if pings != 2 {
+		t.Errorf("returned incorrect number of pings. got: %v, expected: %v", pings, 2)
+	}
+}
+
+func TestChecker_ShutDown(t *testing.T) {
+	checker := NewChecker(time.Second, 0, Check{Name: "redis", Ping: func(ctx context.Context) error { return nil }})
+	checker.ShutDown()
+
+	recorder := httptest.NewRecorder()
+	checker.Readiness().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", recorder.Code, http.StatusServiceUnavailable)
+	}
+
+	expected := `{"status":"shutting down"}` + "\n"
+	if recorder.Body.String() != expected {
+		t.Errorf("returned incorrect body. got: %v, expected: %v", recorder.Body.String(), expected)
+	}
+}
@@ -0,0 +1,249 @@
+package inmem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+// Actions authorized by an Acl.
+const (
+	ActionRead  = "read"
+	ActionWrite = "write"
+)
+
+// Effects of the ACL rules.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// AnonymousSubject is the subject of the requests which are not authenticated.
+const AnonymousSubject = "anonymous"
+
+// ErrAccessDenied is returned when an Acl does not allow the access to a key.
+var ErrAccessDenied = errors.New("access denied")
+
+// AclRule allows or denies the actions on the keys matching Keys to the clients
+// whose subjects match Subjects. The patterns match any sequence of characters
+// by "*" and a single character by "?", e.g. "config/*" matches "config/team-a/x".
+// The rule applies to the keys of the namespaces matching Namespaces, or of all the
+// namespaces if it is empty. The default namespace is matched by an empty pattern.
+// Name identifies the rule in the deny responses.
+type AclRule struct {
+	Name       string   `json:"name"`
+	Effect     string   `json:"effect"`
+	Subjects   []string `json:"subjects"`
+	Actions    []string `json:"actions"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Keys       []string `json:"keys"`
+}
+
+// Acl authorizes the access of the clients to the keys of the namespaces by its rules.
+// A deny rule overrides the allow rules, and the access is denied if no rule allows it.
+type Acl struct {
+	Rules []AclRule
+}
+
+// LoadAcl reads the rules of an Acl from a JSON file in the format of
+// [{"name": "read-config", "effect": "allow", "subjects": ["apikey:*"], "actions": ["read"], "namespaces": ["team-a"], "keys": ["config/*"]}].
+func LoadAcl(path string) (*Acl, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var acl Acl
+	err = json.Unmarshal(content, &acl.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing the acl file: %w", err)
+	}
+
+	for i, rule := range acl.Rules {
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			return nil, fmt.Errorf("effect of the rule %v must be %v or %v", rule.name(i), EffectAllow, EffectDeny)
+		}
+
+		if len(rule.Subjects) == 0 || len(rule.Actions) == 0 || len(rule.Keys) == 0 {
+			return nil, fmt.Errorf("subjects, actions and keys of the rule %v must not be empty", rule.name(i))
+		}
+
+		for _, action := range rule.Actions {
+			if action != ActionRead && action != ActionWrite {
+				return nil, fmt.Errorf("actions of the rule %v must be %v or %v", rule.name(i), ActionRead, ActionWrite)
+			}
+		}
+	}
+
+	return &acl, nil
+}
+
+// Authorize checks whether the subject is allowed the action on the key of the namespace.
+// The error explains the rule denying the access, or that no rule allows it.
+func (a *Acl) Authorize(subject, action, namespace, key string) error {
+	allowed := false
+	for i, rule := range a.Rules {
+		if !rule.matches(subject, action, namespace) || !matchesAny(rule.Keys, key) {
+			continue
+		}
+
+		if rule.Effect == EffectDeny {
+			return fmt.Errorf("%w: %v access to the key %q is denied to %v by the rule %v", ErrAccessDenied, action, key, subject, rule.name(i))
+		}
+		allowed = true
+	}
+
+	if !allowed {
+		return fmt.Errorf("%w: %v access to the key %q is not allowed to %v by any rule", ErrAccessDenied, action, key, subject)
+	}
+
+	return nil
+}
+
+// AuthorizeAll checks whether the subject is allowed the action on all the keys of the namespace,
+// e.g. to list or to flush them. A rule must allow the action on the keys by the "*" pattern,
+// and any deny rule of the subject on the action denies it since the rule denies some of the keys.
+func (a *Acl) AuthorizeAll(subject, action, namespace string) error {
+	allowed := false
+	for i, rule := range a.Rules {
+		if !rule.matches(subject, action, namespace) {
+			continue
+		}
+
+		if rule.Effect == EffectDeny {
+			return fmt.Errorf("%w: %v access to all the keys is denied to %v by the rule %v", ErrAccessDenied, action, subject, rule.name(i))
+		}
+
+		if contains(rule.Keys, "*") {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return fmt.Errorf("%w: %v access to all the keys is not allowed to %v by any rule", ErrAccessDenied, action, subject)
+	}
+
+	return nil
+}
+
+// matches reports whether the rule applies to the action of the subject in the namespace.
+func (r AclRule) matches(subject, action, namespace string) bool {
+	return contains(r.Actions, action) && matchesAny(r.Subjects, subject) &&
+		(len(r.Namespaces) == 0 || matchesAny(r.Namespaces, namespace))
+}
+
+// name returns the name of the rule, or its position in the file if it is not named.
+func (r AclRule) name(i int) string {
+	if r.Name != "" {
+		return fmt.Sprintf("%q", r.Name)
+	}
+
+	return fmt.Sprintf("#%v", i+1)
+}
+
+// checkAccess checks the access of the client of the request to the key of the namespace
+// of the request, or to all the keys of the namespace if key is empty.
+// All the accesses are allowed if acl is nil.
+func checkAccess(acl *Acl, req *http.Request, action, key string) error {
+	if acl == nil {
+		return nil
+	}
+
+	namespace, err := NamespaceOf(req)
+	if err != nil {
+		return err
+	}
+
+	subject := AnonymousSubject
+	if principal, ok := api.PrincipalFromContext(req.Context()); ok {
+		subject = principal.Subject
+	}
+
+	if key == "" {
+		err = acl.AuthorizeAll(subject, action, namespace)
+	} else {
+		err = acl.Authorize(subject, action, namespace, key)
+	}
+	if err != nil {
+		log.Printf("Error on authorizing the access to the key: %v", err)
+	}
+
+	return err
+}
+
+// authorize checks the access of the client of the request to the key and responds
+// 403 Forbidden with the explanation if it is denied.
+func authorize(acl *Acl, rw http.ResponseWriter, req *http.Request, action, key string) bool {
+	if err := checkAccess(acl, req, action, key); err != nil {
+		rw.Header().Set("Content-Type", "application/json")
+		writeJSON(rw, statusCodeOf(err), Response{Key: key, Error: err.Error()})
+		return false
+	}
+
+	return true
+}
+
+// actionOf returns the action of the request, the safe methods read the keys and the others write them.
+func actionOf(req *http.Request) string {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ActionRead
+	default:
+		return ActionWrite
+	}
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPattern reports whether the value matches the pattern, "*" matches
+// any sequence of characters including "/" and "?" matches a single character.
+func matchPattern(pattern, value string) bool {
+	p, v := []rune(pattern), []rune(value)
+	// star and match are the positions to backtrack to after the last "*".
+	star, match := -1, 0
+	i, j := 0, 0
+	for j < len(v) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, match = i, j
+			i++
+		case i < len(p) && (p[i] == '?' || p[i] == v[j]):
+			i++
+			j++
+		case star >= 0:
+			i = star + 1
+			match++
+			j = match
+		default:
+			return false
+		}
+	}
+
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+
+	return i == len(p)
+}
@@ -0,0 +1,280 @@
+package inmem
+
+import (
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{"config/*", "config/team-a/tabs", true},
+		{"config/*", "config/", true},
+		{"config/*", "config", false},
+		{"config/team-?/*", "config/team-a/tabs", true},
+		{"config/team-?/*", "config/team-ab/tabs", false},
+		{"*/tabs", "config/team-a/tabs", true},
+		{"*a", "*ba", true},
+		{"active-tabs", "active-tabs", true},
+		{"active-tabs", "active-tabs-2", false},
+		{"*", "", true},
+	}
+
+	for _, test := range tests {
+		if got := matchPattern(test.pattern, test.value); got != test.expected {
+			t.Errorf("returned incorrect match of %q by %q. got: %v, expected: %v", test.value, test.pattern, got, test.expected)
+		}
+	}
+}
+
+// teamAcl allows the clients of team A to read the configs of all the teams but to write only the configs of team A,
+// the secrets of the default namespace are denied to all. The clients of team B own the keys of their namespace.
+var teamAcl = &Acl{Rules: []AclRule{
+	{Name: "read-config", Effect: EffectAllow, Subjects: []string{"apikey:team-a", "jwt:*"}, Actions: []string{ActionRead}, Keys: []string{"config/*"}},
+	{Name: "write-team-config", Effect: EffectAllow, Subjects: []string{"apikey:team-a"}, Actions: []string{ActionRead, ActionWrite}, Keys: []string{"config/team-a/*"}},
+	{Effect: EffectDeny, Subjects: []string{"*"}, Actions: []string{ActionRead, ActionWrite}, Namespaces: []string{""}, Keys: []string{"config/*/secrets"}},
+	{Name: "team-b", Effect: EffectAllow, Subjects: []string{"apikey:team-b"}, Actions: []string{ActionRead, ActionWrite}, Namespaces: []string{"team-b"}, Keys: []string{"*"}},
+	{Name: "read-all", Effect: EffectAllow, Subjects: []string{"jwt:admin"}, Actions: []string{ActionRead}, Keys: []string{"*"}},
+}}
+
+func TestAcl_Authorize(t *testing.T) {
+	tests := []struct {
+		subject   string
+		action    string
+		namespace string
+		key       string
+		err       string
+	}{
+		{"apikey:team-a", ActionRead, "", "config/team-b/tabs", ""},
+		{"apikey:team-a", ActionWrite, "", "config/team-a/tabs", ""},
+		{"jwt:billing", ActionRead, "", "config/team-a/tabs", ""},
+		{"apikey:team-a", ActionRead, "team-b", "config/team-a/secrets", ""},
+		{"apikey:team-b", ActionWrite, "team-b", "active-tabs", ""},
+		{"apikey:team-a", ActionWrite, "", "config/team-b/tabs", `access denied: write access to the key "config/team-b/tabs" is not allowed to apikey:team-a by any rule`},
+		{"apikey:team-a", ActionRead, "", "config/team-a/secrets", `access denied: read access to the key "config/team-a/secrets" is denied to apikey:team-a by the rule #3`},
+		{AnonymousSubject, ActionRead, "", "config/team-a/tabs", `access denied: read access to the key "config/team-a/tabs" is not allowed to anonymous by any rule`},
+		{"apikey:team-b", ActionWrite, "", "active-tabs", `access denied: write access to the key "active-tabs" is not allowed to apikey:team-b by any rule`},
+	}
+
+	for _, test := range tests {
+		err := teamAcl.Authorize(test.subject, test.action, test.namespace, test.key)
+		if test.err == "" && err != nil {
+			t.Errorf("returned incorrect error for %v %v %q %v. got: %v, expected: nil", test.subject, test.action, test.namespace, test.key, err)
+		}
+
+		if test.err != "" && (!errors.Is(err, ErrAccessDenied) || err.Error() != test.err) {
+			t.Errorf("returned incorrect error for %v %v %q %v. got: %v, expected: %v", test.subject, test.action, test.namespace, test.key, err, test.err)
+		}
+	}
+}
+
+func TestAcl_AuthorizeAll(t *testing.T) {
+	tests := []struct {
+		subject   string
+		action    string
+		namespace string
+		err       string
+	}{
+		{"apikey:team-b", ActionWrite, "team-b", ""},
+		{"jwt:admin", ActionRead, "team-b", ""},
+		{"apikey:team-a", ActionRead, "team-b", `access denied: read access to all the keys is not allowed to apikey:team-a by any rule`},
+		{"apikey:team-b", ActionWrite, "team-a", `access denied: write access to all the keys is not allowed to apikey:team-b by any rule`},
+		{"jwt:admin", ActionRead, "", `access denied: read access to all the keys is denied to jwt:admin by the rule #3`},
+	}
+
+	for _, test := range tests {
+		err := teamAcl.AuthorizeAll(test.subject, test.action, test.namespace)
+		if test.err == "" && err != nil {
+			t.Errorf("returned incorrect error for %v %v %q. got: %v, expected: nil", test.subject, test.action, test.namespace, err)
+		}
+
+		if test.err != "" && (!errors.Is(err, ErrAccessDenied) || err.Error() != test.err) {
+			t.Errorf("returned incorrect error for %v %v %q. got: %v, expected: %v", test.subject, test.action, test.namespace, err, test.err)
+		}
+	}
+}
+
+func TestLoadAcl(t *testing.T) {
+	tests := []struct {
+		content string
+		valid   bool
+	}{
+		{`[{"name": "read-config", "effect": "allow", "subjects": ["apikey:*"], "actions": ["read"], "keys": ["config/*"]}]`, true},
+		{`[{"effect": "allow", "subjects": ["apikey:*"], "actions": ["read"], "namespaces": ["team-*"], "keys": ["*"]}]`, true},
+		{`[{"effect": "permit", "subjects": ["*"], "actions": ["read"], "keys": ["*"]}]`, false},
+		{`[{"effect": "allow", "subjects": ["*"], "actions": ["delete"], "keys": ["*"]}]`, false},
+		{`[{"effect": "allow", "subjects": ["*"], "actions": ["read"]}]`, false},
+		{`{"effect": "allow"}`, false},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "acl.json")
+		if err := ioutil.WriteFile(path, []byte(test.content), 0600); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		acl, err := LoadAcl(path)
+		if test.valid && (err != nil || len(acl.Rules) != 1) {
+			t.Errorf("returned incorrect acl for %v. got: %v %v, expected: 1 rule", test.content, acl, err)
+		}
+
+		if !test.valid && err == nil {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: an error", test.content, err)
+		}
+	}
+}
+
+func TestController_ServeHTTPAcl(t *testing.T) {
+	mock := mockService{
+		GetMock: func(key string) (Response, error) {
+			return Response{Key: key, Value: "getir"}, nil
+		},
+		SetMock: func(key string, value string) (Response, error) {
+			return Response{Key: key, Value: value}, nil
+		},
+	}
+	controller := Controller{Repository: mock, Acl: teamAcl}
+
+	tests := []struct {
+		method     string
+		target     string
+		body       string
+		statusCode int
+		expected   string
+	}{
+		{http.MethodGet, "/in-memory?key=config/team-b/tabs", "", http.StatusOK, `{"key":"config/team-b/tabs","value":"getir"}`},
+		{http.MethodPost, "/in-memory", `{"key": "config/team-a/tabs", "value": "3"}`, http.StatusOK, `{"key":"config/team-a/tabs","value":"3"}`},
+		{http.MethodPost, "/in-memory", `{"key": "config/team-b/tabs", "value": "3"}`, http.StatusForbidden,
+			`{"key":"config/team-b/tabs","value":"","error":"access denied: write access to the key \"config/team-b/tabs\" is not allowed to apikey:team-a by any rule"}`},
+		{http.MethodGet, "/in-memory?key=config/team-a/secrets", "", http.StatusForbidden,
+			`{"key":"config/team-a/secrets","value":"","error":"access denied: read access to the key \"config/team-a/secrets\" is denied to apikey:team-a by the rule #3"}`},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+		req = req.WithContext(api.ContextWithPrincipal(req.Context(), api.Principal{Subject: "apikey:team-a"}))
+
+		rr := httptest.NewRecorder()
+		controller.ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v %v. got: %v, expected: %v", test.method, test.target, rr.Code, test.statusCode)
+		}
+
+		if rr.Body.String() != test.expected {
+			t.Errorf("returned incorrect response body for %v %v. got: %v, expected: %v", test.method, test.target, rr.Body.String(), test.expected)
+		}
+	}
+}
+
+func TestKeyController_ServeHTTPAcl(t *testing.T) {
+	mock := mockDao{
+		SetMock: func(dto Dto) (Dto, error) {
+			t.Errorf("Error on testing: the denied value %v is stored", dto.Key)
+			return dto, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/in-memory/config%2Fteam-b%2Ftabs", strings.NewReader("3"))
+	req = req.WithContext(api.ContextWithPrincipal(req.Context(), api.Principal{Subject: "apikey:team-a"}))
+
+	rr := httptest.NewRecorder()
+	controller := KeyController{Repository: Service{Dao: mock}, Acl: teamAcl}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusForbidden)
+	}
+
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("returned incorrect content type. got: %v, expected: %v", contentType, "application/json")
+	}
+}
+
+func TestHashController_ServeHTTPAcl(t *testing.T) {
+	mock := mockHashDao{
+		HashGetAllMock: func(key string) (HashDto, error) {
+			return HashDto{Key: key, Fields: map[string]string{"tabs": "3"}, Exists: true}, nil
+		},
+		HashSetMock: func(dto HashDto) error {
+			t.Errorf("Error on testing: the denied field of %v is stored", dto.Key)
+			return nil
+		},
+	}
+	controller := HashController{Repository: HashService{Dao: mock}, Acl: teamAcl}
+
+	tests := []struct {
+		method     string
+		target     string
+		body       string
+		statusCode int
+		expected   string
+	}{
+		{http.MethodGet, "/in-memory/hashes?key=config/team-b/tabs", "", http.StatusOK, `{"key":"config/team-b/tabs","fields":{"tabs":"3"}}`},
+		{http.MethodPost, "/in-memory/hashes", `{"key": "config/team-b/tabs", "field": "tabs", "value": "3"}`, http.StatusForbidden,
+			`{"key":"config/team-b/tabs","error":"access denied: write access to the key \"config/team-b/tabs\" is not allowed to apikey:team-a by any rule"}`},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+		req = req.WithContext(api.ContextWithPrincipal(req.Context(), api.Principal{Subject: "apikey:team-a"}))
+
+		rr := httptest.NewRecorder()
+		controller.ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v %v. got: %v, expected: %v", test.method, test.target, rr.Code, test.statusCode)
+		}
+
+		if rr.Body.String() != test.expected {
+			t.Errorf("returned incorrect response body for %v %v. got: %v, expected: %v", test.method, test.target, rr.Body.String(), test.expected)
+		}
+	}
+}
+
+func TestNamespaceController_ServeHTTPAcl(t *testing.T) {
+	mock := mockNamespaceService{
+		FlushMock: func() (NamespaceResponse, error) {
+			removed := int64(2)
+			return NamespaceResponse{Namespace: "team-b", Removed: &removed}, nil
+		},
+	}
+	controller := NamespaceController{Repository: mock, Acl: teamAcl}
+
+	tests := []struct {
+		subject    string
+		namespace  string
+		statusCode int
+		expected   string
+	}{
+		{"apikey:team-b", "team-b", http.StatusOK, `{"namespace":"team-b","removed":2}`},
+		{"apikey:team-a", "team-b", http.StatusForbidden,
+			`{"namespace":"","error":"access denied: write access to all the keys is not allowed to apikey:team-a by any rule"}`},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/namespaces/flush", nil)
+		req.Header.Set(NamespaceHeader, test.namespace)
+		req = req.WithContext(api.ContextWithPrincipal(req.Context(), api.Principal{Subject: test.subject}))
+
+		rr := httptest.NewRecorder()
+		controller.ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.subject, rr.Code, test.statusCode)
+		}
+
+		if rr.Body.String() != test.expected {
+			t.Errorf("returned incorrect response body for %v. got: %v, expected: %v", test.subject, rr.Body.String(), test.expected)
+		}
+	}
+}
@@ -2,10 +2,17 @@
 package inmem
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
 )
 
 // Repository interface is used for interacting with an in-memory database.
@@ -13,31 +20,38 @@ import (
 type Repository interface{
 	Get(string) (Response, error)
 	Set(string, string) (Response, error)
+	Store(Dto) (Response, error)
 }
 
 // Controller is a handler for handling
 // requests coming to "/in-memory" endpoint.
+// The accesses to the keys are authorized by Acl if it is set.
+// The request bodies are read up to the size of a value of MaxValueSize bytes,
+// zero MaxValueSize means that there is no limit.
 type Controller struct{
 	Repository Repository
+	Acl *Acl
+	MaxValueSize int
 }
 
 // ServeHTTP handles incoming requests to "/in-memory" endpoint.
 // Communicates with an in-memory database via a Repository.
-// POST requests set key and value pairs.
+// POST requests set key and value pairs, either as JSON payloads
+// or as "application/octet-stream" uploads with the key in the query.
 // GET requests fetch the values of the keys.
 func (c Controller) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	// the endpoint always returns JSON response.
-	// to notice the client about the content type,
-	// it is a great practice to specify in header.
-	rw.Header().Add("Content-Type", "application/json")
-
 	// the methods except POST and GET are not allowed.
 	switch req.Method {
 	case http.MethodPost:
-		payload, err := c.parseRequestJSON(req)
+		if isOctetStream(req.Header.Get("Content-Type")) {
+			c.upload(rw, req)
+			break
+		}
+
+		payload, err := c.parseRequestJSON(rw, req)
 		if err != nil {
 			log.Printf("Error on parsing request JSON: %v", err)
-			c.badRequest(rw, err.Error())
+			c.writeResponse(rw, requestErrorStatus(err), Response{Error: err.Error()})
 			return
 		}
 
@@ -46,24 +60,38 @@ func (c Controller) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			break
 		}
 
-		// if an error is returned, it means that
-		// an internal server error occurred.
-		resp, err := c.Repository.Set(*payload.Key, *payload.Value)
-		statusCode := http.StatusOK
+		if !authorize(c.Acl, rw, req, ActionWrite, *payload.Key) {
+			break
+		}
+
+		// the values without metadata are plain strings.
+		var resp Response
+		if payload.Encoding == "" && payload.ContentType == "" {
+			resp, err = c.Repository.Set(*payload.Key, *payload.Value)
+		} else {
+			dto, ok := c.toDto(rw, payload)
+			if !ok {
+				break
+			}
+			resp, err = c.Repository.Store(dto)
+		}
 		if err != nil {
 			log.Printf("Error while setting the value: %v", err)
-			statusCode = http.StatusInternalServerError
 		}
+		statusCode := statusCodeOf(err)
 
 		c.writeResponse(rw, statusCode, resp)
 	case http.MethodGet:
 		key := req.URL.Query().Get("key")
+		if !authorize(c.Acl, rw, req, ActionRead, key) {
+			break
+		}
+
 		resp, err := c.Repository.Get(key)
-		statusCode := http.StatusOK
 		if err != nil {
 			log.Printf("Error while getting the value: %v", err)
-			statusCode = http.StatusInternalServerError
 		}
+		statusCode := statusCodeOf(err)
 
 		c.writeResponse(rw, statusCode, resp)
 	default:
@@ -71,6 +99,93 @@ func (c Controller) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// upload stores the request body as a binary value of the key
+// given in the query. The content type of the value is given by
+// the "contentType" query parameter, it is "application/octet-stream" by default.
+func (c Controller) upload(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	key := query.Get("key")
+	if key == "" {
+		c.badRequest(rw, "key parameter is missing")
+		return
+	}
+
+	if !authorize(c.Acl, rw, req, ActionWrite, key) {
+		return
+	}
+
+	contentType := query.Get("contentType")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if len(contentType) > maxContentTypeLength {
+		c.badRequest(rw, "contentType parameter is too long")
+		return
+	}
+
+	body, err := readBody(rw, req, int64(c.MaxValueSize))
+	if errors.Is(err, ErrValueTooLarge) {
+		c.writeResponse(rw, http.StatusRequestEntityTooLarge, Response{Key: key, Error: err.Error()})
+		return
+	}
+
+	if err != nil {
+		log.Printf("Error on reading the request body: %v", err)
+		c.badRequest(rw, "bad request")
+		return
+	}
+
+	resp, err := c.Repository.Store(Dto{
+		Key:         key,
+		Value:       string(body),
+		ContentType: contentType,
+		Binary:      true,
+	})
+	if err != nil {
+		log.Printf("Error while setting the value: %v", err)
+	}
+
+	c.writeResponse(rw, statusCodeOf(err), resp)
+}
+
+// toDto converts a request carrying an encoding or a content type to a Dto.
+// The base64 encoded values are decoded and stored as binary values.
+func (c Controller) toDto(rw http.ResponseWriter, payload Request) (Dto, bool) {
+	dto := Dto{
+		Key:         *payload.Key,
+		Value:       *payload.Value,
+		ContentType: payload.ContentType,
+	}
+
+	if len(payload.ContentType) > maxContentTypeLength {
+		c.badRequest(rw, "contentType field is too long")
+		return dto, false
+	}
+
+	switch payload.Encoding {
+	case "":
+	case EncodingBase64:
+		value, err := base64.StdEncoding.DecodeString(*payload.Value)
+		if err != nil {
+			c.badRequest(rw, "value field is not valid base64")
+			return dto, false
+		}
+		dto.Value, dto.Binary = string(value), true
+	default:
+		c.badRequest(rw, fmt.Sprintf("encoding must be %v", EncodingBase64))
+		return dto, false
+	}
+
+	return dto, true
+}
+
+// isOctetStream reports whether the content type is "application/octet-stream".
+func isOctetStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/octet-stream"
+}
+
 // validateRequest checks whether there are missing fields or not.
 func (c Controller) validateRequest(rw http.ResponseWriter, payload Request) bool {
 	if payload.Key == nil {
@@ -88,8 +203,8 @@ func (c Controller) validateRequest(rw http.ResponseWriter, payload Request) boo
 
 // parseRequestJSON reads all request body and
 // unmarshals the JSON to Request object.
-func (c Controller) parseRequestJSON(r *http.Request) (Request, error) {
-	body, err := ioutil.ReadAll(r.Body)
+func (c Controller) parseRequestJSON(rw http.ResponseWriter, r *http.Request) (Request, error) {
+	body, err := readBody(rw, r, jsonBodyLimit(c.MaxValueSize))
 	if err != nil {
 		return Request{}, err
 	}
@@ -103,6 +218,89 @@ func (c Controller) parseRequestJSON(r *http.Request) (Request, error) {
 	return payload, err
 }
 
+// parseJSON reads all request body up to limit bytes and
+// unmarshals the JSON to the payload object.
+func parseJSON(rw http.ResponseWriter, r *http.Request, payload interface{}, limit int64) error {
+	body, err := readBody(rw, r, limit)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, payload)
+}
+
+// maxJSONOverhead is the size of a JSON request allowed besides its escaped values,
+// e.g. for the key and the other fields.
+const maxJSONOverhead = 64 * 1024
+
+// jsonBodyLimit returns the size of the largest JSON request carrying values of maxValueSize bytes,
+// each character of the values may be escaped by six bytes. Zero maxValueSize means that there is no limit.
+func jsonBodyLimit(maxValueSize int) int64 {
+	if maxValueSize <= 0 {
+		return 0
+	}
+
+	return 6*int64(maxValueSize) + maxJSONOverhead
+}
+
+// readBody reads the request body up to limit bytes, a larger body is not read
+// entirely and it is rejected by ErrValueTooLarge. Zero limit means that there is no limit.
+func readBody(rw http.ResponseWriter, r *http.Request, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		return ioutil.ReadAll(r.Body)
+	}
+
+	r.Body = http.MaxBytesReader(rw, r.Body, limit)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil && int64(len(body)) >= limit {
+		return body, fmt.Errorf("%w: the request body must not be larger than %v bytes", ErrValueTooLarge, limit)
+	}
+
+	return body, err
+}
+
+// requestErrorStatus returns the status code of an error occurred while reading a request,
+// a body larger than its limit is too large and the others are bad requests.
+func requestErrorStatus(err error) int {
+	if errors.Is(err, ErrValueTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	return http.StatusBadRequest
+}
+
+// parseInt64Param parses the query parameter as an integer.
+// It returns the default value if the parameter is not given.
+func parseInt64Param(query url.Values, name string, defaultValue int64) (int64, error) {
+	param := query.Get(name)
+	if param == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v parameter must be an integer", name)
+	}
+
+	return value, nil
+}
+
+// parseFloat64Param parses the query parameter as a floating point number.
+// It returns the default value if the parameter is not given.
+func parseFloat64Param(query url.Values, name string, defaultValue float64) (float64, error) {
+	param := query.Get(name)
+	if param == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil || math.IsNaN(value) {
+		return 0, fmt.Errorf("%v parameter must be a number", name)
+	}
+
+	return value, nil
+}
+
 func (c Controller) badRequest(rw http.ResponseWriter, message string) {
 	resp := Response{
 		Error: message,
@@ -120,8 +318,45 @@ func (c Controller) methodNotAllowed(rw http.ResponseWriter) {
 // writeResponse converts the response object to
 // byte slice and writes it to response body.
 func (c Controller) writeResponse(rw http.ResponseWriter, statusCode int, resp Response) {
-	log.Printf("Sending response statusCode: %v, response: %+v", statusCode, resp)
+	writeJSON(rw, statusCode, resp)
+}
+
+// statusCodeOf maps an error returned by a service to the HTTP status code
+// of the response. A key holding another data type or an existing key
+// is a conflict, an invalid archive or schema is a bad request, a value exceeding
+// the maximum size is too large, a missing key is not found, a value violating its schema is unprocessable,
+// an exceeded key quota is too many requests, an exceeded byte quota
+// is insufficient storage, an access denied by the acl is forbidden, the other errors are considered as internal server errors.
+func statusCodeOf(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrWrongType), errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidSnapshot), errors.Is(err, ErrInvalidSchema):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrValueTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrSchemaViolation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrKeyQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrByteQuotaExceeded):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, ErrAccessDenied), errors.Is(err, ErrNamespaceForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrEncryptionUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
 
+// writeJSON converts any response object of the package to
+// byte slice and writes it to response body.
+func writeJSON(rw http.ResponseWriter, statusCode int, resp interface{}) {
 	respBytes, err := json.Marshal(resp)
 	if err != nil {
 		log.Printf("Error on marshalling to JSON: %v", err)
@@ -132,4 +367,4 @@ func (c Controller) writeResponse(rw http.ResponseWriter, statusCode int, resp R
 	if err != nil {
 		log.Printf("Error on writing response: %v", err)
 	}
-}
\ No newline at end of file
+}
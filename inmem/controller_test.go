@@ -10,6 +10,7 @@ import (
 type mockService struct {
 	GetMock func(string) (Response, error)
 	SetMock func(string, string) (Response, error)
+	StoreMock func(Dto) (Response, error)
 }
 
 func (m mockService) Get(key string) (Response, error) {
@@ -20,6 +21,10 @@ func (m mockService) Set(key string, value string) (Response, error) {
 	return m.SetMock(key, value)
 }
 
+func (m mockService) Store(dto Dto) (Response, error) {
+	return m.StoreMock(dto)
+}
+
 func TestController_ServeHTTPValidPost(t *testing.T) {
 	mock := mockService{
 		SetMock: func(s string, s2 string) (Response, error) {
@@ -114,4 +119,171 @@ func TestController_ServeHTTPIntegrationWithService(t *testing.T) {
 	if rr.Body.String() != expected {
 		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
 	}
-}
\ No newline at end of file
+}
+
+func TestController_ServeHTTPMissingKey(t *testing.T) {
+	mock := mockDao{
+		GetMock: func(s string) (Dto, error) {
+			return Dto{Key: "active-tabs"}, nil
+		},
+	}
+	controller := Controller{Repository: Service{Dao: mock}}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory?key=active-tabs", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusNotFound)
+	}
+
+	expected := "{\"key\":\"active-tabs\",\"value\":\"\",\"error\":\"key specified does not exist.\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestController_ServeHTTPBase64Post(t *testing.T) {
+	var got Dto
+	mock := mockService{
+		StoreMock: func(dto Dto) (Response, error) {
+			got = dto
+			return Response{
+				Key:         dto.Key,
+				Value:       "AAEC",
+				Encoding:    EncodingBase64,
+				ContentType: dto.ContentType,
+			}, nil
+		},
+	}
+
+	request := "{\"key\":\"blob\",\"value\":\"AAEC\",\"encoding\":\"base64\",\"contentType\":\"image/png\"}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := Controller{Repository: mock}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expectedDto := Dto{Key: "blob", Value: "\x00\x01\x02", ContentType: "image/png", Binary: true}
+	if got != expectedDto {
+		t.Errorf("stored incorrect value. got: %+v, expected: %+v", got, expectedDto)
+	}
+
+	expected := "{\"key\":\"blob\",\"value\":\"AAEC\",\"encoding\":\"base64\",\"contentType\":\"image/png\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestController_ServeHTTPInvalidEncoding(t *testing.T) {
+	tests := []struct {
+		request  string
+		expected string
+	}{
+		{"{\"key\":\"blob\",\"value\":\"!!\",\"encoding\":\"base64\"}", "{\"key\":\"\",\"value\":\"\",\"error\":\"value field is not valid base64\"}"},
+		{"{\"key\":\"blob\",\"value\":\"AAEC\",\"encoding\":\"hex\"}", "{\"key\":\"\",\"value\":\"\",\"error\":\"encoding must be base64\"}"},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(http.MethodPost, "/in-memory", strings.NewReader(test.request))
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		rr := httptest.NewRecorder()
+		controller := Controller{Repository: mockService{}}
+		controller.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+		}
+
+		if rr.Body.String() != test.expected {
+			t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), test.expected)
+		}
+	}
+}
+
+func TestController_ServeHTTPOctetStreamUpload(t *testing.T) {
+	var got Dto
+	mock := mockService{
+		StoreMock: func(dto Dto) (Response, error) {
+			got = dto
+			return Response{Key: dto.Key}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/in-memory?key=blob&contentType=image/png", strings.NewReader("\x89PNG"))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	rr := httptest.NewRecorder()
+	controller := Controller{Repository: mock}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expectedDto := Dto{Key: "blob", Value: "\x89PNG", ContentType: "image/png", Binary: true}
+	if got != expectedDto {
+		t.Errorf("stored incorrect value. got: %+v, expected: %+v", got, expectedDto)
+	}
+}
+
+func TestController_ServeHTTPOctetStreamUploadTooLarge(t *testing.T) {
+	mock := mockService{
+		StoreMock: func(dto Dto) (Response, error) {
+			t.Errorf("stored a value larger than the limit. got: %v bytes", len(dto.Value))
+			return Response{Key: dto.Key}, nil
+		},
+	}
+
+	body := strings.NewReader(strings.Repeat("a", 1024*1024))
+	req, err := http.NewRequest(http.MethodPost, "/in-memory?key=blob", body)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	rr := httptest.NewRecorder()
+	controller := Controller{Repository: mock, MaxValueSize: 4}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusRequestEntityTooLarge)
+	}
+
+	if body.Len() == 0 {
+		t.Errorf("read the request body entirely. got: %v bytes left, expected: some bytes left", body.Len())
+	}
+}
+
+func TestController_ServeHTTPJSONTooLarge(t *testing.T) {
+	request := "{\"key\":\"active-tabs\",\"value\":\"" + strings.Repeat("a", maxJSONOverhead+6*4) + "\"}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := Controller{Repository: mockService{}, MaxValueSize: 4}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusRequestEntityTooLarge)
+	}
+}
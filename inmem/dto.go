@@ -3,8 +3,120 @@ package inmem
 // Dto is used for representing in-memory database key-value pair
 // After get operation, if key does not exist, Exists field is set to false
 // Otherwise, Exists field will be set to true.
+// RawSize and StoredSize are the sizes of the value before and after it is compressed.
+// ContentType is empty if the value is stored without a content type.
+// Binary is true if the value is arbitrary bytes rather than a string,
+// such values are base64 encoded in JSON responses.
 type Dto struct {
 	Key string
 	Value string
+	ContentType string
+	Binary bool
 	Exists bool
+	RawSize int
+	StoredSize int
+}
+
+// HashDto is used for representing a Redis hash or a field of it.
+// Fields is only set when the whole hash is fetched.
+type HashDto struct {
+	Key    string
+	Field  string
+	Value  string
+	Fields map[string]string
+	Exists bool
+}
+
+// ListSide specifies the end of a list which an operation is performed on.
+type ListSide string
+
+const (
+	ListLeft  ListSide = "left"
+	ListRight ListSide = "right"
+)
+
+// ListDto is used for representing the elements of a Redis list.
+type ListDto struct {
+	Key    string
+	Values []string
+	Exists bool
+}
+
+// SetDto is used for representing the members of a Redis set.
+type SetDto struct {
+	Key     string
+	Members []string
+	Exists  bool
+}
+
+// LeaderboardEntry represents a member of a leaderboard with its score.
+// Rank starts from 1 which is the member having the highest score.
+type LeaderboardEntry struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+	Rank   int64   `json:"rank"`
+}
+
+// LeaderboardDto is used for representing a window of a leaderboard
+// stored as a Redis sorted set. Total is the number of the members in the leaderboard.
+type LeaderboardDto struct {
+	Key     string
+	Entries []LeaderboardEntry
+	Total   int64
+	Exists  bool
+}
+
+// SnapshotEntry represents a key with its value and time to live in a keyspace snapshot.
+// Only one of the value fields is set depending on Type of the key.
+// Value of a string is base64 encoded if Encoding is "base64", e.g. if it is not valid UTF-8.
+// TTLMillis is zero if the key does not expire.
+type SnapshotEntry struct {
+	Key       string            `json:"key"`
+	Type      string            `json:"type"`
+	TTLMillis int64             `json:"ttlMillis,omitempty"`
+	Value     string            `json:"value,omitempty"`
+	Encoding  string            `json:"encoding,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Elements  []string          `json:"elements,omitempty"`
+	Members   []ScoredMember    `json:"members,omitempty"`
+}
+
+// ScoredMember represents a member of a sorted set with its score.
+type ScoredMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// The types of the keys which can be stored in a keyspace snapshot.
+const (
+	TypeString    = "string"
+	TypeHash      = "hash"
+	TypeList      = "list"
+	TypeSet       = "set"
+	TypeSortedSet = "zset"
+)
+
+// ConflictPolicy specifies what happens when an imported key already exists.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing key and skips the imported one.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing key with the imported one.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictFail stops the import when an existing key is encountered.
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// NamespaceDto represents the keys and the usage of a namespace.
+// Namespace is empty for the default namespace.
+// KeyCount is the number of the keys counted or removed.
+// Quota is only set with the usage of the quota.
+type NamespaceDto struct {
+	Namespace string
+	Keys      []string
+	Cursor    uint64
+	KeyCount  int64
+	Bytes     int64
+	Quota     Quota
 }
@@ -0,0 +1,228 @@
+package inmem
+
+import (
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"strings"
+)
+
+// envelopeMagic prefixes the values which are stored with a header.
+// A header consists of the magic, a flags byte describing how the rest
+// of the value is encoded and the optional fields specified by the flags:
+//  - flagContentType: the length and the content type of the value,
+//  - flagEncrypted: the length and the id of the key encrypting the data key,
+//    followed by the encrypted data key.
+// The rest of the value is the payload.
+const envelopeMagic = "\x00GC"
+
+const (
+	// flagCompressed is set if the value is compressed with zstd.
+	flagCompressed byte = 1 << iota
+	// flagEncrypted is set if the value is encrypted with AES-GCM.
+	flagEncrypted
+	// flagContentType is set if the content type of the value is stored.
+	flagContentType
+	// flagBinary is set if the value is stored as arbitrary bytes rather than a string.
+	flagBinary
+	// flagBoundKey is set if the key of the value in the database is authenticated by the encryption
+	// along with the header, so that the value cannot be decrypted after it is copied to another key.
+	// It is not set for the values encrypted before the keys were bound.
+	flagBoundKey
+)
+
+// wrappedKeySize is the size of an encrypted data key with its nonce and tag.
+const wrappedKeySize = 12 + dataKeySize + 16
+
+// maxDecompressedSize guards against values which decompress to enormous sizes.
+const maxDecompressedSize = 64 * 1024 * 1024
+
+// zstd encoders and decoders are safe for concurrent use by EncodeAll and DecodeAll,
+// so a single instance of each is shared by all the values.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+)
+
+// valueMeta represents the metadata stored with a value.
+type valueMeta struct {
+	contentType string
+	binary      bool
+}
+
+// envelope represents the parsed header of a stored value.
+type envelope struct {
+	flags       byte
+	contentType string
+	keyId       string
+	wrappedKey  []byte
+	// header is the part of the value authenticated by the encryption.
+	header  string
+	payload string
+}
+
+// additionalData returns the data authenticated by the encryption of the value stored at the key.
+func (e envelope) additionalData(key string) []byte {
+	if e.flags&flagBoundKey == 0 {
+		return []byte(e.header)
+	}
+
+	return []byte(e.header + key)
+}
+
+// encodeValue prepares the value to be stored. The value is compressed
+// if it is at least threshold bytes long and compression makes it smaller.
+// A threshold of zero disables the compression.
+// If a keyring is given, the value is encrypted by the primary key of it after the compression,
+// the key of the value in the database is authenticated by the encryption.
+// The content type is stored in the header if it is not empty.
+func encodeValue(key string, value string, meta valueMeta, threshold int, keyring *Keyring) (string, error) {
+	contentType := meta.contentType
+	if len(contentType) > 255 {
+		return "", fmt.Errorf("content type must not be longer than 255 bytes")
+	}
+
+	flags := byte(0)
+	payload := []byte(value)
+	if threshold > 0 && len(value) >= threshold {
+		compressed := zstdEncoder.EncodeAll(payload, nil)
+		if len(compressed)+len(envelopeMagic)+1 < len(value) {
+			flags |= flagCompressed
+			payload = compressed
+		}
+	}
+
+	if contentType != "" {
+		flags |= flagContentType
+	}
+
+	if meta.binary {
+		flags |= flagBinary
+	}
+
+	if keyring != nil {
+		flags |= flagEncrypted | flagBoundKey
+	}
+
+	// a plain value which looks like an envelope is wrapped
+	// in an empty envelope not to be decoded wrongly.
+	if flags == 0 && !strings.HasPrefix(value, envelopeMagic) {
+		return value, nil
+	}
+
+	header := envelopeMagic + string(flags)
+	if contentType != "" {
+		header += string(byte(len(contentType))) + contentType
+	}
+
+	if keyring == nil {
+		return header + string(payload), nil
+	}
+
+	header += string(byte(len(keyring.Primary()))) + keyring.Primary()
+	env := envelope{flags: flags, header: header}
+	wrappedKey, ciphertext, err := keyring.encrypt(payload, env.additionalData(key))
+	if err != nil {
+		return "", fmt.Errorf("error on encrypting the value: %w", err)
+	}
+
+	return header + string(wrappedKey) + string(ciphertext), nil
+}
+
+// decodeValue restores the value and the metadata stored by encodeValue at the key.
+// The values stored without a header are returned as they are.
+// The keyring is required to decrypt the encrypted values.
+func decodeValue(key string, stored string, keyring *Keyring) (string, valueMeta, error) {
+	env, ok, err := parseEnvelope(stored)
+	if err != nil || !ok {
+		return stored, valueMeta{}, err
+	}
+
+	meta := valueMeta{contentType: env.contentType, binary: env.flags&flagBinary != 0}
+	payload := []byte(env.payload)
+	if env.flags&flagEncrypted != 0 {
+		if keyring == nil {
+			return "", meta, fmt.Errorf("value is encrypted but no keyring is configured")
+		}
+
+		payload, err = keyring.decrypt(env.keyId, env.wrappedKey, payload, env.additionalData(key))
+		if err != nil {
+			return "", meta, err
+		}
+	}
+
+	if env.flags&flagCompressed != 0 {
+		payload, err = zstdDecoder.DecodeAll(payload, nil)
+		if err != nil {
+			return "", meta, fmt.Errorf("error on decompressing the value: %w", err)
+		}
+	}
+
+	return string(payload), meta, nil
+}
+
+// encryptionKeyId returns the id of the key which encrypted the data key of the stored value.
+// It returns false if the value is not encrypted.
+func encryptionKeyId(stored string) (string, bool) {
+	env, ok, err := parseEnvelope(stored)
+	if err != nil || !ok || env.flags&flagEncrypted == 0 {
+		return "", false
+	}
+
+	return env.keyId, true
+}
+
+// boundToKey reports whether the stored value is encrypted along with its key in the database.
+func boundToKey(stored string) bool {
+	env, ok, err := parseEnvelope(stored)
+	return err == nil && ok && env.flags&flagBoundKey != 0
+}
+
+// parseEnvelope parses the header of the stored value.
+// It returns false if the value is stored without a header.
+func parseEnvelope(stored string) (envelope, bool, error) {
+	if !strings.HasPrefix(stored, envelopeMagic) || len(stored) <= len(envelopeMagic) {
+		return envelope{}, false, nil
+	}
+
+	env := envelope{flags: stored[len(envelopeMagic)]}
+	if env.flags&^(flagCompressed|flagEncrypted|flagContentType|flagBinary|flagBoundKey) != 0 {
+		return env, true, fmt.Errorf("unsupported value header flags: %08b", env.flags)
+	}
+
+	offset := len(envelopeMagic) + 1
+	// readField reads a field prefixed by its length.
+	readField := func() (string, bool) {
+		if len(stored) <= offset {
+			return "", false
+		}
+
+		end := offset + 1 + int(stored[offset])
+		if len(stored) < end {
+			return "", false
+		}
+
+		field := stored[offset+1 : end]
+		offset = end
+		return field, true
+	}
+
+	var ok bool
+	if env.flags&flagContentType != 0 {
+		if env.contentType, ok = readField(); !ok {
+			return env, true, fmt.Errorf("value header is truncated")
+		}
+	}
+
+	if env.flags&flagEncrypted != 0 {
+		if env.keyId, ok = readField(); !ok || len(stored) < offset+wrappedKeySize {
+			return env, true, fmt.Errorf("value header is truncated")
+		}
+
+		env.header = stored[:offset]
+		env.wrappedKey = []byte(stored[offset : offset+wrappedKeySize])
+		offset += wrappedKeySize
+	}
+
+	env.payload = stored[offset:]
+	return env, true, nil
+}
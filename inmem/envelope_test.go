@@ -0,0 +1,169 @@
+package inmem
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodeValue_CompressesAboveThreshold(t *testing.T) {
+	value := strings.Repeat("{\"active-tabs\":\"getir\"}", 100)
+
+	stored, _ := encodeValue("ns::default:key", value, valueMeta{}, 1024, nil)
+	if len(stored) >= len(value) {
+		t.Errorf("value is not compressed. stored size: %v, raw size: %v", len(stored), len(value))
+	}
+
+	got, _, err := decodeValue("ns::default:key", stored, nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if got != value {
+		t.Errorf("returned incorrect value. got: %v, expected: %v", got, value)
+	}
+}
+
+func TestEncodeValue_KeepsSmallValuesPlain(t *testing.T) {
+	stored, _ := encodeValue("ns::default:key", "getir", valueMeta{}, 1024, nil)
+	if stored != "getir" {
+		t.Errorf("returned incorrect stored value. got: %v, expected: %v", stored, "getir")
+	}
+}
+
+func TestEncodeValue_WrapsValuesLookingLikeEnvelope(t *testing.T) {
+	value := envelopeMagic + "\x01getir"
+
+	stored, _ := encodeValue("ns::default:key", value, valueMeta{}, 0, nil)
+	got, _, err := decodeValue("ns::default:key", stored, nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if got != value {
+		t.Errorf("returned incorrect value. got: %q, expected: %q", got, value)
+	}
+}
+
+func TestEncodeValue_EncryptsByPrimaryKey(t *testing.T) {
+	oldKeyring, err := NewKeyring("old", map[string][]byte{"old": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	value := strings.Repeat("getir", 1000)
+	stored, err := encodeValue("ns::default:key", value, valueMeta{}, 1024, oldKeyring)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if strings.Contains(stored, "getir") {
+		t.Errorf("value is stored as plaintext")
+	}
+
+	// after a rotation, the values encrypted by the old key are still readable.
+	rotatedKeyring, err := NewKeyring("new", map[string][]byte{
+		"old": make([]byte, 32),
+		"new": []byte("0123456789abcdef0123456789abcdef"),
+	})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if keyId, _ := encryptionKeyId(stored); keyId != "old" {
+		t.Errorf("returned incorrect key id. got: %v, expected: %v", keyId, "old")
+	}
+
+	got, _, err := decodeValue("ns::default:key", stored, rotatedKeyring)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if got != value {
+		t.Errorf("returned incorrect value. got: %v, expected: %v", got, value)
+	}
+}
+
+func TestDecodeValue_RejectsTamperedValue(t *testing.T) {
+	keyring, err := NewKeyring("primary", map[string][]byte{"primary": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	stored, err := encodeValue("ns::default:key", "getir", valueMeta{}, 0, keyring)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tampered := []byte(stored)
+	tampered[len(tampered)-1] ^= 1
+	if _, _, err = decodeValue("ns::default:key", string(tampered), keyring); err == nil {
+		t.Errorf("tampered value is decrypted")
+	}
+}
+
+func TestDecodeValue_RejectsValueOfAnotherKey(t *testing.T) {
+	keyring, err := NewKeyring("primary", map[string][]byte{"primary": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	stored, err := encodeValue("ns:team-a:secret", "getir", valueMeta{}, 0, keyring)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if _, _, err = decodeValue("ns:team-b:secret", stored, keyring); err == nil {
+		t.Errorf("value copied to another key is decrypted")
+	}
+}
+
+func TestDecodeValue_DecryptsValueNotBoundToKey(t *testing.T) {
+	keyring, err := NewKeyring("primary", map[string][]byte{"primary": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	// a value encrypted before the keys were bound authenticates the header only.
+	header := envelopeMagic + string(flagEncrypted) + string(byte(len("primary"))) + "primary"
+	wrappedKey, ciphertext, err := keyring.encrypt([]byte("getir"), []byte(header))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	stored := header + string(wrappedKey) + string(ciphertext)
+
+	got, _, err := decodeValue("ns::default:key", stored, keyring)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if got != "getir" {
+		t.Errorf("returned incorrect value. got: %v, expected: %v", got, "getir")
+	}
+
+	if boundToKey(stored) {
+		t.Errorf("returned incorrect bound state. got: %v, expected: %v", true, false)
+	}
+}
+
+func TestEncodeValue_StoresMetadata(t *testing.T) {
+	keyring, err := NewKeyring("primary", map[string][]byte{"primary": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	for _, k := range []*Keyring{nil, keyring} {
+		stored, err := encodeValue("ns::default:key", "\x89PNG", valueMeta{contentType: "image/png", binary: true}, 0, k)
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		value, meta, err := decodeValue("ns::default:key", stored, k)
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if value != "\x89PNG" || meta.contentType != "image/png" || !meta.binary {
+			t.Errorf("returned incorrect value. got: %q %+v, expected: %q (%v)", value, meta, "\x89PNG", "image/png")
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package inmem
+
+import (
+	"log"
+	"net/http"
+)
+
+// HashRepository interface is used by a HashController
+// to operate on the hashes stored in an in-memory database.
+type HashRepository interface {
+	Get(key string, field string) (HashResponse, error)
+	GetAll(key string) (HashResponse, error)
+	Set(key string, field string, value string) (HashResponse, error)
+	Delete(key string, field string) (HashResponse, error)
+}
+
+// HashController is a handler for handling
+// requests coming to "/in-memory/hashes" endpoint.
+// The request bodies are read up to the size of the values of MaxValueSize bytes,
+// zero MaxValueSize means that there is no limit.
+// The accesses to the hashes are authorized by Acl if it is set.
+type HashController struct {
+	Repository   HashRepository
+	Acl          *Acl
+	MaxValueSize int
+}
+
+// ServeHTTP handles incoming requests to "/in-memory/hashes" endpoint.
+// POST requests set a field of a hash.
+// GET requests fetch a field of a hash if field parameter is given,
+// otherwise all the fields of the hash.
+// DELETE requests remove a field from a hash.
+func (c HashController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		var payload HashRequest
+		err := parseJSON(rw, req, &payload, jsonBodyLimit(c.MaxValueSize))
+		if err != nil {
+			log.Printf("Error on parsing request JSON: %v", err)
+			writeJSON(rw, requestErrorStatus(err), HashResponse{Error: err.Error()})
+			return
+		}
+
+		if !c.validateRequest(rw, payload) || !c.authorize(rw, req, ActionWrite, *payload.Key) {
+			return
+		}
+
+		resp, err := c.Repository.Set(*payload.Key, *payload.Field, *payload.Value)
+		if err != nil {
+			log.Printf("Error while setting the hash field: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodGet:
+		query := req.URL.Query()
+		key := query.Get("key")
+		if key == "" {
+			c.badRequest(rw, "key parameter is missing")
+			return
+		}
+
+		if !c.authorize(rw, req, ActionRead, key) {
+			return
+		}
+
+		var resp HashResponse
+		var err error
+		if _, ok := query["field"]; ok {
+			resp, err = c.Repository.Get(key, query.Get("field"))
+		} else {
+			resp, err = c.Repository.GetAll(key)
+		}
+		if err != nil {
+			log.Printf("Error while getting the hash: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodDelete:
+		query := req.URL.Query()
+		key, field := query.Get("key"), query.Get("field")
+		if key == "" {
+			c.badRequest(rw, "key parameter is missing")
+			return
+		}
+
+		if field == "" {
+			c.badRequest(rw, "field parameter is missing")
+			return
+		}
+
+		if !c.authorize(rw, req, ActionWrite, key) {
+			return
+		}
+
+		resp, err := c.Repository.Delete(key, field)
+		if err != nil {
+			log.Printf("Error while deleting the hash field: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	default:
+		c.methodNotAllowed(rw)
+	}
+}
+
+// validateRequest checks whether there are missing fields or not.
+func (c HashController) validateRequest(rw http.ResponseWriter, payload HashRequest) bool {
+	if payload.Key == nil {
+		c.badRequest(rw, "key field is missing")
+		return false
+	}
+
+	if payload.Field == nil {
+		c.badRequest(rw, "field field is missing")
+		return false
+	}
+
+	if payload.Value == nil {
+		c.badRequest(rw, "value field is missing")
+		return false
+	}
+
+	return true
+}
+
+// authorize checks the access of the client of the request to the hash.
+func (c HashController) authorize(rw http.ResponseWriter, req *http.Request, action, key string) bool {
+	if err := checkAccess(c.Acl, req, action, key); err != nil {
+		writeJSON(rw, statusCodeOf(err), HashResponse{Key: key, Error: err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func (c HashController) badRequest(rw http.ResponseWriter, message string) {
+	writeJSON(rw, http.StatusBadRequest, HashResponse{Error: message})
+}
+
+func (c HashController) methodNotAllowed(rw http.ResponseWriter) {
+	writeJSON(rw, http.StatusMethodNotAllowed, HashResponse{Error: "the method is not allowed for this endpoint."})
+}
@@ -0,0 +1,136 @@
+package inmem
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockHashDao struct {
+	HashGetMock    func(string, string) (HashDto, error)
+	HashGetAllMock func(string) (HashDto, error)
+	HashSetMock    func(HashDto) error
+	HashDeleteMock func(string, string) (bool, error)
+}
+
+func (m mockHashDao) HashGet(key string, field string) (HashDto, error) {
+	return m.HashGetMock(key, field)
+}
+
+func (m mockHashDao) HashGetAll(key string) (HashDto, error) {
+	return m.HashGetAllMock(key)
+}
+
+func (m mockHashDao) HashSet(dto HashDto) error {
+	return m.HashSetMock(dto)
+}
+
+func (m mockHashDao) HashDelete(key string, field string) (bool, error) {
+	return m.HashDeleteMock(key, field)
+}
+
+func TestHashController_ServeHTTPValidPost(t *testing.T) {
+	mock := mockHashDao{
+		HashSetMock: func(dto HashDto) error {
+			return nil
+		},
+	}
+
+	request := "{\"key\":\"user:1\",\"field\":\"name\",\"value\":\"getir\"}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory/hashes", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := HashController{Repository: HashService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expected := "{\"key\":\"user:1\",\"field\":\"name\",\"value\":\"getir\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestHashController_ServeHTTPMissingField(t *testing.T) {
+	request := "{\"key\":\"user:1\",\"value\":\"getir\"}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory/hashes", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := HashController{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+
+	expected := "{\"key\":\"\",\"error\":\"field field is missing\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestHashController_ServeHTTPGetAll(t *testing.T) {
+	mock := mockHashDao{
+		HashGetAllMock: func(s string) (HashDto, error) {
+			return HashDto{
+				Key:    "user:1",
+				Fields: map[string]string{"name": "getir"},
+				Exists: true,
+			}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/hashes?key=user:1", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := HashController{Repository: HashService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expected := "{\"key\":\"user:1\",\"fields\":{\"name\":\"getir\"}}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestHashController_ServeHTTPWrongType(t *testing.T) {
+	mock := mockHashDao{
+		HashGetMock: func(s string, s2 string) (HashDto, error) {
+			return HashDto{}, fmt.Errorf("%w: mock error", ErrWrongType)
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/hashes?key=active-tabs&field=name", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := HashController{Repository: HashService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusConflict)
+	}
+
+	expected := "{\"key\":\"active-tabs\",\"field\":\"name\",\"error\":\"key specified holds a value of another data type.\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
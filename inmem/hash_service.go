@@ -0,0 +1,72 @@
+package inmem
+
+// HashDao interface is used by a HashService to access the hashes
+// stored in the in-memory database.
+type HashDao interface {
+	HashGet(key string, field string) (HashDto, error)
+	HashGetAll(key string) (HashDto, error)
+	HashSet(dto HashDto) error
+	HashDelete(key string, field string) (bool, error)
+}
+
+// HashService uses HashDao to operate on hashes and
+// creates responses according to the possible errors.
+type HashService struct {
+	Dao HashDao
+}
+
+// Get fetches the value of a field in the hash.
+func (s HashService) Get(key string, field string) (HashResponse, error) {
+	dto, err := s.Dao.HashGet(key, field)
+	if err != nil {
+		return HashResponse{Key: key, Field: field, Error: errorMessage(err)}, err
+	}
+
+	if !dto.Exists {
+		return HashResponse{Key: key, Field: field, Error: "field specified does not exist."}, nil
+	}
+
+	return HashResponse{Key: key, Field: field, Value: dto.Value}, nil
+}
+
+// GetAll fetches all the fields of the hash.
+func (s HashService) GetAll(key string) (HashResponse, error) {
+	dto, err := s.Dao.HashGetAll(key)
+	if err != nil {
+		return HashResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	if !dto.Exists {
+		return HashResponse{Key: key, Error: "key specified does not exist."}, nil
+	}
+
+	return HashResponse{Key: key, Fields: dto.Fields}, nil
+}
+
+// Set sets the value of a field in the hash.
+func (s HashService) Set(key string, field string, value string) (HashResponse, error) {
+	err := s.Dao.HashSet(HashDto{
+		Key:   key,
+		Field: field,
+		Value: value,
+	})
+	if err != nil {
+		return HashResponse{Key: key, Field: field, Error: errorMessage(err)}, err
+	}
+
+	return HashResponse{Key: key, Field: field, Value: value}, nil
+}
+
+// Delete removes a field from the hash.
+func (s HashService) Delete(key string, field string) (HashResponse, error) {
+	removed, err := s.Dao.HashDelete(key, field)
+	if err != nil {
+		return HashResponse{Key: key, Field: field, Error: errorMessage(err)}, err
+	}
+
+	if !removed {
+		return HashResponse{Key: key, Field: field, Error: "field specified does not exist."}, nil
+	}
+
+	return HashResponse{Key: key, Field: field}, nil
+}
@@ -0,0 +1,141 @@
+package inmem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// keyResourcePrefix is the path which the keys are served under.
+	keyResourcePrefix = "/in-memory/"
+	// defaultContentType is served for the values stored without a content type,
+	// e.g. the plain string values set by "/in-memory" endpoint.
+	defaultContentType = "text/plain; charset=utf-8"
+	// maxContentTypeLength is the longest content type which can be stored with a value.
+	maxContentTypeLength = 255
+)
+
+// KeyRepository interface is used by a KeyController
+// to access the keys as resources in an in-memory database.
+type KeyRepository interface {
+	Get(key string) (Response, error)
+	Put(key string, value string, contentType string) (Response, error)
+	Delete(key string) (Response, error)
+}
+
+// KeyController is a handler for handling
+// requests coming to "/in-memory/{key}" resources.
+// The accesses to the keys are authorized by Acl if it is set.
+// The request bodies larger than MaxValueSize bytes are rejected before they are read entirely,
+// zero MaxValueSize means that there is no limit.
+type KeyController struct {
+	Repository   KeyRepository
+	Acl          *Acl
+	MaxValueSize int
+}
+
+// ServeHTTP handles incoming requests to "/in-memory/{key}" resources.
+// The key is the rest of the escaped path unescaped once, it should be percent-encoded
+// if it contains special characters.
+// GET and HEAD requests serve the raw value with the content type it was stored with,
+// conditional requests are supported by an ETag calculated from the value.
+// PUT requests store the raw request body with the Content-Type header of the request.
+// DELETE requests remove the key.
+func (c KeyController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	key, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), keyResourcePrefix))
+	if err != nil {
+		c.writeError(rw, http.StatusBadRequest, Response{Error: "key is not percent-encoded correctly"})
+		return
+	}
+
+	if key == "" {
+		c.writeError(rw, http.StatusBadRequest, Response{Error: "key is missing in the path"})
+		return
+	}
+
+	if !authorize(c.Acl, rw, req, actionOf(req), key) {
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		resp, err := c.Repository.Get(key)
+		if err != nil {
+			log.Printf("Error while getting the value: %v", err)
+			c.writeError(rw, statusCodeOf(err), resp)
+			return
+		}
+
+		contentType := resp.ContentType
+		if contentType == "" {
+			contentType = defaultContentType
+		}
+
+		// ServeContent handles HEAD, range and conditional requests.
+		rw.Header().Set("Content-Type", contentType)
+		rw.Header().Set("ETag", etag(resp.Value))
+		http.ServeContent(rw, req, "", time.Time{}, strings.NewReader(resp.Value))
+	case http.MethodPut:
+		contentType := req.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		if len(contentType) > maxContentTypeLength {
+			c.writeError(rw, http.StatusBadRequest, Response{Key: key, Error: "Content-Type header is too long"})
+			return
+		}
+
+		body, err := readBody(rw, req, int64(c.MaxValueSize))
+		if errors.Is(err, ErrValueTooLarge) {
+			c.writeError(rw, http.StatusRequestEntityTooLarge, Response{Key: key, Error: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Printf("Error on reading the request body: %v", err)
+			c.writeError(rw, http.StatusBadRequest, Response{Key: key, Error: "bad request"})
+			return
+		}
+
+		resp, err := c.Repository.Put(key, string(body), contentType)
+		if err != nil {
+			log.Printf("Error while setting the value: %v", err)
+			c.writeError(rw, statusCodeOf(err), resp)
+			return
+		}
+
+		rw.Header().Set("ETag", etag(string(body)))
+		rw.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		resp, err := c.Repository.Delete(key)
+		if err != nil {
+			log.Printf("Error while deleting the key: %v", err)
+			c.writeError(rw, statusCodeOf(err), resp)
+			return
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	default:
+		rw.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		c.writeError(rw, http.StatusMethodNotAllowed, Response{Error: "the method is not allowed for this endpoint."})
+	}
+}
+
+// writeError writes the error response as JSON since the values are not served on errors.
+func (c KeyController) writeError(rw http.ResponseWriter, statusCode int, resp Response) {
+	rw.Header().Set("Content-Type", "application/json")
+	writeJSON(rw, statusCode, resp)
+}
+
+// etag returns a strong entity tag of the value.
+func etag(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "\"" + hex.EncodeToString(sum[:16]) + "\""
+}
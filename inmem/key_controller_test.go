@@ -0,0 +1,197 @@
+package inmem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestKeyController_ServeHTTPGetWithContentType(t *testing.T) {
+	mock := mockDao{
+		GetMock: func(key string) (Dto, error) {
+			return Dto{Key: key, Value: "{\"tabs\":3}", ContentType: "application/json", Exists: true}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/config%2Fteam-a", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := KeyController{Repository: Service{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	if contentType := rr.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("returned incorrect content type. got: %v, expected: %v", contentType, "application/json")
+	}
+
+	expected := "{\"tabs\":3}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestKeyController_ServeHTTPKeyOfPath(t *testing.T) {
+	tests := []struct {
+		path        string
+		expectedKey string
+	}{
+		{"/in-memory/config/team-a", "config/team-a"},
+		{"/in-memory/config%2Fteam-a", "config/team-a"},
+		{"/in-memory/config%2F%2Fteam-a", "config//team-a"},
+		{"/in-memory/a//b", "a//b"},
+		{"/in-memory/a/./b", "a/./b"},
+		{"/in-memory/a/../b", "a/../b"},
+		{"/in-memory/..%2Fb", "../b"},
+		{"/in-memory/active%20tabs", "active tabs"},
+		{"/in-memory/100%2525", "100%25"},
+	}
+
+	for _, test := range tests {
+		var got string
+		mock := mockDao{
+			GetMock: func(key string) (Dto, error) {
+				got = key
+				return Dto{Key: key, Value: "getir", Exists: true}, nil
+			},
+		}
+
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		rr := httptest.NewRecorder()
+		controller := KeyController{Repository: Service{Dao: mock}}
+		controller.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.path, status, http.StatusOK)
+		}
+
+		if got != test.expectedKey {
+			t.Errorf("returned incorrect key for %v. got: %v, expected: %v", test.path, got, test.expectedKey)
+		}
+	}
+}
+
+func TestKeyController_ServeHTTPGetNotModified(t *testing.T) {
+	mock := mockDao{
+		GetMock: func(key string) (Dto, error) {
+			return Dto{Key: key, Value: "getir", Exists: true}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/active-tabs", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	req.Header.Set("If-None-Match", etag("getir"))
+
+	rr := httptest.NewRecorder()
+	controller := KeyController{Repository: Service{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotModified {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusNotModified)
+	}
+}
+
+func TestKeyController_ServeHTTPGetMissingKey(t *testing.T) {
+	mock := mockDao{
+		GetMock: func(key string) (Dto, error) {
+			return Dto{Key: key}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodHead, "/in-memory/active-tabs", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := KeyController{Repository: Service{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusNotFound)
+	}
+}
+
+func TestKeyController_ServeHTTPPutRawBody(t *testing.T) {
+	var got Dto
+	mock := mockDao{
+		SetMock: func(dto Dto) (Dto, error) {
+			got = dto
+			return dto, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodPut, "/in-memory/logo", strings.NewReader("\x89PNG"))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	req.Header.Set("Content-Type", "image/png")
+
+	rr := httptest.NewRecorder()
+	controller := KeyController{Repository: Service{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusNoContent)
+	}
+
+	if got.Key != "logo" || got.Value != "\x89PNG" || got.ContentType != "image/png" {
+		t.Errorf("stored incorrect value. got: %+v", got)
+	}
+}
+
+func TestKeyController_ServeHTTPPutTooLarge(t *testing.T) {
+	mock := mockDao{
+		SetMock: func(dto Dto) (Dto, error) {
+			t.Errorf("stored a value larger than the limit. got: %v bytes", len(dto.Value))
+			return dto, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodPut, "/in-memory/logo", strings.NewReader("\x89PNG"))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := KeyController{Repository: Service{Dao: mock}, MaxValueSize: 3}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestKeyController_ServeHTTPDeleteMissingKey(t *testing.T) {
+	mock := mockDao{
+		DeleteMock: func(key string) (bool, error) {
+			return false, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, "/in-memory/active-tabs", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := KeyController{Repository: Service{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusNotFound)
+	}
+
+	expected := "{\"key\":\"active-tabs\",\"value\":\"\",\"error\":\"key specified does not exist.\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
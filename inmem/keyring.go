@@ -0,0 +1,167 @@
+package inmem
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// dataKeySize is the size of the AES-256 keys used to encrypt the values.
+const dataKeySize = 32
+
+// Keyring holds the key encryption keys loaded from a keyfile.
+// Each value is encrypted by a random data key, and the data key is encrypted
+// by the primary key of the keyring and stored alongside the value with the id of the primary key.
+// The other keys are kept to decrypt the values encrypted before a key rotation.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// keyfile represents the JSON keyfile format. The keys are base64 encoded 32 bytes.
+type keyfile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// LoadKeyring reads the keys from the JSON keyfile at path, for example:
+//   {"primary": "2022-02", "keys": {"2022-01": "base64 key", "2022-02": "base64 key"}}
+func LoadKeyring(path string) (*Keyring, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file keyfile
+	err = json.Unmarshal(content, &file)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing the keyfile: %w", err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		keys[id], err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("error on decoding the key %v: %w", id, err)
+		}
+	}
+
+	return NewKeyring(file.Primary, keys)
+}
+
+// NewKeyring creates a keyring from 32 bytes long keys by their ids.
+// The primary key is used to encrypt the new values.
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q does not exist in the keys", primary)
+	}
+
+	keyring := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > 255 {
+			return nil, fmt.Errorf("key id %q must be between 1 and 255 bytes long", id)
+		}
+
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("key %v must be %v bytes long", id, dataKeySize)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[id] = aead
+	}
+
+	return keyring, nil
+}
+
+// Primary returns the id of the key encrypting the new values.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// encrypt encrypts the plaintext by a random data key and encrypts the data key by the primary key.
+// additionalData, the header of the value and its key, is authenticated with both so that they cannot be tampered with.
+// It returns the encrypted data key and the ciphertext, each prefixed with its nonce.
+func (k *Keyring) encrypt(plaintext []byte, additionalData []byte) ([]byte, []byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrappedKey, err := seal(k.keys[k.primary], dataKey, additionalData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, err := seal(dataAEAD, plaintext, additionalData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wrappedKey, ciphertext, nil
+}
+
+// decrypt decrypts the data key by the key with the id, then decrypts the ciphertext by the data key.
+func (k *Keyring) decrypt(keyId string, wrappedKey []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	aead, ok := k.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("encryption key %q does not exist in the keyring", keyId)
+	}
+
+	dataKey, err := open(aead, wrappedKey, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("error on decrypting the data key: %w", err)
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(dataAEAD, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("error on decrypting the value: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext with a random nonce and prefixes the ciphertext with the nonce.
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts the ciphertext sealed by seal.
+func open(aead cipher.AEAD, sealed []byte, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext is too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -0,0 +1,168 @@
+package inmem
+
+import (
+	"log"
+	"math"
+	"net/http"
+)
+
+const (
+	// defaultLeaderboardLimit is the page size used if limit parameter is not given.
+	defaultLeaderboardLimit = 10
+	// maxLeaderboardLimit is the maximum number of entries which can be fetched at once.
+	maxLeaderboardLimit = 100
+)
+
+// LeaderboardRepository interface is used by a LeaderboardController
+// to operate on the leaderboards stored in an in-memory database.
+type LeaderboardRepository interface {
+	Add(key string, member string, score float64, increment bool) (LeaderboardResponse, error)
+	Member(key string, member string) (LeaderboardResponse, error)
+	Top(key string, offset int64, limit int64) (LeaderboardResponse, error)
+	Around(key string, member string, window int64) (LeaderboardResponse, error)
+	ByScore(key string, min float64, max float64, offset int64, limit int64) (LeaderboardResponse, error)
+}
+
+// LeaderboardController is a handler for handling
+// requests coming to "/in-memory/leaderboards" endpoint.
+// The request bodies are read up to the size of the values of MaxValueSize bytes,
+// zero MaxValueSize means that there is no limit.
+// The accesses to the leaderboards are authorized by Acl if it is set.
+type LeaderboardController struct {
+	Repository   LeaderboardRepository
+	Acl          *Acl
+	MaxValueSize int
+}
+
+// ServeHTTP handles incoming requests to "/in-memory/leaderboards" endpoint.
+// POST requests set or increment the score of a member.
+// GET requests fetch a window of a leaderboard depending on the query parameters:
+//  - member and around: the members ranked around the member,
+//  - member: the rank and the score of the member,
+//  - min and/or max: the members whose scores are in the range,
+//  - otherwise: the members having the highest scores.
+// The windows except the one around a member are paginated by offset and limit parameters.
+func (c LeaderboardController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		var payload LeaderboardRequest
+		err := parseJSON(rw, req, &payload, jsonBodyLimit(c.MaxValueSize))
+		if err != nil {
+			log.Printf("Error on parsing request JSON: %v", err)
+			writeJSON(rw, requestErrorStatus(err), LeaderboardResponse{Error: err.Error()})
+			return
+		}
+
+		if !c.validateRequest(rw, payload) || !c.authorize(rw, req, ActionWrite, *payload.Key) {
+			return
+		}
+
+		resp, err := c.Repository.Add(*payload.Key, *payload.Member, *payload.Score, payload.Increment)
+		if err != nil {
+			log.Printf("Error while setting the score: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodGet:
+		c.serveGet(rw, req)
+	default:
+		c.methodNotAllowed(rw)
+	}
+}
+
+// serveGet fetches the window of the leaderboard specified by the query parameters.
+func (c LeaderboardController) serveGet(rw http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	key, member := query.Get("key"), query.Get("member")
+	if key == "" {
+		c.badRequest(rw, "key parameter is missing")
+		return
+	}
+
+	offset, err := parseInt64Param(query, "offset", 0)
+	if err != nil || offset < 0 {
+		c.badRequest(rw, "offset parameter must be a non-negative integer")
+		return
+	}
+
+	limit, err := parseInt64Param(query, "limit", defaultLeaderboardLimit)
+	if err != nil || limit < 1 || limit > maxLeaderboardLimit {
+		c.badRequest(rw, "limit parameter must be an integer between 1 and 100")
+		return
+	}
+
+	if !c.authorize(rw, req, ActionRead, key) {
+		return
+	}
+
+	var resp LeaderboardResponse
+	switch {
+	case member != "" && query.Get("around") != "":
+		window, parseErr := parseInt64Param(query, "around", 0)
+		if parseErr != nil || window < 0 || window > maxLeaderboardLimit {
+			c.badRequest(rw, "around parameter must be an integer between 0 and 100")
+			return
+		}
+
+		resp, err = c.Repository.Around(key, member, window)
+	case member != "":
+		resp, err = c.Repository.Member(key, member)
+	case query.Get("min") != "" || query.Get("max") != "":
+		min, parseErr := parseFloat64Param(query, "min", math.Inf(-1))
+		if parseErr != nil {
+			c.badRequest(rw, parseErr.Error())
+			return
+		}
+
+		max, parseErr := parseFloat64Param(query, "max", math.Inf(1))
+		if parseErr != nil {
+			c.badRequest(rw, parseErr.Error())
+			return
+		}
+
+		resp, err = c.Repository.ByScore(key, min, max, offset, limit)
+	default:
+		resp, err = c.Repository.Top(key, offset, limit)
+	}
+	if err != nil {
+		log.Printf("Error while fetching the leaderboard: %v", err)
+	}
+	writeJSON(rw, statusCodeOf(err), resp)
+}
+
+// validateRequest checks whether there are missing fields or not.
+func (c LeaderboardController) validateRequest(rw http.ResponseWriter, payload LeaderboardRequest) bool {
+	if payload.Key == nil {
+		c.badRequest(rw, "key field is missing")
+		return false
+	}
+
+	if payload.Member == nil {
+		c.badRequest(rw, "member field is missing")
+		return false
+	}
+
+	if payload.Score == nil {
+		c.badRequest(rw, "score field is missing")
+		return false
+	}
+
+	return true
+}
+
+// authorize checks the access of the client of the request to the leaderboard.
+func (c LeaderboardController) authorize(rw http.ResponseWriter, req *http.Request, action, key string) bool {
+	if err := checkAccess(c.Acl, req, action, key); err != nil {
+		writeJSON(rw, statusCodeOf(err), LeaderboardResponse{Key: key, Error: err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func (c LeaderboardController) badRequest(rw http.ResponseWriter, message string) {
+	writeJSON(rw, http.StatusBadRequest, LeaderboardResponse{Error: message})
+}
+
+func (c LeaderboardController) methodNotAllowed(rw http.ResponseWriter) {
+	writeJSON(rw, http.StatusMethodNotAllowed, LeaderboardResponse{Error: "the method is not allowed for this endpoint."})
+}
@@ -0,0 +1,140 @@
+package inmem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockLeaderboardDao struct {
+	LeaderboardAddMock          func(string, string, float64, bool) (LeaderboardEntry, error)
+	LeaderboardMemberMock       func(string, string) (LeaderboardDto, error)
+	LeaderboardRangeMock        func(string, int64, int64) (LeaderboardDto, error)
+	LeaderboardRangeByScoreMock func(string, float64, float64, int64, int64) (LeaderboardDto, error)
+}
+
+func (m mockLeaderboardDao) LeaderboardAdd(key string, member string, score float64, increment bool) (LeaderboardEntry, error) {
+	return m.LeaderboardAddMock(key, member, score, increment)
+}
+
+func (m mockLeaderboardDao) LeaderboardMember(key string, member string) (LeaderboardDto, error) {
+	return m.LeaderboardMemberMock(key, member)
+}
+
+func (m mockLeaderboardDao) LeaderboardRange(key string, start int64, stop int64) (LeaderboardDto, error) {
+	return m.LeaderboardRangeMock(key, start, stop)
+}
+
+func (m mockLeaderboardDao) LeaderboardRangeByScore(key string, min float64, max float64, offset int64, count int64) (LeaderboardDto, error) {
+	return m.LeaderboardRangeByScoreMock(key, min, max, offset, count)
+}
+
+func TestLeaderboardController_ServeHTTPValidIncrement(t *testing.T) {
+	mock := mockLeaderboardDao{
+		LeaderboardAddMock: func(key string, member string, score float64, increment bool) (LeaderboardEntry, error) {
+			if !increment {
+				t.Errorf("score is not incremented")
+			}
+			return LeaderboardEntry{Member: member, Score: 15, Rank: 2}, nil
+		},
+	}
+
+	request := "{\"key\":\"weekly\",\"member\":\"alice\",\"score\":5,\"increment\":true}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory/leaderboards", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := LeaderboardController{Repository: LeaderboardService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expected := "{\"key\":\"weekly\",\"entries\":[{\"member\":\"alice\",\"score\":15,\"rank\":2}]}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestLeaderboardController_ServeHTTPAroundMember(t *testing.T) {
+	var gotStart, gotStop int64
+	mock := mockLeaderboardDao{
+		LeaderboardMemberMock: func(key string, member string) (LeaderboardDto, error) {
+			return LeaderboardDto{
+				Key:     key,
+				Entries: []LeaderboardEntry{{Member: member, Score: 10, Rank: 2}},
+				Total:   5,
+				Exists:  true,
+			}, nil
+		},
+		LeaderboardRangeMock: func(key string, start int64, stop int64) (LeaderboardDto, error) {
+			gotStart, gotStop = start, stop
+			return LeaderboardDto{Key: key, Total: 5, Exists: true}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/leaderboards?key=weekly&member=alice&around=3", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := LeaderboardController{Repository: LeaderboardService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	// the member is ranked second, so the window is clamped at the top of the leaderboard.
+	if gotStart != 0 || gotStop != 4 {
+		t.Errorf("fetched incorrect range. got: %v-%v, expected: %v-%v", gotStart, gotStop, 0, 4)
+	}
+}
+
+func TestLeaderboardController_ServeHTTPTopPagination(t *testing.T) {
+	var gotStart, gotStop int64
+	mock := mockLeaderboardDao{
+		LeaderboardRangeMock: func(key string, start int64, stop int64) (LeaderboardDto, error) {
+			gotStart, gotStop = start, stop
+			return LeaderboardDto{Key: key}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/leaderboards?key=weekly&offset=20&limit=10", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := LeaderboardController{Repository: LeaderboardService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if gotStart != 20 || gotStop != 29 {
+		t.Errorf("fetched incorrect range. got: %v-%v, expected: %v-%v", gotStart, gotStop, 20, 29)
+	}
+
+	expected := "{\"key\":\"weekly\",\"error\":\"key specified does not exist.\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestLeaderboardController_ServeHTTPInvalidLimit(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/leaderboards?key=weekly&limit=1000", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := LeaderboardController{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+}
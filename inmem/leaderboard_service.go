@@ -0,0 +1,90 @@
+package inmem
+
+// LeaderboardDao interface is used by a LeaderboardService to access
+// the leaderboards stored as sorted sets in the in-memory database.
+type LeaderboardDao interface {
+	LeaderboardAdd(key string, member string, score float64, increment bool) (LeaderboardEntry, error)
+	LeaderboardMember(key string, member string) (LeaderboardDto, error)
+	LeaderboardRange(key string, start int64, stop int64) (LeaderboardDto, error)
+	LeaderboardRangeByScore(key string, min float64, max float64, offset int64, count int64) (LeaderboardDto, error)
+}
+
+// LeaderboardService uses LeaderboardDao to operate on leaderboards
+// and creates responses according to the possible errors.
+type LeaderboardService struct {
+	Dao LeaderboardDao
+}
+
+// Add sets the score of the member. If increment is true,
+// the score is added to the current score of the member.
+func (s LeaderboardService) Add(key string, member string, score float64, increment bool) (LeaderboardResponse, error) {
+	entry, err := s.Dao.LeaderboardAdd(key, member, score, increment)
+	if err != nil {
+		return LeaderboardResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	return LeaderboardResponse{Key: key, Entries: []LeaderboardEntry{entry}}, nil
+}
+
+// Member fetches the rank and the score of the member.
+func (s LeaderboardService) Member(key string, member string) (LeaderboardResponse, error) {
+	dto, err := s.Dao.LeaderboardMember(key, member)
+	if err != nil {
+		return LeaderboardResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	if !dto.Exists {
+		return LeaderboardResponse{Key: key, Error: "member specified does not exist."}, nil
+	}
+
+	return LeaderboardResponse{Key: key, Entries: dto.Entries, Total: dto.Total}, nil
+}
+
+// Top fetches limit members having the highest scores after skipping offset members.
+func (s LeaderboardService) Top(key string, offset int64, limit int64) (LeaderboardResponse, error) {
+	dto, err := s.Dao.LeaderboardRange(key, offset, offset+limit-1)
+	return s.rangeResponse(dto, err)
+}
+
+// Around fetches the member with window members ranked
+// right before and right after the member.
+func (s LeaderboardService) Around(key string, member string, window int64) (LeaderboardResponse, error) {
+	dto, err := s.Dao.LeaderboardMember(key, member)
+	if err != nil {
+		return LeaderboardResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	if !dto.Exists {
+		return LeaderboardResponse{Key: key, Error: "member specified does not exist."}, nil
+	}
+
+	// the ranks of the entries start from 1, Redis ranks start from 0.
+	rank := dto.Entries[0].Rank - 1
+	start := rank - window
+	if start < 0 {
+		start = 0
+	}
+
+	dto, err = s.Dao.LeaderboardRange(key, start, rank+window)
+	return s.rangeResponse(dto, err)
+}
+
+// ByScore fetches limit members whose scores are between min and max
+// after skipping offset members in the range.
+func (s LeaderboardService) ByScore(key string, min float64, max float64, offset int64, limit int64) (LeaderboardResponse, error) {
+	dto, err := s.Dao.LeaderboardRangeByScore(key, min, max, offset, limit)
+	return s.rangeResponse(dto, err)
+}
+
+// rangeResponse creates a response from a window of the leaderboard fetched from the Dao.
+func (s LeaderboardService) rangeResponse(dto LeaderboardDto, err error) (LeaderboardResponse, error) {
+	if err != nil {
+		return LeaderboardResponse{Key: dto.Key, Error: errorMessage(err)}, err
+	}
+
+	if !dto.Exists {
+		return LeaderboardResponse{Key: dto.Key, Error: "key specified does not exist."}, nil
+	}
+
+	return LeaderboardResponse{Key: dto.Key, Entries: dto.Entries, Total: dto.Total}, nil
+}
@@ -0,0 +1,194 @@
+package inmem
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ListRepository interface is used by a ListController
+// to operate on the lists stored in an in-memory database.
+type ListRepository interface {
+	Push(key string, side ListSide, values []string) (ListResponse, error)
+	Pop(key string, side ListSide) (ListResponse, error)
+	Range(key string, start int64, stop int64) (ListResponse, error)
+	Trim(key string, start int64, stop int64) (ListResponse, error)
+}
+
+// ListController is a handler for handling
+// requests coming to "/in-memory/lists" endpoint.
+// The request bodies are read up to the size of the values of MaxValueSize bytes,
+// zero MaxValueSize means that there is no limit.
+// The accesses to the lists are authorized by Acl if it is set.
+type ListController struct {
+	Repository   ListRepository
+	Acl          *Acl
+	MaxValueSize int
+}
+
+// ServeHTTP handles incoming requests to "/in-memory/lists" endpoint.
+// POST requests push values to a list.
+// DELETE requests pop an element from a list.
+// GET requests fetch a range of elements of a list.
+// PATCH requests trim a list to a range of elements.
+func (c ListController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		var payload ListPushRequest
+		err := parseJSON(rw, req, &payload, jsonBodyLimit(c.MaxValueSize))
+		if err != nil {
+			log.Printf("Error on parsing request JSON: %v", err)
+			writeJSON(rw, requestErrorStatus(err), ListResponse{Error: err.Error()})
+			return
+		}
+
+		if payload.Key == nil {
+			c.badRequest(rw, "key field is missing")
+			return
+		}
+
+		if len(payload.Values) == 0 {
+			c.badRequest(rw, "values field is missing or empty")
+			return
+		}
+
+		side, err := parseListSide(string(payload.Side))
+		if err != nil {
+			c.badRequest(rw, err.Error())
+			return
+		}
+
+		if !c.authorize(rw, req, ActionWrite, *payload.Key) {
+			return
+		}
+
+		resp, err := c.Repository.Push(*payload.Key, side, payload.Values)
+		if err != nil {
+			log.Printf("Error while pushing to the list: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodDelete:
+		query := req.URL.Query()
+		key := query.Get("key")
+		if key == "" {
+			c.badRequest(rw, "key parameter is missing")
+			return
+		}
+
+		side, err := parseListSide(query.Get("side"))
+		if err != nil {
+			c.badRequest(rw, err.Error())
+			return
+		}
+
+		if !c.authorize(rw, req, ActionWrite, key) {
+			return
+		}
+
+		resp, err := c.Repository.Pop(key, side)
+		if err != nil {
+			log.Printf("Error while popping from the list: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodGet:
+		query := req.URL.Query()
+		key := query.Get("key")
+		if key == "" {
+			c.badRequest(rw, "key parameter is missing")
+			return
+		}
+
+		// the whole list is fetched if the offsets are not given.
+		start, err := parseInt64Param(query, "start", 0)
+		if err != nil {
+			c.badRequest(rw, err.Error())
+			return
+		}
+
+		stop, err := parseInt64Param(query, "stop", -1)
+		if err != nil {
+			c.badRequest(rw, err.Error())
+			return
+		}
+
+		if !c.authorize(rw, req, ActionRead, key) {
+			return
+		}
+
+		resp, err := c.Repository.Range(key, start, stop)
+		if err != nil {
+			log.Printf("Error while fetching the list range: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodPatch:
+		var payload ListTrimRequest
+		err := parseJSON(rw, req, &payload, jsonBodyLimit(c.MaxValueSize))
+		if err != nil {
+			log.Printf("Error on parsing request JSON: %v", err)
+			writeJSON(rw, requestErrorStatus(err), ListResponse{Error: err.Error()})
+			return
+		}
+
+		if !c.validateTrimRequest(rw, payload) || !c.authorize(rw, req, ActionWrite, *payload.Key) {
+			return
+		}
+
+		resp, err := c.Repository.Trim(*payload.Key, *payload.Start, *payload.Stop)
+		if err != nil {
+			log.Printf("Error while trimming the list: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	default:
+		c.methodNotAllowed(rw)
+	}
+}
+
+// validateTrimRequest checks whether there are missing fields or not.
+func (c ListController) validateTrimRequest(rw http.ResponseWriter, payload ListTrimRequest) bool {
+	if payload.Key == nil {
+		c.badRequest(rw, "key field is missing")
+		return false
+	}
+
+	if payload.Start == nil {
+		c.badRequest(rw, "start field is missing")
+		return false
+	}
+
+	if payload.Stop == nil {
+		c.badRequest(rw, "stop field is missing")
+		return false
+	}
+
+	return true
+}
+
+// authorize checks the access of the client of the request to the list.
+func (c ListController) authorize(rw http.ResponseWriter, req *http.Request, action, key string) bool {
+	if err := checkAccess(c.Acl, req, action, key); err != nil {
+		writeJSON(rw, statusCodeOf(err), ListResponse{Key: key, Error: err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func (c ListController) badRequest(rw http.ResponseWriter, message string) {
+	writeJSON(rw, http.StatusBadRequest, ListResponse{Error: message})
+}
+
+func (c ListController) methodNotAllowed(rw http.ResponseWriter) {
+	writeJSON(rw, http.StatusMethodNotAllowed, ListResponse{Error: "the method is not allowed for this endpoint."})
+}
+
+// parseListSide validates the side of a list. Right is the default side.
+func parseListSide(side string) (ListSide, error) {
+	switch ListSide(side) {
+	case "", ListRight:
+		return ListRight, nil
+	case ListLeft:
+		return ListLeft, nil
+	default:
+		return "", fmt.Errorf("side must be either %v or %v", ListLeft, ListRight)
+	}
+}
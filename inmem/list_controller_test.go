@@ -0,0 +1,133 @@
+package inmem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockListDao struct {
+	ListPushMock  func(string, ListSide, []string) (int64, error)
+	ListPopMock   func(string, ListSide) (ListDto, error)
+	ListRangeMock func(string, int64, int64) (ListDto, error)
+	ListTrimMock  func(string, int64, int64) error
+}
+
+func (m mockListDao) ListPush(key string, side ListSide, values []string) (int64, error) {
+	return m.ListPushMock(key, side, values)
+}
+
+func (m mockListDao) ListPop(key string, side ListSide) (ListDto, error) {
+	return m.ListPopMock(key, side)
+}
+
+func (m mockListDao) ListRange(key string, start int64, stop int64) (ListDto, error) {
+	return m.ListRangeMock(key, start, stop)
+}
+
+func (m mockListDao) ListTrim(key string, start int64, stop int64) error {
+	return m.ListTrimMock(key, start, stop)
+}
+
+func TestListController_ServeHTTPValidPush(t *testing.T) {
+	var gotSide ListSide
+	mock := mockListDao{
+		ListPushMock: func(s string, side ListSide, values []string) (int64, error) {
+			gotSide = side
+			return int64(len(values)), nil
+		},
+	}
+
+	request := "{\"key\":\"jobs\",\"values\":[\"a\",\"b\"],\"side\":\"left\"}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory/lists", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := ListController{Repository: ListService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	if gotSide != ListLeft {
+		t.Errorf("pushed to incorrect side. got: %v, expected: %v", gotSide, ListLeft)
+	}
+
+	expected := "{\"key\":\"jobs\",\"length\":2}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestListController_ServeHTTPInvalidSide(t *testing.T) {
+	req, err := http.NewRequest(http.MethodDelete, "/in-memory/lists?key=jobs&side=middle", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := ListController{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+
+	expected := "{\"key\":\"\",\"error\":\"side must be either left or right\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestListController_ServeHTTPRangeWithDefaults(t *testing.T) {
+	var gotStart, gotStop int64
+	mock := mockListDao{
+		ListRangeMock: func(s string, start int64, stop int64) (ListDto, error) {
+			gotStart, gotStop = start, stop
+			return ListDto{Key: "jobs", Values: []string{"a", "b"}, Exists: true}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/lists?key=jobs", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := ListController{Repository: ListService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if gotStart != 0 || gotStop != -1 {
+		t.Errorf("fetched incorrect range. got: %v-%v, expected: %v-%v", gotStart, gotStop, 0, -1)
+	}
+
+	expected := "{\"key\":\"jobs\",\"values\":[\"a\",\"b\"]}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestListController_ServeHTTPTrimMissingField(t *testing.T) {
+	request := "{\"key\":\"jobs\",\"start\":0}"
+	req, err := http.NewRequest(http.MethodPatch, "/in-memory/lists", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := ListController{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+
+	expected := "{\"key\":\"\",\"error\":\"stop field is missing\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
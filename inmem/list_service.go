@@ -0,0 +1,60 @@
+package inmem
+
+// ListDao interface is used by a ListService to access the lists
+// stored in the in-memory database.
+type ListDao interface {
+	ListPush(key string, side ListSide, values []string) (int64, error)
+	ListPop(key string, side ListSide) (ListDto, error)
+	ListRange(key string, start int64, stop int64) (ListDto, error)
+	ListTrim(key string, start int64, stop int64) error
+}
+
+// ListService uses ListDao to operate on lists and
+// creates responses according to the possible errors.
+type ListService struct {
+	Dao ListDao
+}
+
+// Push inserts the values to the specified side of the list.
+func (s ListService) Push(key string, side ListSide, values []string) (ListResponse, error) {
+	length, err := s.Dao.ListPush(key, side, values)
+	if err != nil {
+		return ListResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	return ListResponse{Key: key, Length: length}, nil
+}
+
+// Pop removes and returns the element at the specified side of the list.
+func (s ListService) Pop(key string, side ListSide) (ListResponse, error) {
+	dto, err := s.Dao.ListPop(key, side)
+	if err != nil {
+		return ListResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	if !dto.Exists {
+		return ListResponse{Key: key, Error: "key specified does not exist or the list is empty."}, nil
+	}
+
+	return ListResponse{Key: key, Values: dto.Values}, nil
+}
+
+// Range fetches the elements of the list between start and stop offsets.
+func (s ListService) Range(key string, start int64, stop int64) (ListResponse, error) {
+	dto, err := s.Dao.ListRange(key, start, stop)
+	if err != nil {
+		return ListResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	return ListResponse{Key: key, Values: dto.Values}, nil
+}
+
+// Trim trims the list to the elements between start and stop offsets.
+func (s ListService) Trim(key string, start int64, stop int64) (ListResponse, error) {
+	err := s.Dao.ListTrim(key, start, stop)
+	if err != nil {
+		return ListResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	return ListResponse{Key: key}, nil
+}
@@ -0,0 +1,85 @@
+package inmem
+
+import (
+	"context"
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"log"
+	"net/http"
+)
+
+// NamespaceHeader is the request header selecting the namespace explicitly.
+const NamespaceHeader = "X-Namespace"
+
+// ErrInvalidNamespace is returned when the name of a namespace is not valid.
+var ErrInvalidNamespace = errors.New("invalid namespace")
+
+// ErrNamespaceForbidden is returned when a client selects a namespace other than its own.
+var ErrNamespaceForbidden = errors.New("namespace is not accessible by the client")
+
+type namespaceContextKey struct{}
+
+// ContextWithNamespace returns a copy of the context carrying the namespace
+// of the authenticated client. The requests of the client are restricted to it.
+func ContextWithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceContextKey{}, namespace)
+}
+
+// NamespaceFromContext returns the namespace of the authenticated client if it is set.
+func NamespaceFromContext(ctx context.Context) (string, bool) {
+	namespace, ok := ctx.Value(namespaceContextKey{}).(string)
+	return namespace, ok
+}
+
+// NamespaceOf returns the namespace of the request. The namespace of the authenticated
+// client is used if it is set, selecting another namespace by the header is forbidden.
+// Otherwise, the namespace is selected by the header, it is the default namespace
+// which is an empty string if the header is not given.
+func NamespaceOf(req *http.Request) (string, error) {
+	header := req.Header.Get(NamespaceHeader)
+	if namespace, ok := NamespaceFromContext(req.Context()); ok {
+		if header != "" && header != namespace {
+			return "", ErrNamespaceForbidden
+		}
+		return namespace, nil
+	}
+
+	if header != "" && !api.ValidNamespace(header) {
+		return "", ErrInvalidNamespace
+	}
+
+	return header, nil
+}
+
+// NamespaceHandler serves the requests by the handler built
+// for the namespace of the request by Handler function.
+// The handler accesses the keys of the namespace within the quota
+// of the namespace by the dao given to it.
+type NamespaceHandler struct {
+	Dao     RedisDao
+	Quotas  Quotas
+	Handler func(dao RedisDao) http.Handler
+}
+
+// ServeHTTP resolves the namespace of the request and serves it by the handler of the namespace.
+func (h NamespaceHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	namespace, err := NamespaceOf(req)
+	if err != nil {
+		log.Printf("Error on resolving the namespace: %v", err)
+
+		statusCode := http.StatusBadRequest
+		message := "namespace must consist of 1 to 64 letters, digits, '_', '.' or '-'."
+		if errors.Is(err, ErrNamespaceForbidden) {
+			statusCode = http.StatusForbidden
+			message = "namespace is not accessible by the client."
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		writeJSON(rw, statusCode, NamespaceResponse{Error: message})
+		return
+	}
+
+	dao := h.Dao.WithNamespace(namespace)
+	dao.Quota = h.Quotas.Of(namespace)
+	h.Handler(dao).ServeHTTP(rw, req)
+}
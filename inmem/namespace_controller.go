@@ -0,0 +1,132 @@
+package inmem
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+)
+
+const (
+	// defaultNamespaceKeysCount is the number of the keys scanned in a batch if count parameter is not given.
+	defaultNamespaceKeysCount = 100
+	// maxNamespaceKeysCount is the maximum number of the keys scanned in a batch.
+	maxNamespaceKeysCount = 1000
+)
+
+// NamespaceRepository interface is used by a NamespaceController
+// to operate on the keys of a namespace.
+type NamespaceRepository interface {
+	Keys(cursor uint64, count int64) (NamespaceResponse, error)
+	Flush() (NamespaceResponse, error)
+	Usage() (NamespaceResponse, error)
+	Quota() (NamespaceResponse, error)
+}
+
+// NamespaceController is a handler for handling requests coming to
+// "/namespaces/keys", "/namespaces/flush", "/namespaces/stats" and "/namespaces/quota" endpoints.
+// The namespace is resolved by a NamespaceHandler. The accesses are authorized by Acl
+// if it is set, they require the clients to be allowed to access all the keys of the namespace.
+type NamespaceController struct {
+	Repository NamespaceRepository
+	Acl        *Acl
+}
+
+// ServeHTTP handles incoming requests to the namespace endpoints.
+// GET requests to keys endpoint list the keys of the namespace in batches by cursor and count parameters.
+// POST requests to flush endpoint remove all the keys of the namespace.
+// GET requests to stats endpoint return the number of the keys and the memory used by them.
+// GET requests to quota endpoint return the usage of the quota of the namespace.
+func (c NamespaceController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var resp NamespaceResponse
+	var err error
+	switch path.Base(req.URL.Path) {
+	case "keys":
+		if req.Method != http.MethodGet {
+			c.methodNotAllowed(rw)
+			return
+		}
+
+		query := req.URL.Query()
+		var cursor uint64
+		if param := query.Get("cursor"); param != "" {
+			cursor, err = strconv.ParseUint(param, 10, 64)
+			if err != nil {
+				c.badRequest(rw, "cursor parameter must be a non-negative integer")
+				return
+			}
+		}
+
+		count, err := parseInt64Param(query, "count", defaultNamespaceKeysCount)
+		if err != nil || count < 1 || count > maxNamespaceKeysCount {
+			c.badRequest(rw, fmt.Sprintf("count parameter must be an integer between 1 and %v", maxNamespaceKeysCount))
+			return
+		}
+
+		if !c.authorize(rw, req, ActionRead) {
+			return
+		}
+
+		resp, err = c.Repository.Keys(cursor, count)
+	case "flush":
+		if req.Method != http.MethodPost {
+			c.methodNotAllowed(rw)
+			return
+		}
+
+		if !c.authorize(rw, req, ActionWrite) {
+			return
+		}
+
+		resp, err = c.Repository.Flush()
+	case "stats":
+		if req.Method != http.MethodGet {
+			c.methodNotAllowed(rw)
+			return
+		}
+
+		if !c.authorize(rw, req, ActionRead) {
+			return
+		}
+
+		resp, err = c.Repository.Usage()
+	case "quota":
+		if req.Method != http.MethodGet {
+			c.methodNotAllowed(rw)
+			return
+		}
+
+		if !c.authorize(rw, req, ActionRead) {
+			return
+		}
+
+		resp, err = c.Repository.Quota()
+	default:
+		writeJSON(rw, http.StatusNotFound, NamespaceResponse{Error: "the endpoint does not exist."})
+		return
+	}
+
+	if err != nil {
+		log.Printf("Error while operating on the namespace: %v", err)
+	}
+	writeJSON(rw, statusCodeOf(err), resp)
+}
+
+// authorize checks the access of the client of the request to all the keys of the namespace.
+func (c NamespaceController) authorize(rw http.ResponseWriter, req *http.Request, action string) bool {
+	if err := checkAccess(c.Acl, req, action, ""); err != nil {
+		writeJSON(rw, statusCodeOf(err), NamespaceResponse{Error: err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func (c NamespaceController) badRequest(rw http.ResponseWriter, message string) {
+	writeJSON(rw, http.StatusBadRequest, NamespaceResponse{Error: message})
+}
+
+func (c NamespaceController) methodNotAllowed(rw http.ResponseWriter) {
+	writeJSON(rw, http.StatusMethodNotAllowed, NamespaceResponse{Error: "the method is not allowed for this endpoint."})
+}
@@ -0,0 +1,114 @@
+package inmem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockNamespaceService struct {
+	KeysMock  func(uint64, int64) (NamespaceResponse, error)
+	FlushMock func() (NamespaceResponse, error)
+	UsageMock func() (NamespaceResponse, error)
+	QuotaMock func() (NamespaceResponse, error)
+}
+
+func (m mockNamespaceService) Keys(cursor uint64, count int64) (NamespaceResponse, error) {
+	return m.KeysMock(cursor, count)
+}
+
+func (m mockNamespaceService) Flush() (NamespaceResponse, error) {
+	return m.FlushMock()
+}
+
+func (m mockNamespaceService) Usage() (NamespaceResponse, error) {
+	return m.UsageMock()
+}
+
+func (m mockNamespaceService) Quota() (NamespaceResponse, error) {
+	return m.QuotaMock()
+}
+
+func TestNamespaceController_ServeHTTPKeys(t *testing.T) {
+	mock := mockNamespaceService{
+		KeysMock: func(cursor uint64, count int64) (NamespaceResponse, error) {
+			if cursor != 12 || count != 50 {
+				t.Errorf("returned incorrect parameters. got: %v %v, expected: %v %v", cursor, count, 12, 50)
+			}
+
+			next := uint64(0)
+			return NamespaceResponse{Namespace: "team-a", Keys: []string{"active-tabs"}, Cursor: &next}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/namespaces/keys?cursor=12&count=50", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := NamespaceController{Repository: mock}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expected := "{\"namespace\":\"team-a\",\"keys\":[\"active-tabs\"],\"cursor\":0}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestNamespaceController_ServeHTTPInvalidCount(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "/namespaces/keys?count=5000", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := NamespaceController{Repository: mockNamespaceService{}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestNamespaceController_ServeHTTPFlush(t *testing.T) {
+	mock := mockNamespaceService{
+		FlushMock: func() (NamespaceResponse, error) {
+			removed := int64(3)
+			return NamespaceResponse{Namespace: "team-a", Removed: &removed}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/namespaces/flush", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := NamespaceController{Repository: mock}
+	controller.ServeHTTP(rr, req)
+
+	expected := "{\"namespace\":\"team-a\",\"removed\":3}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestNamespaceController_ServeHTTPStatsMethodNotAllowed(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/namespaces/stats", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := NamespaceController{Repository: mockNamespaceService{}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusMethodNotAllowed {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,70 @@
+package inmem
+
+// NamespaceDao interface is used by a NamespaceService
+// to access the keys of a namespace.
+type NamespaceDao interface {
+	NamespaceKeys(cursor uint64, count int64) (NamespaceDto, error)
+	FlushNamespace() (NamespaceDto, error)
+	NamespaceUsage() (NamespaceDto, error)
+	QuotaUsage() (NamespaceDto, error)
+}
+
+// NamespaceService uses NamespaceDao to list, flush and measure
+// the keys of a namespace and creates responses according to the possible errors.
+type NamespaceService struct {
+	Dao NamespaceDao
+}
+
+// Keys fetches a batch of the keys of the namespace starting from the cursor.
+func (s NamespaceService) Keys(cursor uint64, count int64) (NamespaceResponse, error) {
+	dto, err := s.Dao.NamespaceKeys(cursor, count)
+	if err != nil {
+		return NamespaceResponse{Namespace: dto.Namespace, Error: errorMessage(err)}, err
+	}
+
+	keys := dto.Keys
+	if keys == nil {
+		keys = []string{}
+	}
+
+	return NamespaceResponse{Namespace: dto.Namespace, Keys: keys, Cursor: &dto.Cursor}, nil
+}
+
+// Flush removes all the keys of the namespace.
+func (s NamespaceService) Flush() (NamespaceResponse, error) {
+	dto, err := s.Dao.FlushNamespace()
+	if err != nil {
+		return NamespaceResponse{Namespace: dto.Namespace, Error: errorMessage(err)}, err
+	}
+
+	return NamespaceResponse{Namespace: dto.Namespace, Removed: &dto.KeyCount}, nil
+}
+
+// Usage returns the number of the keys of the namespace and the memory used by them.
+func (s NamespaceService) Usage() (NamespaceResponse, error) {
+	dto, err := s.Dao.NamespaceUsage()
+	if err != nil {
+		return NamespaceResponse{Namespace: dto.Namespace, Error: errorMessage(err)}, err
+	}
+
+	return NamespaceResponse{Namespace: dto.Namespace, KeyCount: &dto.KeyCount, Bytes: &dto.Bytes}, nil
+}
+
+// Quota returns the usage of the quota of the namespace with its limits,
+// the limits which are disabled are omitted.
+func (s NamespaceService) Quota() (NamespaceResponse, error) {
+	dto, err := s.Dao.QuotaUsage()
+	if err != nil {
+		return NamespaceResponse{Namespace: dto.Namespace, Error: errorMessage(err)}, err
+	}
+
+	resp := NamespaceResponse{Namespace: dto.Namespace, KeyCount: &dto.KeyCount, Bytes: &dto.Bytes}
+	if dto.Quota.MaxKeys > 0 {
+		resp.MaxKeys = &dto.Quota.MaxKeys
+	}
+
+	if dto.Quota.MaxBytes > 0 {
+		resp.MaxBytes = &dto.Quota.MaxBytes
+	}
+	return resp, nil
+}
@@ -0,0 +1,123 @@
+package inmem
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNamespaceOf(t *testing.T) {
+	tests := []struct {
+		contextNamespace *string
+		header           string
+		expected         string
+		expectedErr      error
+	}{
+		{nil, "", "", nil},
+		{nil, "team-a", "team-a", nil},
+		{nil, "team:a", "", ErrInvalidNamespace},
+		{nil, "team*", "", ErrInvalidNamespace},
+		{stringPointer("team-a"), "", "team-a", nil},
+		{stringPointer("team-a"), "team-a", "team-a", nil},
+		{stringPointer("team-a"), "team-b", "", ErrNamespaceForbidden},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(http.MethodGet, "/in-memory?key=k", nil)
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if test.header != "" {
+			req.Header.Set(NamespaceHeader, test.header)
+		}
+
+		if test.contextNamespace != nil {
+			req = req.WithContext(ContextWithNamespace(req.Context(), *test.contextNamespace))
+		}
+
+		got, err := NamespaceOf(req)
+		if got != test.expected || !errors.Is(err, test.expectedErr) {
+			t.Errorf("returned incorrect namespace. got: %v (%v), expected: %v (%v)", got, err, test.expected, test.expectedErr)
+		}
+	}
+}
+
+func TestRedisDao_Key(t *testing.T) {
+	tests := []struct {
+		namespace string
+		key       string
+		expected  string
+	}{
+		{"", "active-tabs", "ns::default:active-tabs"},
+		{"", "ns:team-a:active-tabs", "ns::default:ns:team-a:active-tabs"},
+		{"", "queue:{jobs}:ready", "ns::default:queue:{jobs}:ready"},
+		{"team-a", "active-tabs", "ns:team-a:active-tabs"},
+		{"team-a", "ns:team-b:active-tabs", "ns:team-a:ns:team-b:active-tabs"},
+	}
+
+	for _, test := range tests {
+		got := RedisDao{}.WithNamespace(test.namespace).key(test.key)
+		if got != test.expected {
+			t.Errorf("returned incorrect key. got: %v, expected: %v", got, test.expected)
+		}
+	}
+}
+
+func TestNamespaceHandler_ServeHTTP(t *testing.T) {
+	var got RedisDao
+	handler := NamespaceHandler{
+		Quotas: Quotas{Namespaces: map[string]Quota{"team-a": {MaxKeys: 10}}},
+		Handler: func(dao RedisDao) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				got = dao
+			})
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory?key=k", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	req.Header.Set(NamespaceHeader, "team-a")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if got.Namespace != "team-a" || got.Quota.MaxKeys != 10 {
+		t.Errorf("returned incorrect namespace. got: %v (%+v), expected: %v (%+v)", got.Namespace, got.Quota, "team-a", Quota{MaxKeys: 10})
+	}
+}
+
+func TestNamespaceHandler_ServeHTTPForbidden(t *testing.T) {
+	handler := NamespaceHandler{
+		Handler: func(dao RedisDao) http.Handler {
+			t.Errorf("handler of the namespace %v is called", dao.Namespace)
+			return http.NotFoundHandler()
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory?key=k", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	req.Header.Set(NamespaceHeader, "team-b")
+	req = req.WithContext(ContextWithNamespace(req.Context(), "team-a"))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusForbidden {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusForbidden)
+	}
+
+	expected := "{\"namespace\":\"\",\"error\":\"namespace is not accessible by the client.\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
@@ -0,0 +1,55 @@
+package inmem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Quota limits the number of the keys of a namespace of any data type
+// and the bytes stored by them, zero limits are disabled.
+type Quota struct {
+	MaxKeys  int64 `json:"maxKeys"`
+	MaxBytes int64 `json:"maxBytes"`
+}
+
+// Quotas holds the quotas of the namespaces. The namespaces
+// which do not have a quota of their own are limited by Default.
+type Quotas struct {
+	Default    Quota
+	Namespaces map[string]Quota
+}
+
+// LoadQuotas reads the quotas of the namespaces from a JSON file
+// in the format of {"namespace": {"maxKeys": 1000, "maxBytes": 1048576}}.
+// The default namespace is configured by an empty name.
+func LoadQuotas(path string, defaultQuota Quota) (Quotas, error) {
+	quotas := Quotas{Default: defaultQuota}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return quotas, err
+	}
+
+	err = json.Unmarshal(content, &quotas.Namespaces)
+	if err != nil {
+		return quotas, fmt.Errorf("error on parsing the quota file: %w", err)
+	}
+
+	for namespace, quota := range quotas.Namespaces {
+		if quota.MaxKeys < 0 || quota.MaxBytes < 0 {
+			return quotas, fmt.Errorf("quota of the namespace %q must not be negative", namespace)
+		}
+	}
+
+	return quotas, nil
+}
+
+// Of returns the quota of the namespace.
+func (q Quotas) Of(namespace string) Quota {
+	if quota, ok := q.Namespaces[namespace]; ok {
+		return quota
+	}
+
+	return q.Default
+}
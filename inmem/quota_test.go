@@ -0,0 +1,125 @@
+package inmem
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadQuotas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotas.json")
+	content := "{\"team-a\": {\"maxKeys\": 10, \"maxBytes\": 1024}, \"\": {\"maxKeys\": 100}}"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	quotas, err := LoadQuotas(path, Quota{MaxKeys: 5})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tests := []struct {
+		namespace string
+		expected  Quota
+	}{
+		{"team-a", Quota{MaxKeys: 10, MaxBytes: 1024}},
+		{"", Quota{MaxKeys: 100}},
+		{"team-b", Quota{MaxKeys: 5}},
+	}
+
+	for _, test := range tests {
+		if got := quotas.Of(test.namespace); got != test.expected {
+			t.Errorf("returned incorrect quota of %q. got: %+v, expected: %+v", test.namespace, got, test.expected)
+		}
+	}
+}
+
+func TestLoadQuotas_Negative(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotas.json")
+	if err := ioutil.WriteFile(path, []byte("{\"team-a\": {\"maxKeys\": -1}}"), 0600); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if _, err := LoadQuotas(path, Quota{}); err == nil {
+		t.Errorf("returned incorrect error. got: %v, expected: an error", err)
+	}
+}
+
+func TestWrapError_Quota(t *testing.T) {
+	tests := []struct {
+		reply    string
+		expected error
+	}{
+		{"KEYQUOTA the key quota of the namespace is exceeded", ErrKeyQuotaExceeded},
+		{"BYTEQUOTA the byte quota of the namespace is exceeded", ErrByteQuotaExceeded},
+	}
+
+	for _, test := range tests {
+		if got := wrapError(errors.New(test.reply)); !errors.Is(got, test.expected) {
+			t.Errorf("returned incorrect error. got: %v, expected: %v", got, test.expected)
+		}
+	}
+}
+
+func TestUsageKeyOf(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"queue:{jobs}:ready", ""},
+		{"ns::default:active-tabs", "ns:"},
+		{"ns:team-a:active-tabs", "ns:team-a"},
+		{"ns:team-a:ns:team-b:active-tabs", "ns:team-a"},
+		{"ns:team-a", ""},
+		{"ns:", ""},
+		{"ns::schemas", ""},
+		{"ns::schemas-version", ""},
+	}
+
+	for _, test := range tests {
+		if got := usageKeyOf(test.key); got != test.expected {
+			t.Errorf("returned incorrect usage key of %v. got: %v, expected: %v", test.key, got, test.expected)
+		}
+	}
+}
+
+func TestController_ServeHTTPQuotaExceeded(t *testing.T) {
+	tests := []struct {
+		err          error
+		expectedCode int
+		expected     string
+	}{
+		{ErrKeyQuotaExceeded, http.StatusTooManyRequests, "{\"key\":\"active-tabs\",\"value\":\"\",\"error\":\"namespace has reached its key quota.\"}"},
+		{ErrByteQuotaExceeded, http.StatusInsufficientStorage, "{\"key\":\"active-tabs\",\"value\":\"\",\"error\":\"namespace has reached its byte quota.\"}"},
+	}
+
+	for _, test := range tests {
+		quotaErr := test.err
+		service := Service{Dao: mockDao{
+			SetMock: func(dto Dto) (Dto, error) {
+				return dto, quotaErr
+			},
+		}}
+
+		req, err := http.NewRequest(http.MethodPost, "/in-memory", strings.NewReader("{\"key\":\"active-tabs\",\"value\":\"getir\"}"))
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		rr := httptest.NewRecorder()
+		controller := Controller{Repository: service}
+		controller.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.expectedCode {
+			t.Errorf("returned incorrect status code. got: %v, expected: %v", status, test.expectedCode)
+		}
+
+		if rr.Body.String() != test.expected {
+			t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), test.expected)
+		}
+	}
+}
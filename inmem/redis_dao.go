@@ -2,30 +2,1030 @@ package inmem
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/go-redis/redis/v8"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"log"
+	"math"
+	"strconv"
+	"strings"
 )
 
+// ErrWrongType is returned when an operation is performed
+// against a key holding another kind of value.
+var ErrWrongType = errors.New("operation against a key holding the wrong kind of value")
+
+// ErrConflict is returned when an imported key already exists and the conflict policy is ConflictFail.
+var ErrConflict = errors.New("key already exists")
+
+// ErrKeyNotFound is returned when the key specified does not exist.
+var ErrKeyNotFound = errors.New("key does not exist")
+
+// ErrValueTooLarge is returned when a value exceeds the maximum value size.
+var ErrValueTooLarge = errors.New("value is too large")
+
+// ErrInvalidSnapshot is returned when an imported archive cannot be parsed.
+var ErrInvalidSnapshot = errors.New("invalid snapshot archive")
+
+// ErrKeyQuotaExceeded is returned when a key is not created since the namespace reached its key quota.
+var ErrKeyQuotaExceeded = errors.New("key quota is exceeded")
+
+// ErrByteQuotaExceeded is returned when a value is not stored since the namespace reached its byte quota.
+var ErrByteQuotaExceeded = errors.New("byte quota is exceeded")
+
+// ErrEncryptionUnsupported is returned when a value of a data type which cannot be encrypted
+// is written while the values are encrypted.
+var ErrEncryptionUnsupported = errors.New("data type is not supported while the values are encrypted")
+
+// namespacePrefix prefixes the keys of the namespaces in the database.
+// The in-memory keys are not stored anywhere else, so that the keys of
+// the other components sharing the database are not accessible by the clients.
+const namespacePrefix = "ns:"
+
+// defaultNamespacePrefix prefixes the keys of the default namespace.
+// It does not collide with the other namespaces since their names are not empty.
+const defaultNamespacePrefix = namespacePrefix + ":default:"
+
+// schemasKey is the key of the hash holding the JSON Schemas by key prefixes.
+// It is not accessible as a key of any namespace since the names of the namespaces are not empty.
+const schemasKey = namespacePrefix + ":schemas"
+
+// schemasVersionKey is the key of the version of the JSON Schemas, which is incremented
+// whenever a schema is set or deleted so that the compiled schemas are fetched again.
+const schemasVersionKey = namespacePrefix + ":schemas-version"
+
 // RedisDao manages the interaction between the Redis database.
+// The string values which are at least CompressionThreshold bytes long
+// are stored compressed, zero threshold disables the compression.
+// If Keyring is set, the string values are stored encrypted and the values of the other data types,
+// whose fields and members must be stored as they are to be looked up, are not written.
+// The keys are prefixed by "ns:{namespace}:" transparently, or by "ns::default:"
+// if Namespace is not set. The keys are written within the Quota of the namespace.
 type RedisDao struct{
 	Db *redis.Client
+	CompressionThreshold int
+	Keyring *Keyring
+	Namespace string
+	Quota Quota
+}
+
+// WithNamespace returns a copy of the dao accessing the keys of the namespace.
+func (d RedisDao) WithNamespace(namespace string) RedisDao {
+	d.Namespace = namespace
+	return d
+}
+
+// key returns the key in the database for the key of the namespace.
+func (d RedisDao) key(key string) string {
+	return d.keyPrefix() + key
+}
+
+// keyPrefix returns the prefix of the keys of the namespace in the database.
+func (d RedisDao) keyPrefix() string {
+	if d.Namespace == "" {
+		return defaultNamespacePrefix
+	}
+
+	return namespacePrefix + d.Namespace + ":"
+}
+
+// usageKey returns the key of the hash holding the usage of the namespace.
+// It is not accessible as a key of any namespace since it has no trailing separator.
+func usageKey(namespace string) string {
+	return namespacePrefix + namespace
+}
+
+// usageKeyOf returns the key of the hash holding the usage of the namespace of a key in the database,
+// or an empty string if the key is not in a namespace.
+func usageKeyOf(redisKey string) string {
+	namespace, ok := namespaceOfKey(redisKey)
+	if !ok {
+		return ""
+	}
+
+	return usageKey(namespace)
+}
+
+// namespaceOfKey returns the namespace of a key in the database. It returns false for the keys
+// which are not keys of a namespace: the usage hashes, the JSON Schemas and the keys of the other components.
+func namespaceOfKey(redisKey string) (string, bool) {
+	if strings.HasPrefix(redisKey, defaultNamespacePrefix) {
+		return "", true
+	}
+
+	if !strings.HasPrefix(redisKey, namespacePrefix) {
+		return "", false
+	}
+
+	namespace := strings.TrimPrefix(redisKey, namespacePrefix)
+	i := strings.Index(namespace, ":")
+	if i < 0 || !api.ValidNamespace(namespace[:i]) {
+		return "", false
+	}
+
+	return namespace[:i], true
 }
 
 func (d RedisDao) Get(key string) (Dto, error) {
 	var dto Dto
 	dto.Key = key
 
-	val, err := d.Db.Get(context.Background(), key).Result()
+	redisKey := d.key(key)
+
+	val, err := d.Db.Get(context.Background(), redisKey).Result()
 	if err == redis.Nil {
 		dto.Exists = false
 		return dto, nil
 	}
 
+	if err != nil {
+		return dto, wrapError(err)
+	}
+
+	dto.Exists = true
+	dto.StoredSize = len(val)
+	var meta valueMeta
+	dto.Value, meta, err = decodeValue(redisKey, val, d.Keyring)
+	dto.ContentType, dto.Binary = meta.contentType, meta.binary
+	dto.RawSize = len(dto.Value)
+	return dto, err
+}
+
+// Set stores the value of the dto, compressing it if it is large enough.
+// It returns the dto with the raw and the stored sizes of the value.
+// The stored size of the value is counted by the usage of the namespace.
+func (d RedisDao) Set(dto Dto) (Dto, error) {
+	redisKey := d.key(dto.Key)
+
+	meta := valueMeta{contentType: dto.ContentType, binary: dto.Binary}
+	stored, err := encodeValue(redisKey, dto.Value, meta, d.CompressionThreshold, d.Keyring)
+	if err != nil {
+		return dto, err
+	}
+
+	err = d.runQuotaScript(setScript, redisKey, stored).Err()
+
+	dto.RawSize = len(dto.Value)
+	dto.StoredSize = len(stored)
+	return dto, wrapError(err)
+}
+
+// Delete removes the key. It returns false if the key does not exist.
+func (d RedisDao) Delete(key string) (bool, error) {
+	redisKey := d.key(key)
+
+	removed, err := d.runQuotaScript(deleteScript, redisKey).Int64()
+	return removed > 0, wrapError(err)
+}
+
+// runQuotaScript runs a script prepended by quotaPrelude against the key with the arguments.
+func (d RedisDao) runQuotaScript(script *redis.Script, redisKey string, args ...interface{}) *redis.Cmd {
+	keys := []string{redisKey, usageKey(d.Namespace)}
+	args = append([]interface{}{d.Quota.MaxKeys, d.Quota.MaxBytes}, args...)
+	return script.Run(context.Background(), d.Db, keys, args...)
+}
+
+// Reencrypt re-encrypts the string values of the namespaces which are not encrypted by the primary key
+// of the keyring, e.g. after a key rotation, or which are encrypted without their keys. The values are rewritten in optimistic transactions keeping
+// their TTLs, a value changed during its transaction is skipped since it is already written by the primary key.
+// The values without an envelope and the values which cannot be decoded are logged and skipped,
+// the plain values are encrypted when they are written again.
+// It returns the number of the re-encrypted values.
+func (d RedisDao) Reencrypt(ctx context.Context) (int64, error) {
+	if d.Keyring == nil {
+		return 0, nil
+	}
+
+	var reencrypted int64
+	var cursor uint64
+	for {
+		keys, next, err := d.Db.ScanType(ctx, cursor, namespacePrefix+"*", 100, TypeString).Result()
+		if err != nil {
+			return reencrypted, err
+		}
+
+		for _, key := range keys {
+			// the internal keys, e.g. the schemas version, are not values of the namespaces.
+			if _, ok := namespaceOfKey(key); !ok {
+				continue
+			}
+
+			ok, err := d.reencryptKey(ctx, key)
+			if err != nil {
+				return reencrypted, fmt.Errorf("error on re-encrypting %v: %w", key, err)
+			}
+
+			if ok {
+				reencrypted++
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return reencrypted, nil
+		}
+	}
+}
+
+// reencryptKey rewrites the value of the key by the primary key of the keyring if it is needed.
+func (d RedisDao) reencryptKey(ctx context.Context, key string) (bool, error) {
+	reencrypted := false
+	err := d.Db.Watch(ctx, func(tx *redis.Tx) error {
+		stored, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(stored, envelopeMagic) {
+			log.Printf("Skipped re-encrypting %v since it is not stored in an envelope.", key)
+			return nil
+		}
+
+		if keyId, ok := encryptionKeyId(stored); ok && keyId == d.Keyring.Primary() && boundToKey(stored) {
+			return nil
+		}
+
+		value, meta, err := decodeValue(key, stored, d.Keyring)
+		if err != nil {
+			log.Printf("Skipped re-encrypting %v since it cannot be decoded: %v", key, err)
+			return nil
+		}
+
+		reencoded, err := encodeValue(key, value, meta, d.CompressionThreshold, d.Keyring)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, reencoded, redis.KeepTTL)
+			pipe.HIncrBy(ctx, usageKeyOf(key), "bytes", int64(len(reencoded)-len(stored)))
+			return nil
+		})
+		reencrypted = err == nil
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		return false, nil
+	}
+
+	return reencrypted, err
+}
+
+// wrapError converts the WRONGTYPE errors replied by Redis to ErrWrongType
+// and the quota errors replied by the scripts to the quota errors
+// so that the upper layers can distinguish them from the other errors.
+func wrapError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case strings.HasPrefix(err.Error(), "WRONGTYPE"):
+		return fmt.Errorf("%w: %v", ErrWrongType, err)
+	case strings.HasPrefix(err.Error(), "KEYQUOTA"):
+		return fmt.Errorf("%w: %v", ErrKeyQuotaExceeded, err)
+	case strings.HasPrefix(err.Error(), "BYTEQUOTA"):
+		return fmt.Errorf("%w: %v", ErrByteQuotaExceeded, err)
+	default:
+		return err
+	}
+}
+
+// HashGet fetches the value of a field in the hash stored at key.
+func (d RedisDao) HashGet(key string, field string) (HashDto, error) {
+	dto := HashDto{Key: key, Field: field}
+
+	redisKey := d.key(key)
+
+	val, err := d.Db.HGet(context.Background(), redisKey, field).Result()
+	if err == redis.Nil {
+		return dto, nil
+	}
+
 	dto.Exists = true
 	dto.Value = val
+	return dto, wrapError(err)
+}
+
+// HashGetAll fetches all the fields and values of the hash stored at key.
+func (d RedisDao) HashGetAll(key string) (HashDto, error) {
+	dto := HashDto{Key: key}
+
+	redisKey := d.key(key)
+
+	fields, err := d.Db.HGetAll(context.Background(), redisKey).Result()
+	if err != nil {
+		return dto, wrapError(err)
+	}
+
+	// Redis replies an empty hash for non-existing keys.
+	dto.Exists = len(fields) > 0
+	dto.Fields = fields
+	return dto, nil
+}
+
+// HashSet sets the field of the hash stored at key to the value.
+func (d RedisDao) HashSet(dto HashDto) error {
+	if d.Keyring != nil {
+		return ErrEncryptionUnsupported
+	}
+
+	redisKey := d.key(dto.Key)
+
+	err := d.runQuotaScript(hashSetScript, redisKey, dto.Field, dto.Value).Err()
+	return wrapError(err)
+}
+
+// HashDelete removes the field from the hash stored at key.
+// It returns false if the field does not exist.
+func (d RedisDao) HashDelete(key string, field string) (bool, error) {
+	redisKey := d.key(key)
+
+	removed, err := d.runQuotaScript(hashDeleteScript, redisKey, field).Int64()
+	return removed > 0, wrapError(err)
+}
+
+// ListPush inserts the values to the head or tail of the list stored at key.
+// It returns the length of the list after the push operation.
+func (d RedisDao) ListPush(key string, side ListSide, values []string) (int64, error) {
+	if d.Keyring != nil {
+		return 0, ErrEncryptionUnsupported
+	}
+
+	redisKey := d.key(key)
+
+	args := append([]interface{}{string(side)}, toInterfaces(values)...)
+	length, err := d.runQuotaScript(listPushScript, redisKey, args...).Int64()
+	return length, wrapError(err)
+}
+
+// ListPop removes and returns the first or the last element of the list stored at key.
+func (d RedisDao) ListPop(key string, side ListSide) (ListDto, error) {
+	dto := ListDto{Key: key}
+
+	redisKey := d.key(key)
+
+	val, err := d.runQuotaScript(listPopScript, redisKey, string(side)).Text()
+	if err == redis.Nil {
+		return dto, nil
+	}
+
+	dto.Exists = true
+	dto.Values = []string{val}
+	return dto, wrapError(err)
+}
+
+// ListRange fetches the elements of the list stored at key between start and stop offsets.
+func (d RedisDao) ListRange(key string, start int64, stop int64) (ListDto, error) {
+	dto := ListDto{Key: key}
+
+	redisKey := d.key(key)
+
+	values, err := d.Db.LRange(context.Background(), redisKey, start, stop).Result()
+	if err != nil {
+		return dto, wrapError(err)
+	}
+
+	dto.Exists = true
+	dto.Values = values
+	return dto, nil
+}
+
+// ListTrim trims the list stored at key so that it will contain
+// only the elements between start and stop offsets.
+func (d RedisDao) ListTrim(key string, start int64, stop int64) error {
+	redisKey := d.key(key)
+
+	err := d.runQuotaScript(listTrimScript, redisKey, start, stop).Err()
+	return wrapError(err)
+}
+
+// SetAdd adds the members to the set stored at key.
+// It returns the number of the members which were not already in the set.
+func (d RedisDao) SetAdd(key string, members []string) (int64, error) {
+	if d.Keyring != nil {
+		return 0, ErrEncryptionUnsupported
+	}
+
+	redisKey := d.key(key)
+
+	added, err := d.runQuotaScript(setAddScript, redisKey, toInterfaces(members)...).Int64()
+	return added, wrapError(err)
+}
+
+// SetRemove removes the members from the set stored at key.
+// It returns the number of the members which were in the set.
+func (d RedisDao) SetRemove(key string, members []string) (int64, error) {
+	redisKey := d.key(key)
+
+	removed, err := d.runQuotaScript(setRemoveScript, redisKey, toInterfaces(members)...).Int64()
+	return removed, wrapError(err)
+}
+
+// SetMembers fetches all the members of the set stored at key.
+func (d RedisDao) SetMembers(key string) (SetDto, error) {
+	dto := SetDto{Key: key}
+
+	redisKey := d.key(key)
+
+	members, err := d.Db.SMembers(context.Background(), redisKey).Result()
+	if err != nil {
+		return dto, wrapError(err)
+	}
+
+	// Redis replies an empty set for non-existing keys.
+	dto.Exists = len(members) > 0
+	dto.Members = members
+	return dto, nil
+}
+
+// SetIsMember checks whether the member is in the set stored at key.
+func (d RedisDao) SetIsMember(key string, member string) (bool, error) {
+	redisKey := d.key(key)
+
+	isMember, err := d.Db.SIsMember(context.Background(), redisKey, member).Result()
+	return isMember, wrapError(err)
+}
+
+// LeaderboardAdd sets the score of the member in the sorted set stored at key.
+// If increment is true, the score is added to the current score of the member.
+// It returns the entry of the member after the operation.
+func (d RedisDao) LeaderboardAdd(key string, member string, score float64, increment bool) (LeaderboardEntry, error) {
+	entry := LeaderboardEntry{Member: member}
+	if d.Keyring != nil {
+		return entry, ErrEncryptionUnsupported
+	}
+
+	redisKey := d.key(key)
+
+	incr := "0"
+	if increment {
+		incr = "1"
+	}
+
+	reply, err := d.runQuotaScript(leaderboardAddScript, redisKey, member, formatScore(score), incr).Slice()
+	if err != nil {
+		return entry, wrapError(err)
+	}
+
+	if len(reply) != 2 {
+		return entry, fmt.Errorf("unexpected reply of the leaderboard script: %v", reply)
+	}
+
+	entry.Score, err = strconv.ParseFloat(fmt.Sprint(reply[0]), 64)
+	if err != nil {
+		return entry, err
+	}
+
+	rank, ok := reply[1].(int64)
+	if !ok {
+		return entry, fmt.Errorf("unexpected rank replied by the leaderboard script: %v", reply[1])
+	}
+
+	entry.Rank = rank + 1
+	return entry, nil
+}
+
+// LeaderboardMember fetches the score and the rank of the member in the sorted set stored at key.
+func (d RedisDao) LeaderboardMember(key string, member string) (LeaderboardDto, error) {
+	dto := LeaderboardDto{Key: key}
+
+	redisKey := d.key(key)
+
+	var scoreCmd *redis.FloatCmd
+	var rankCmd *redis.IntCmd
+	var totalCmd *redis.IntCmd
+	_, err := d.Db.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		scoreCmd = pipe.ZScore(context.Background(), redisKey, member)
+		rankCmd = pipe.ZRevRank(context.Background(), redisKey, member)
+		totalCmd = pipe.ZCard(context.Background(), redisKey)
+		return nil
+	})
+	if err == redis.Nil {
+		return dto, nil
+	}
+
+	if err != nil {
+		return dto, wrapError(err)
+	}
+
+	dto.Exists = true
+	dto.Total = totalCmd.Val()
+	dto.Entries = []LeaderboardEntry{{
+		Member: member,
+		Score:  scoreCmd.Val(),
+		Rank:   rankCmd.Val() + 1,
+	}}
+	return dto, nil
+}
+
+// LeaderboardRange fetches the members of the sorted set stored at key
+// between start and stop ranks, ordered from the highest score to the lowest.
+// The ranks are zero based as in Redis.
+func (d RedisDao) LeaderboardRange(key string, start int64, stop int64) (LeaderboardDto, error) {
+	dto := LeaderboardDto{Key: key}
+
+	redisKey := d.key(key)
+
+	var rangeCmd *redis.ZSliceCmd
+	var totalCmd *redis.IntCmd
+	_, err := d.Db.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		rangeCmd = pipe.ZRevRangeWithScores(context.Background(), redisKey, start, stop)
+		totalCmd = pipe.ZCard(context.Background(), redisKey)
+		return nil
+	})
+	if err != nil {
+		return dto, wrapError(err)
+	}
+
+	dto.Total = totalCmd.Val()
+	dto.Exists = dto.Total > 0
+	dto.Entries = toLeaderboardEntries(rangeCmd.Val(), start+1)
+	return dto, nil
+}
+
+// LeaderboardRangeByScore fetches the members of the sorted set stored at key
+// whose scores are between min and max, ordered from the highest score to the lowest.
+// Offset and count are used for paginating the members in the range.
+func (d RedisDao) LeaderboardRangeByScore(key string, min float64, max float64, offset int64, count int64) (LeaderboardDto, error) {
+	dto := LeaderboardDto{Key: key}
+
+	redisKey := d.key(key)
+
+	var rangeCmd *redis.ZSliceCmd
+	var aboveCmd *redis.IntCmd
+	var totalCmd *redis.IntCmd
+	_, err := d.Db.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		rangeCmd = pipe.ZRevRangeByScoreWithScores(context.Background(), redisKey, &redis.ZRangeBy{
+			Min:    formatScore(min),
+			Max:    formatScore(max),
+			Offset: offset,
+			Count:  count,
+		})
+		// the members having a score greater than max are ranked before the range.
+		aboveCmd = pipe.ZCount(context.Background(), redisKey, "("+formatScore(max), "+inf")
+		totalCmd = pipe.ZCard(context.Background(), redisKey)
+		return nil
+	})
+	if err != nil {
+		return dto, wrapError(err)
+	}
+
+	dto.Total = totalCmd.Val()
+	dto.Exists = dto.Total > 0
+	dto.Entries = toLeaderboardEntries(rangeCmd.Val(), aboveCmd.Val()+offset+1)
+	return dto, nil
+}
+
+// toLeaderboardEntries converts the members of a sorted set
+// to leaderboard entries ranked starting from firstRank.
+func toLeaderboardEntries(members []redis.Z, firstRank int64) []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, 0, len(members))
+	for i, member := range members {
+		entries = append(entries, LeaderboardEntry{
+			Member: fmt.Sprint(member.Member),
+			Score:  member.Score,
+			Rank:   firstRank + int64(i),
+		})
+	}
+
+	return entries
+}
+
+// formatScore formats the score in a way that Redis accepts as a range boundary.
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, 1):
+		return "+inf"
+	case math.IsInf(score, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(score, 'f', -1, 64)
+	}
+}
+
+// ScanKeys iterates over the keys of the namespaces matching the pattern by using SCAN command.
+// The keys which are not keys of a namespace, e.g. the usage hashes of the namespaces, are omitted
+// since they are maintained by the dao. It returns a batch of the keys and the cursor of the next batch,
+// the iteration is completed when the returned cursor is zero.
+func (d RedisDao) ScanKeys(pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+	keys, next, err := d.Db.Scan(context.Background(), cursor, pattern, count).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	namespaceKeys := keys[:0]
+	for _, key := range keys {
+		if _, ok := namespaceOfKey(key); ok {
+			namespaceKeys = append(namespaceKeys, key)
+		}
+	}
+
+	return namespaceKeys, next, nil
+}
+
+// DumpEntries fetches the types, the values and the TTLs of the keys in two pipelines.
+// The keys which do not exist anymore and the keys of unsupported types are omitted.
+// A key expired between the pipelines is replied as an empty collection,
+// which is omitted as well since it cannot be imported.
+func (d RedisDao) DumpEntries(keys []string) ([]SnapshotEntry, error) {
+	ctx := context.Background()
+
+	typeCmds := make([]*redis.StatusCmd, len(keys))
+	ttlCmds := make([]*redis.DurationCmd, len(keys))
+	_, err := d.Db.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			typeCmds[i] = pipe.Type(ctx, key)
+			ttlCmds[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	valueCmds := make([]redis.Cmder, len(keys))
+	_, err = d.Db.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			switch typeCmds[i].Val() {
+			case TypeString:
+				valueCmds[i] = pipe.Get(ctx, key)
+			case TypeHash:
+				valueCmds[i] = pipe.HGetAll(ctx, key)
+			case TypeList:
+				valueCmds[i] = pipe.LRange(ctx, key, 0, -1)
+			case TypeSet:
+				valueCmds[i] = pipe.SMembers(ctx, key)
+			case TypeSortedSet:
+				valueCmds[i] = pipe.ZRangeWithScores(ctx, key, 0, -1)
+			}
+		}
+		return nil
+	})
+	// a key may expire between the pipelines, GET replies nil for it.
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	entries := make([]SnapshotEntry, 0, len(keys))
+	for i, key := range keys {
+		entry := SnapshotEntry{Key: key, Type: typeCmds[i].Val()}
+		if ttl := ttlCmds[i].Val(); ttl > 0 {
+			entry.TTLMillis = ttl.Milliseconds()
+		}
+
+		switch cmd := valueCmds[i].(type) {
+		case *redis.StringCmd:
+			if cmd.Err() == redis.Nil {
+				continue
+			}
+			entry.Value = cmd.Val()
+		case *redis.StringStringMapCmd:
+			if len(cmd.Val()) == 0 {
+				continue
+			}
+			entry.Fields = cmd.Val()
+		case *redis.StringSliceCmd:
+			if len(cmd.Val()) == 0 {
+				continue
+			}
+			entry.Elements = cmd.Val()
+		case *redis.ZSliceCmd:
+			if len(cmd.Val()) == 0 {
+				continue
+			}
+			for _, z := range cmd.Val() {
+				entry.Members = append(entry.Members, ScoredMember{Member: fmt.Sprint(z.Member), Score: z.Score})
+			}
+		default:
+			if entry.Type != "none" {
+				log.Printf("Skipping %v key of unsupported type %v", key, entry.Type)
+			}
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// RestoreEntries writes the entries with their TTLs by restoreScript, so that the existing keys are checked
+// and the entries are written atomically. The existing keys are skipped, overwritten or cause ErrConflict
+// depending on the policy. The entries are counted by the usage of their namespaces, but they are not limited
+// by the quotas so that an archive is either imported entirely or not imported. The entries which are not keys
+// of a namespace, e.g. the usage hashes in the archives of the older versions, are skipped so that the usage
+// is counted by the restored entries rather than overwritten. It returns the number of the written and the skipped entries.
+func (d RedisDao) RestoreEntries(entries []SnapshotEntry, policy ConflictPolicy) (int64, int64, error) {
+	var ignored int64
+	keys := make([]string, 0, 2*len(entries))
+	args := []interface{}{string(policy)}
+	for _, entry := range entries {
+		usage := usageKeyOf(entry.Key)
+		if usage == "" {
+			log.Printf("Skipped restoring %v since it is not a key of a namespace.", entry.Key)
+			ignored++
+			continue
+		}
+
+		if d.Keyring != nil && entry.Type != TypeString {
+			log.Printf("Skipped restoring %v since the %v values cannot be encrypted.", entry.Key, entry.Type)
+			ignored++
+			continue
+		}
+		keys = append(keys, entry.Key, usage)
+
+		var items []interface{}
+		switch entry.Type {
+		case TypeString:
+			items = []interface{}{entry.Value}
+		case TypeHash:
+			for field, value := range entry.Fields {
+				items = append(items, field, value)
+			}
+		case TypeList, TypeSet:
+			items = toInterfaces(entry.Elements)
+		case TypeSortedSet:
+			for _, member := range entry.Members {
+				items = append(items, formatScore(member.Score), member.Member)
+			}
+		}
+
+		args = append(args, entry.Type, entry.TTLMillis, len(items))
+		args = append(args, items...)
+	}
+
+	if len(keys) == 0 {
+		return 0, ignored, nil
+	}
+
+	reply, err := restoreScript.Run(context.Background(), d.Db, keys, args...).Int64Slice()
+	if err != nil && strings.HasPrefix(err.Error(), "CONFLICT ") {
+		return 0, 0, fmt.Errorf("%w: %v", ErrConflict, strings.TrimPrefix(err.Error(), "CONFLICT "))
+	}
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(reply) != 2 {
+		return 0, 0, fmt.Errorf("unexpected reply of the restore script: %v", reply)
+	}
+
+	return reply[0], reply[1] + ignored, nil
+}
+
+func toInterfaces(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+
+	return args
+}
+
+// NamespaceKeys iterates over the keys of the namespace by using SCAN command.
+// It returns a batch of the keys without the namespace prefix and the cursor of the next batch,
+// the iteration is completed when the returned cursor is zero.
+func (d RedisDao) NamespaceKeys(cursor uint64, count int64) (NamespaceDto, error) {
+	dto := NamespaceDto{Namespace: d.Namespace}
+
+	keys, next, err := d.Db.Scan(context.Background(), cursor, d.namespacePattern(), count).Result()
+	if err != nil {
+		return dto, err
+	}
+
+	dto.Keys = d.namespaceKeys(keys)
+	dto.Cursor = next
+	return dto, nil
+}
+
+// FlushNamespace removes all the keys of the namespace.
+// The removed keys are subtracted from the usage, then the usage is reconciled by the keys
+// written during the iteration, e.g. to discount the keys expired by a TTL.
+// It returns the number of the removed keys.
+func (d RedisDao) FlushNamespace() (NamespaceDto, error) {
+	dto := NamespaceDto{Namespace: d.Namespace}
+
+	err := d.scanNamespace(func(keys []string) error {
+		removed, err := flushScript.Run(context.Background(), d.Db, append([]string{usageKey(d.Namespace)}, keys...)).Int64()
+		dto.KeyCount += removed
+		return err
+	})
+	if err != nil {
+		return dto, err
+	}
+
+	_, _, err = d.reconcileUsage()
+	return dto, err
+}
+
+// QuotaUsage returns the number of the keys of the namespace
+// and the bytes stored by them with the quota of the namespace.
+func (d RedisDao) QuotaUsage() (NamespaceDto, error) {
+	dto := NamespaceDto{Namespace: d.Namespace, Quota: d.Quota}
+
+	usage, err := d.Db.HMGet(context.Background(), usageKey(d.Namespace), "keys", "bytes").Result()
+	if err != nil {
+		return dto, err
+	}
+
+	dto.KeyCount, err = parseUsage(usage[0])
+	if err != nil {
+		return dto, err
+	}
+
+	dto.Bytes, err = parseUsage(usage[1])
+	return dto, err
+}
+
+// parseUsage parses a field of the usage hash, a missing field means zero usage.
+func parseUsage(field interface{}) (int64, error) {
+	if field == nil {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(fmt.Sprint(field), 10, 64)
+}
+
+// NamespaceUsage counts the keys of the namespace and the memory used by them.
+// The usage counted by the quota is reconciled by the sizes of the keys as well since the keys
+// expired by a TTL are not subtracted from it.
+func (d RedisDao) NamespaceUsage() (NamespaceDto, error) {
+	dto := NamespaceDto{Namespace: d.Namespace}
+
+	err := d.scanNamespace(func(keys []string) error {
+		cmds := make([]*redis.IntCmd, len(keys))
+		_, err := d.Db.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+			for i, key := range keys {
+				cmds[i] = pipe.MemoryUsage(context.Background(), key)
+			}
+			return nil
+		})
+		if err != nil && err != redis.Nil {
+			return err
+		}
+
+		// the keys expired during the iteration are not counted.
+		for _, cmd := range cmds {
+			if cmd.Err() == nil {
+				dto.KeyCount++
+				dto.Bytes += cmd.Val()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return dto, err
+	}
+
+	_, _, err = d.reconcileUsage()
 	return dto, err
 }
 
-func (d RedisDao) Set(dto Dto) error {
-	err := d.Db.Set(context.Background(), dto.Key, dto.Value, 0).Err()
+// reconcileUsage sets the usage of the namespace to the number of its keys and their sizes counted by the quota.
+// The keys are counted by a script in a single step, which blocks the database while the namespace is iterated,
+// so that the writes performed meanwhile are not lost. It returns the reconciled number of the keys and bytes.
+func (d RedisDao) reconcileUsage() (int64, int64, error) {
+	usage, err := reconcileScript.Run(context.Background(), d.Db, []string{usageKey(d.Namespace)}, d.namespacePattern()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(usage) != 2 {
+		return 0, 0, fmt.Errorf("unexpected reply of the reconcile script: %v", usage)
+	}
+
+	return usage[0], usage[1], nil
+}
+
+// scanNamespace calls fn with the batches of the keys of the namespace until all keys are iterated.
+// The keys are given as they are stored in the database.
+func (d RedisDao) scanNamespace(fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := d.Db.Scan(context.Background(), cursor, d.namespacePattern(), 100).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// namespacePattern returns the pattern matching the keys of the namespace.
+// The names of the namespaces do not contain the special characters of the patterns.
+func (d RedisDao) namespacePattern() string {
+	return d.keyPrefix() + "*"
+}
+
+// namespaceKeys strips the namespace prefix of the keys.
+func (d RedisDao) namespaceKeys(keys []string) []string {
+	namespaceKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		namespaceKeys = append(namespaceKeys, strings.TrimPrefix(key, d.keyPrefix()))
+	}
+
+	return namespaceKeys
+}
+
+// MigrateDefaultNamespace moves the keys of the default namespace stored without a prefix,
+// before the default namespace had a prefix of its own, under the prefix of the default namespace.
+// The keys of the namespaces and the keys starting with the reserved prefixes, which belong
+// to the other components sharing the database, are not moved. A key is not moved if it
+// already exists in the default namespace. It returns the number of the moved keys.
+func (d RedisDao) MigrateDefaultNamespace(ctx context.Context, reserved []string) (int64, error) {
+	var moved int64
+	var cursor uint64
+	for {
+		keys, next, err := d.Db.Scan(ctx, cursor, "*", 100).Result()
+		if err != nil {
+			return moved, err
+		}
+
+		for _, key := range keys {
+			if strings.HasPrefix(key, namespacePrefix) || hasAnyPrefix(key, reserved) {
+				continue
+			}
+
+			// the keys expired during the iteration are replied "no such key".
+			ok, err := d.Db.RenameNX(ctx, key, defaultNamespacePrefix+key).Result()
+			if err != nil && !strings.Contains(err.Error(), "no such key") {
+				return moved, fmt.Errorf("error on moving %v: %w", key, err)
+			}
+
+			if !ok {
+				log.Printf("Skipping %v since it already exists in the default namespace or it does not exist anymore", key)
+				continue
+			}
+			moved++
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return moved, nil
+		}
+	}
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Schemas fetches the JSON Schemas of the values by their key prefixes.
+func (d RedisDao) Schemas() (map[string]string, error) {
+	return d.Db.HGetAll(context.Background(), schemasKey).Result()
+}
+
+// SchemasVersion fetches the version of the JSON Schemas, which is zero if no schema is ever set.
+func (d RedisDao) SchemasVersion() (int64, error) {
+	version, err := d.Db.Get(context.Background(), schemasVersionKey).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// SetSchema sets the JSON Schema of the values whose keys start with the prefix.
+func (d RedisDao) SetSchema(prefix string, schema string) error {
+	_, err := d.Db.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		pipe.HSet(context.Background(), schemasKey, prefix, schema)
+		pipe.Incr(context.Background(), schemasVersionKey)
+		return nil
+	})
 	return err
 }
+
+// DeleteSchema removes the JSON Schema of the prefix. It returns false if the prefix has no schema.
+func (d RedisDao) DeleteSchema(prefix string) (bool, error) {
+	var removed *redis.IntCmd
+	_, err := d.Db.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		removed = pipe.HDel(context.Background(), schemasKey, prefix)
+		pipe.Incr(context.Background(), schemasVersionKey)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return removed.Val() > 0, nil
+}
@@ -0,0 +1,553 @@
+package inmem
+
+import (
+	"context"
+	"errors"
+	"bytes"
+	"github.com/alicebob/miniredis/v2"
+	redisserver "github.com/alicebob/miniredis/v2/server"
+	"github.com/go-redis/redis/v8"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// newTestDao returns a dao accessing an in-memory Redis server which is closed when the test ends.
+func newTestDao(t *testing.T) (RedisDao, *miniredis.Miniredis) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	db := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = db.Close() })
+	return RedisDao{Db: db}, server
+}
+
+// setOtherComponentKeys writes the keys of the components sharing the database with the in-memory keys.
+func setOtherComponentKeys(t *testing.T, server *miniredis.Miniredis) []string {
+	keys := []string{"queue:{jobs}:ready", "records:cache:generation", "records:cache:1:filter", "ratelimit:apikey:1"}
+	for _, key := range keys {
+		if err := server.Set(key, "1"); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	return keys
+}
+
+func TestRedisDao_FlushNamespaceDefault(t *testing.T) {
+	dao, server := newTestDao(t)
+	otherKeys := setOtherComponentKeys(t, server)
+
+	for _, d := range []RedisDao{dao, dao.WithNamespace("team-a")} {
+		if _, err := d.Set(Dto{Key: "active-tabs", Value: "3"}); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	got, err := dao.FlushNamespace()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if got.KeyCount != 1 {
+		t.Errorf("returned incorrect number of the removed keys. got: %v, expected: %v", got.KeyCount, 1)
+	}
+
+	for _, key := range append(otherKeys, "ns:team-a:active-tabs") {
+		if !server.Exists(key) {
+			t.Errorf("removed the key of another namespace or component. got: %v removed, expected: %v kept", key, key)
+		}
+	}
+
+	if keyCount, bytes := usageOf(t, dao); keyCount != 0 || bytes != 0 {
+		t.Errorf("returned incorrect usage of the flushed namespace. got: %v keys %v bytes, expected: %v keys %v bytes", keyCount, bytes, 0, 0)
+	}
+
+	if keyCount, bytes := usageOf(t, dao.WithNamespace("team-a")); keyCount != 1 || bytes != 1 {
+		t.Errorf("returned incorrect usage of another namespace. got: %v keys %v bytes, expected: %v keys %v bytes", keyCount, bytes, 1, 1)
+	}
+}
+
+func TestRedisDao_NamespaceKeysDefault(t *testing.T) {
+	dao, server := newTestDao(t)
+	setOtherComponentKeys(t, server)
+
+	for _, key := range []string{"active-tabs", "config/team-a"} {
+		if _, err := dao.Set(Dto{Key: key, Value: "3"}); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	got, err := dao.NamespaceKeys(0, 100)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	sort.Strings(got.Keys)
+	expected := []string{"active-tabs", "config/team-a"}
+	if !reflect.DeepEqual(got.Keys, expected) {
+		t.Errorf("returned incorrect keys. got: %v, expected: %v", got.Keys, expected)
+	}
+}
+
+func TestRedisDao_MigrateDefaultNamespace(t *testing.T) {
+	dao, server := newTestDao(t)
+	otherKeys := setOtherComponentKeys(t, server)
+
+	for key, value := range map[string]string{"active-tabs": "3", "conflict": "old", "ns::default:conflict": "new", "ns:team-a:active-tabs": "5"} {
+		if err := server.Set(key, value); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	moved, err := dao.MigrateDefaultNamespace(context.Background(), []string{"queue:", "records:cache:", "ratelimit:"})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if moved != 1 {
+		t.Errorf("returned incorrect number of the moved keys. got: %v, expected: %v", moved, 1)
+	}
+
+	got, err := dao.Get("active-tabs")
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if !got.Exists || got.Value != "3" {
+		t.Errorf("returned incorrect value of the moved key. got: %v, expected: %v", got.Value, "3")
+	}
+
+	for _, key := range append(otherKeys, "conflict", "ns:team-a:active-tabs") {
+		if !server.Exists(key) {
+			t.Errorf("moved a key which is not in the default namespace. got: %v moved, expected: %v kept", key, key)
+		}
+	}
+
+	if value, _ := server.Get("ns::default:conflict"); value != "new" {
+		t.Errorf("overwrote an existing key of the default namespace. got: %v, expected: %v", value, "new")
+	}
+}
+
+func TestRedisDao_Reencrypt(t *testing.T) {
+	dao, server := newTestDao(t)
+	otherKeys := setOtherComponentKeys(t, server)
+
+	oldKeyring, err := NewKeyring("old", map[string][]byte{"old": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	dao.Keyring = oldKeyring
+	if _, err = dao.Set(Dto{Key: "active-tabs", Value: "3"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if err = server.Set("ns::default:corrupted", envelopeMagic+"corrupted"); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if err = server.Set("ns::default:plain", "5"); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	oldBytes, _ := strconv.Atoi(server.HGet(usageKey(""), "bytes"))
+	oldSize := len(mustGet(t, server, "ns::default:active-tabs"))
+
+	dao.Keyring, err = NewKeyring("new", map[string][]byte{"old": make([]byte, 32), "new": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	got, err := dao.Reencrypt(context.Background())
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if got != 1 {
+		t.Errorf("returned incorrect number of the re-encrypted values. got: %v, expected: %v", got, 1)
+	}
+
+	stored := mustGet(t, server, "ns::default:active-tabs")
+	if keyId, _ := encryptionKeyId(stored); keyId != "new" {
+		t.Errorf("returned incorrect key id of the re-encrypted value. got: %v, expected: %v", keyId, "new")
+	}
+
+	newBytes, _ := strconv.Atoi(server.HGet(usageKey(""), "bytes"))
+	if expected := oldBytes + len(stored) - oldSize; newBytes != expected {
+		t.Errorf("returned incorrect usage bytes. got: %v, expected: %v", newBytes, expected)
+	}
+
+	for _, key := range otherKeys {
+		if value := mustGet(t, server, key); value != "1" {
+			t.Errorf("rewrote the key of another component. got: %v, expected: %v", value, "1")
+		}
+	}
+
+	if value := mustGet(t, server, "ns::default:plain"); value != "5" {
+		t.Errorf("rewrote the value without an envelope. got: %v, expected: %v", value, "5")
+	}
+}
+
+func TestRedisDao_EncryptionBindsKey(t *testing.T) {
+	dao, server := newTestDao(t)
+
+	var err error
+	dao.Keyring, err = NewKeyring("primary", map[string][]byte{"primary": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if _, err = dao.Set(Dto{Key: "secret", Value: "getir"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if err = server.Set("ns::default:copied", mustGet(t, server, "ns::default:secret")); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if _, err = dao.Get("copied"); err == nil {
+		t.Errorf("value copied to another key is decrypted")
+	}
+
+	dto, err := dao.Get("secret")
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if dto.Value != "getir" {
+		t.Errorf("returned incorrect value. got: %v, expected: %v", dto.Value, "getir")
+	}
+}
+
+func TestRedisDao_RefusesDataTypesWhileEncrypting(t *testing.T) {
+	dao, server := newTestDao(t)
+
+	var err error
+	dao.Keyring, err = NewKeyring("primary", map[string][]byte{"primary": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	errs := map[string]error{
+		"hash": dao.HashSet(HashDto{Key: "hash", Fields: map[string]string{"field": "value"}}),
+	}
+	_, errs["list"] = dao.ListPush("list", ListRight, []string{"value"})
+	_, errs["set"] = dao.SetAdd("set", []string{"value"})
+	_, errs["leaderboard"] = dao.LeaderboardAdd("leaderboard", "member", 1, false)
+
+	for dataType, err := range errs {
+		if !errors.Is(err, ErrEncryptionUnsupported) {
+			t.Errorf("returned incorrect error of %v. got: %v, expected: %v", dataType, err, ErrEncryptionUnsupported)
+		}
+	}
+
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Errorf("returned incorrect keys. got: %v, expected: %v", keys, []string{})
+	}
+}
+
+// mustGet returns the string value of the key in the in-memory Redis server.
+func mustGet(t *testing.T, server *miniredis.Miniredis, key string) string {
+	value, err := server.Get(key)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	return value
+}
+
+// registerMemoryUsage serves MEMORY USAGE, which the test server does not implement, replying 64 bytes for each key.
+func registerMemoryUsage(t *testing.T, server *miniredis.Miniredis) {
+	err := server.Server().Register("MEMORY", func(c *redisserver.Peer, cmd string, args []string) {
+		if len(args) < 2 || !server.Exists(args[1]) {
+			c.WriteNull()
+			return
+		}
+		c.WriteInt(64)
+	})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+}
+
+// usageOf returns the usage of the namespace counted by the quota scripts.
+func usageOf(t *testing.T, dao RedisDao) (int64, int64) {
+	dto, err := dao.QuotaUsage()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	return dto.KeyCount, dto.Bytes
+}
+
+func TestRedisDao_QuotaDataTypes(t *testing.T) {
+	dao, _ := newTestDao(t)
+	dao = dao.WithNamespace("team-a")
+
+	if err := dao.HashSet(HashDto{Key: "user:1", Field: "name", Value: "getir"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if _, err := dao.ListPush("jobs", ListRight, []string{"a", "bb"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if _, err := dao.SetAdd("tags", []string{"x", "x", "yy"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if _, err := dao.LeaderboardAdd("scores", "alice", 10, false); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	keyCount, bytes := usageOf(t, dao)
+	if keyCount != 4 || bytes != 9+3+3+13 {
+		t.Errorf("returned incorrect usage. got: %v keys %v bytes, expected: %v keys %v bytes", keyCount, bytes, 4, 28)
+	}
+
+	if _, err := dao.ListPop("jobs", ListLeft); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if _, err := dao.SetRemove("tags", []string{"x", "yy"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if _, err := dao.Delete("user:1"); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	keyCount, bytes = usageOf(t, dao)
+	if keyCount != 2 || bytes != 2+13 {
+		t.Errorf("returned incorrect usage. got: %v keys %v bytes, expected: %v keys %v bytes", keyCount, bytes, 2, 15)
+	}
+}
+
+func TestRedisDao_QuotaDataTypesExceeded(t *testing.T) {
+	dao, _ := newTestDao(t)
+	dao.Quota = Quota{MaxKeys: 1, MaxBytes: 10}
+
+	if _, err := dao.SetAdd("tags", []string{"abcde"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		write    func() error
+		expected error
+	}{
+		{"new key", func() error {
+			return dao.HashSet(HashDto{Key: "user:1", Field: "name", Value: "getir"})
+		}, ErrKeyQuotaExceeded},
+		{"too many bytes", func() error {
+			_, err := dao.SetAdd("tags", []string{"fghijk"})
+			return err
+		}, ErrByteQuotaExceeded},
+		{"wrong type", func() error {
+			_, err := dao.ListPush("tags", ListLeft, []string{"a"})
+			return err
+		}, ErrWrongType},
+	}
+
+	for _, test := range tests {
+		if err := test.write(); !errors.Is(err, test.expected) {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: %v", test.name, err, test.expected)
+		}
+	}
+}
+
+func TestRedisDao_LeaderboardAdd(t *testing.T) {
+	dao, _ := newTestDao(t)
+
+	for _, member := range []string{"alice", "bob"} {
+		if _, err := dao.LeaderboardAdd("scores", member, 10, false); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	got, err := dao.LeaderboardAdd("scores", "bob", 2.5, true)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	expected := LeaderboardEntry{Member: "bob", Score: 12.5, Rank: 1}
+	if got != expected {
+		t.Errorf("returned incorrect entry. got: %+v, expected: %+v", got, expected)
+	}
+}
+
+func TestRedisDao_ReconcileUsage(t *testing.T) {
+	dao, server := newTestDao(t)
+	otherKeys := setOtherComponentKeys(t, server)
+
+	if _, err := dao.Set(Dto{Key: "active-tabs", Value: "3"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	// the keys written or expired without the scripts are not counted by the usage.
+	server.HSet("ns::default:user:1", "name", "getir")
+	server.Del("ns::default:active-tabs")
+
+	keyCount, bytes, err := dao.reconcileUsage()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if keyCount != 1 || bytes != 9 {
+		t.Errorf("returned incorrect usage. got: %v keys %v bytes, expected: %v keys %v bytes", keyCount, bytes, 1, 9)
+	}
+
+	if keyCount, bytes = usageOf(t, dao); keyCount != 1 || bytes != 9 {
+		t.Errorf("returned incorrect reconciled usage. got: %v keys %v bytes, expected: %v keys %v bytes", keyCount, bytes, 1, 9)
+	}
+
+	for _, key := range otherKeys {
+		if !server.Exists(key) {
+			t.Errorf("removed the key of another component. got: %v removed, expected: not removed", key)
+		}
+	}
+}
+
+func TestRedisDao_RestoreEntries(t *testing.T) {
+	dao, server := newTestDao(t)
+	if _, err := dao.Set(Dto{Key: "active-tabs", Value: "3"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	entries := []SnapshotEntry{
+		{Key: "ns::default:active-tabs", Type: TypeString, Value: "5"},
+		{Key: "ns::default:binary", Type: TypeString, Value: "\xff\xfe", TTLMillis: 60000},
+		{Key: "ns::default:scores", Type: TypeSortedSet, Members: []ScoredMember{{Member: "alice", Score: 0.1}}},
+		{Key: "records:cache:generation", Type: TypeString, Value: "1"},
+	}
+
+	imported, skipped, err := dao.RestoreEntries(entries, ConflictSkip)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if imported != 2 || skipped != 2 {
+		t.Errorf("returned incorrect counts. got: %v imported %v skipped, expected: %v imported %v skipped", imported, skipped, 2, 2)
+	}
+
+	// the keys outside the namespaces are not restored.
+	if server.Exists("records:cache:generation") {
+		t.Errorf("restored a key outside the namespaces. got: %v restored, expected: not restored", "records:cache:generation")
+	}
+
+	if value := mustGet(t, server, "ns::default:binary"); value != "\xff\xfe" || server.TTL("ns::default:binary") == 0 {
+		t.Errorf("restored incorrect value. got: %q with TTL %v, expected: %q with TTL", value, server.TTL("ns::default:binary"), "\xff\xfe")
+	}
+
+	if score, _ := server.ZScore("ns::default:scores", "alice"); score != 0.1 {
+		t.Errorf("restored incorrect score. got: %v, expected: %v", score, 0.1)
+	}
+
+	keyCount, bytes := usageOf(t, dao)
+	expectedBytes := int64(len(mustGet(t, server, "ns::default:active-tabs")) + 2 + 5 + 8)
+	if keyCount != 3 || bytes != expectedBytes {
+		t.Errorf("returned incorrect usage. got: %v keys %v bytes, expected: %v keys %v bytes", keyCount, bytes, 3, expectedBytes)
+	}
+}
+
+func TestRedisDao_RestoreEntriesConflict(t *testing.T) {
+	dao, server := newTestDao(t)
+	if err := server.Set("ns::default:active-tabs", "3"); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	entries := []SnapshotEntry{
+		{Key: "ns::default:tags", Type: TypeSet, Elements: []string{"a"}},
+		{Key: "ns::default:active-tabs", Type: TypeString, Value: "5"},
+	}
+
+	_, _, err := dao.RestoreEntries(entries, ConflictFail)
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrConflict)
+	}
+
+	if server.Exists("ns::default:tags") {
+		t.Errorf("restored an entry of the batch containing a conflict. got: %v restored, expected: not restored", "ns::default:tags")
+	}
+
+	if value := mustGet(t, server, "ns::default:active-tabs"); value != "3" {
+		t.Errorf("overwrote an existing key. got: %v, expected: %v", value, "3")
+	}
+}
+
+func TestRedisDao_SchemasVersion(t *testing.T) {
+	dao, _ := newTestDao(t)
+
+	if err := dao.SetSchema("user:", `{"type":"object"}`); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if _, err := dao.DeleteSchema("user:"); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	got, err := dao.SchemasVersion()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if got != 2 {
+		t.Errorf("returned incorrect version. got: %v, expected: %v", got, 2)
+	}
+}
+
+// TestSnapshotService_ExportImportUsage checks that the usage of the namespaces is not changed by
+// exporting the keyspace and importing it back, since the usage hashes and the JSON Schemas are not exported.
+func TestSnapshotService_ExportImportUsage(t *testing.T) {
+	dao, server := newTestDao(t)
+	registerMemoryUsage(t, server)
+	teamDao := dao.WithNamespace("team-a")
+
+	if _, err := dao.Set(Dto{Key: "active-tabs", Value: "3"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if err := teamDao.HashSet(HashDto{Key: "user:1", Field: "name", Value: "getir"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if _, err := teamDao.SetAdd("tags", []string{"x", "yy"}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	if err := dao.SetSchema("config/", `{"type": "object"}`); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	service := SnapshotService{Dao: dao}
+	var archive bytes.Buffer
+	exported, err := service.Export("", &archive)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if exported != 3 {
+		t.Errorf("returned incorrect number of exported keys. got: %v, expected: %v, archive: %v", exported, 3, archive.String())
+	}
+
+	for _, namespaceDao := range []RedisDao{dao, teamDao} {
+		expected, err := namespaceDao.NamespaceUsage()
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+		expectedKeys, expectedBytes := usageOf(t, namespaceDao)
+
+		if _, err = service.Import(bytes.NewReader(archive.Bytes()), ConflictOverwrite); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		got, err := namespaceDao.NamespaceUsage()
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if got.KeyCount != expected.KeyCount || got.Bytes != expected.Bytes {
+			t.Errorf("returned incorrect usage of %q. got: %v keys %v bytes, expected: %v keys %v bytes",
+				namespaceDao.Namespace, got.KeyCount, got.Bytes, expected.KeyCount, expected.Bytes)
+		}
+
+		if keyCount, bytes := usageOf(t, namespaceDao); keyCount != expectedKeys || bytes != expectedBytes {
+			t.Errorf("returned incorrect quota usage of %q. got: %v keys %v bytes, expected: %v keys %v bytes",
+				namespaceDao.Namespace, keyCount, bytes, expectedKeys, expectedBytes)
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package inmem
+
+import "github.com/go-redis/redis/v8"
+
+// sizePrelude defines sizeOf returning the size of a key counted by the usage of the namespaces.
+// The size of a key is the length of its string value, the lengths of the fields and the values of its hash,
+// the lengths of the elements of its list or set, or the lengths of the members of its sorted set plus 8 bytes
+// for each score.
+const sizePrelude = `
+local function lengthOf(values, extra)
+	local size = 0
+	for _, value in ipairs(values) do
+		size = size + string.len(value) + extra
+	end
+	return size
+end
+
+local function sizeOf(key)
+	local t = redis.call('TYPE', key).ok
+	if t == 'string' then
+		return redis.call('STRLEN', key)
+	elseif t == 'hash' then
+		return lengthOf(redis.call('HGETALL', key), 0)
+	elseif t == 'list' then
+		return lengthOf(redis.call('LRANGE', key, 0, -1), 0)
+	elseif t == 'set' then
+		return lengthOf(redis.call('SMEMBERS', key), 0)
+	elseif t == 'zset' then
+		return lengthOf(redis.call('ZRANGE', key, 0, -1), 8)
+	end
+	return 0
+end
+`
+
+// quotaPrelude is prepended to the scripts writing the keys of the namespaces so that every write
+// is counted by the usage of the namespace of the key and limited by its quota.
+// The scripts take the key at KEYS[1], the usage hash of its namespace at KEYS[2],
+// the key and the byte quotas at ARGV[1] and ARGV[2] and their own arguments after them.
+// The type of the key is checked explicitly since the errors raised by the commands
+// called in a script are not replied as they are. The errors are replied as error tables
+// rather than by redis.error_reply which may prefix them by "ERR", e.g. in the test servers.
+const quotaPrelude = sizePrelude + `
+local maxKeys = tonumber(ARGV[1])
+local maxBytes = tonumber(ARGV[2])
+
+local function checkType(expected)
+	local t = redis.call('TYPE', KEYS[1]).ok
+	if t ~= 'none' and t ~= expected then
+		return {err = 'WRONGTYPE Operation against a key holding the wrong kind of value'}
+	end
+end
+
+local function checkQuota(added, delta)
+	if maxKeys > 0 and added > 0 and tonumber(redis.call('HGET', KEYS[2], 'keys') or '0') + added > maxKeys then
+		return {err = 'KEYQUOTA the key quota of the namespace is exceeded'}
+	end
+
+	if maxBytes > 0 and delta > 0 and tonumber(redis.call('HGET', KEYS[2], 'bytes') or '0') + delta > maxBytes then
+		return {err = 'BYTEQUOTA the byte quota of the namespace is exceeded'}
+	end
+end
+
+local function account(added, delta)
+	if added ~= 0 then
+		redis.call('HINCRBY', KEYS[2], 'keys', added)
+	end
+
+	if delta ~= 0 then
+		redis.call('HINCRBY', KEYS[2], 'bytes', delta)
+	end
+end
+`
+
+// newQuotaScript creates a script running after quotaPrelude.
+func newQuotaScript(src string) *redis.Script {
+	return redis.NewScript(quotaPrelude + src)
+}
+
+// setScript stores the string value at ARGV[3]. Overwriting a key of another
+// data type replaces its size by the size of the value.
+var setScript = newQuotaScript(`
+local added = 1 - redis.call('EXISTS', KEYS[1])
+local delta = string.len(ARGV[3]) - sizeOf(KEYS[1])
+local err = checkQuota(added, delta)
+if err then
+	return err
+end
+
+redis.call('SET', KEYS[1], ARGV[3])
+account(added, delta)
+return 1
+`)
+
+// deleteScript removes the key of any data type.
+var deleteScript = newQuotaScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+
+local size = sizeOf(KEYS[1])
+redis.call('DEL', KEYS[1])
+account(-1, -size)
+return 1
+`)
+
+// hashSetScript sets the field at ARGV[3] of the hash to the value at ARGV[4].
+var hashSetScript = newQuotaScript(`
+local err = checkType('hash')
+if err then
+	return err
+end
+
+local added = 1 - redis.call('EXISTS', KEYS[1])
+local delta = string.len(ARGV[4])
+local old = redis.call('HGET', KEYS[1], ARGV[3])
+if old then
+	delta = delta - string.len(old)
+else
+	delta = delta + string.len(ARGV[3])
+end
+
+err = checkQuota(added, delta)
+if err then
+	return err
+end
+
+redis.call('HSET', KEYS[1], ARGV[3], ARGV[4])
+account(added, delta)
+return 1
+`)
+
+// hashDeleteScript removes the field at ARGV[3] of the hash.
+var hashDeleteScript = newQuotaScript(`
+local err = checkType('hash')
+if err then
+	return err
+end
+
+local old = redis.call('HGET', KEYS[1], ARGV[3])
+if not old then
+	return 0
+end
+
+redis.call('HDEL', KEYS[1], ARGV[3])
+account(redis.call('EXISTS', KEYS[1]) - 1, -string.len(ARGV[3]) - string.len(old))
+return 1
+`)
+
+// listPushScript pushes the values after ARGV[3] to the side of the list at ARGV[3] one by one.
+var listPushScript = newQuotaScript(`
+local err = checkType('list')
+if err then
+	return err
+end
+
+local added = 1 - redis.call('EXISTS', KEYS[1])
+local delta = 0
+for i = 4, #ARGV do
+	delta = delta + string.len(ARGV[i])
+end
+
+err = checkQuota(added, delta)
+if err then
+	return err
+end
+
+local command = 'RPUSH'
+if ARGV[3] == 'left' then
+	command = 'LPUSH'
+end
+
+local length = 0
+for i = 4, #ARGV do
+	length = redis.call(command, KEYS[1], ARGV[i])
+end
+account(added, delta)
+return length
+`)
+
+// listPopScript pops an element from the side of the list at ARGV[3].
+var listPopScript = newQuotaScript(`
+local err = checkType('list')
+if err then
+	return err
+end
+
+local command = 'RPOP'
+if ARGV[3] == 'left' then
+	command = 'LPOP'
+end
+
+local value = redis.call(command, KEYS[1])
+if not value then
+	return false
+end
+
+account(redis.call('EXISTS', KEYS[1]) - 1, -string.len(value))
+return value
+`)
+
+// listTrimScript trims the list to the elements between the offsets at ARGV[3] and ARGV[4].
+var listTrimScript = newQuotaScript(`
+local err = checkType('list')
+if err then
+	return err
+end
+
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 1
+end
+
+local size = sizeOf(KEYS[1])
+redis.call('LTRIM', KEYS[1], ARGV[3], ARGV[4])
+account(redis.call('EXISTS', KEYS[1]) - 1, sizeOf(KEYS[1]) - size)
+return 1
+`)
+
+// setAddScript adds the members after ARGV[2] to the set.
+var setAddScript = newQuotaScript(`
+local err = checkType('set')
+if err then
+	return err
+end
+
+local added = 1 - redis.call('EXISTS', KEYS[1])
+local delta = 0
+local seen = {}
+for i = 3, #ARGV do
+	if not seen[ARGV[i]] and redis.call('SISMEMBER', KEYS[1], ARGV[i]) == 0 then
+		delta = delta + string.len(ARGV[i])
+	end
+	seen[ARGV[i]] = true
+end
+
+err = checkQuota(added, delta)
+if err then
+	return err
+end
+
+local count = 0
+for i = 3, #ARGV do
+	count = count + redis.call('SADD', KEYS[1], ARGV[i])
+end
+account(added, delta)
+return count
+`)
+
+// setRemoveScript removes the members after ARGV[2] from the set.
+var setRemoveScript = newQuotaScript(`
+local err = checkType('set')
+if err then
+	return err
+end
+
+local count = 0
+local delta = 0
+for i = 3, #ARGV do
+	if redis.call('SREM', KEYS[1], ARGV[i]) == 1 then
+		count = count + 1
+		delta = delta - string.len(ARGV[i])
+	end
+end
+
+if count > 0 then
+	account(redis.call('EXISTS', KEYS[1]) - 1, delta)
+end
+return count
+`)
+
+// leaderboardAddScript sets the score of the member at ARGV[3] in the sorted set to the score at ARGV[4],
+// or increments it by the score if ARGV[5] is 1. It returns the score and the zero based rank of the member.
+var leaderboardAddScript = newQuotaScript(`
+local err = checkType('zset')
+if err then
+	return err
+end
+
+local added = 1 - redis.call('EXISTS', KEYS[1])
+local delta = 0
+if not redis.call('ZSCORE', KEYS[1], ARGV[3]) then
+	delta = string.len(ARGV[3]) + 8
+end
+
+err = checkQuota(added, delta)
+if err then
+	return err
+end
+
+if ARGV[5] == '1' then
+	redis.call('ZINCRBY', KEYS[1], ARGV[4], ARGV[3])
+else
+	redis.call('ZADD', KEYS[1], ARGV[4], ARGV[3])
+end
+account(added, delta)
+return {redis.call('ZSCORE', KEYS[1], ARGV[3]), redis.call('ZREVRANK', KEYS[1], ARGV[3])}
+`)
+
+// reconcileScript counts the keys matching the pattern at ARGV[1] and their sizes, and sets them
+// to the usage hash at KEYS[1] in a single step so that the writes counted meanwhile are not lost.
+// It replies the number of the keys and their sizes.
+var reconcileScript = redis.NewScript(sizePrelude + `
+local keys = 0
+local bytes = 0
+local cursor = '0'
+repeat
+	local reply = redis.call('SCAN', cursor, 'MATCH', ARGV[1], 'COUNT', 100)
+	cursor = reply[1]
+	for _, key in ipairs(reply[2]) do
+		keys = keys + 1
+		bytes = bytes + sizeOf(key)
+	end
+until cursor == '0'
+
+redis.call('HSET', KEYS[1], 'keys', keys, 'bytes', bytes)
+return {keys, bytes}
+`)
+
+// flushScript removes the keys at KEYS after KEYS[1] and subtracts them from the usage hash at KEYS[1],
+// so that the keys written concurrently by the other scripts are still counted.
+// It replies the number of the removed keys.
+var flushScript = redis.NewScript(sizePrelude + `
+local removed = 0
+local bytes = 0
+for i = 2, #KEYS do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		bytes = bytes + sizeOf(KEYS[i])
+		redis.call('UNLINK', KEYS[i])
+		removed = removed + 1
+	end
+end
+
+if removed > 0 then
+	redis.call('HINCRBY', KEYS[1], 'keys', -removed)
+	redis.call('HINCRBY', KEYS[1], 'bytes', -bytes)
+end
+return removed
+`)
+
+// restoreScript writes the entries of a snapshot with their TTLs after checking the existing keys, in a single step
+// so that the keys created concurrently are not overwritten unless the conflict policy at ARGV[1] is overwrite.
+// The keys are given at KEYS in pairs of the key and the usage hash of its namespace. The entries are given after ARGV[1] as the type, the TTL in milliseconds,
+// the number of the items and the items of the value: the value of a string, the fields and the values
+// of a hash, the elements of a list or set, or the scores and the members of a sorted set.
+// It replies the number of the written and the skipped entries.
+var restoreScript = redis.NewScript(sizePrelude + `
+local policy = ARGV[1]
+
+if policy == 'fail' then
+	local seen = {}
+	for i = 1, #KEYS, 2 do
+		if seen[KEYS[i]] or redis.call('EXISTS', KEYS[i]) == 1 then
+			return {err = 'CONFLICT ' .. KEYS[i]}
+		end
+		seen[KEYS[i]] = true
+	end
+end
+
+local imported = 0
+local skipped = 0
+local arg = 2
+for i = 1, #KEYS, 2 do
+	local key = KEYS[i]
+	local usage = KEYS[i + 1]
+	local t = ARGV[arg]
+	local ttl = ARGV[arg + 1]
+	local first = arg + 3
+	local last = first + tonumber(ARGV[arg + 2]) - 1
+	arg = last + 1
+
+	local exists = redis.call('EXISTS', key) == 1
+	if exists and policy ~= 'overwrite' then
+		skipped = skipped + 1
+	else
+		local added = 1
+		local size = 0
+		if exists then
+			added = 0
+			size = sizeOf(key)
+			redis.call('DEL', key)
+		end
+
+		if t == 'string' then
+			redis.call('SET', key, ARGV[first])
+		elseif t == 'hash' then
+			for j = first, last, 2 do
+				redis.call('HSET', key, ARGV[j], ARGV[j + 1])
+			end
+		elseif t == 'list' then
+			for j = first, last do
+				redis.call('RPUSH', key, ARGV[j])
+			end
+		elseif t == 'set' then
+			for j = first, last do
+				redis.call('SADD', key, ARGV[j])
+			end
+		elseif t == 'zset' then
+			for j = first, last, 2 do
+				redis.call('ZADD', key, ARGV[j], ARGV[j + 1])
+			end
+		end
+
+		if tonumber(ttl) > 0 then
+			redis.call('PEXPIRE', key, ttl)
+		end
+
+		redis.call('HINCRBY', usage, 'keys', added)
+		redis.call('HINCRBY', usage, 'bytes', sizeOf(key) - size)
+		imported = imported + 1
+	end
+end
+
+return {imported, skipped}
+`)
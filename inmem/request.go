@@ -1,7 +1,57 @@
 package inmem
 
+import "encoding/json"
+
 // Request represents the request payload.
+// If Encoding is "base64", Value is decoded and stored as a binary value.
+// ContentType is optional and returned with the value on read.
 type Request struct{
 	Key *string `json:"key"`
 	Value *string `json:"value"`
+	Encoding string `json:"encoding"`
+	ContentType string `json:"contentType"`
+}
+
+// HashRequest represents the request payload to set a field of a hash.
+type HashRequest struct {
+	Key   *string `json:"key"`
+	Field *string `json:"field"`
+	Value *string `json:"value"`
+}
+
+// ListPushRequest represents the request payload to push values to a list.
+// Side is optional, the values are pushed to the tail of the list by default.
+type ListPushRequest struct {
+	Key    *string  `json:"key"`
+	Values []string `json:"values"`
+	Side   ListSide `json:"side"`
+}
+
+// ListTrimRequest represents the request payload to trim a list.
+type ListTrimRequest struct {
+	Key   *string `json:"key"`
+	Start *int64  `json:"start"`
+	Stop  *int64  `json:"stop"`
+}
+
+// SetRequest represents the request payload to add members to a set.
+type SetRequest struct {
+	Key     *string  `json:"key"`
+	Members []string `json:"members"`
+}
+
+// LeaderboardRequest represents the request payload to set the score of a member.
+// If Increment is true, Score is added to the current score of the member.
+type LeaderboardRequest struct {
+	Key       *string  `json:"key"`
+	Member    *string  `json:"member"`
+	Score     *float64 `json:"score"`
+	Increment bool     `json:"increment"`
+}
+
+// SchemaRequest represents the request payload to set the JSON Schema
+// of the values whose keys start with Prefix.
+type SchemaRequest struct {
+	Prefix *string          `json:"prefix"`
+	Schema *json.RawMessage `json:"schema"`
 }
@@ -1,8 +1,87 @@
 package inmem
 
+import (
+	"encoding/json"
+	"github.com/skarakasoglu/g-case-challenge/jsonschema"
+)
+
 // Response represents the response payload.
+// Encoding is "base64" if the value is base64 encoded binary value.
+// Violations lists the reasons why a value does not match the schema of its key.
 type Response struct{
 	Key string `json:"key"`
 	Value string `json:"value"`
+	Encoding string `json:"encoding,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	RawSize int `json:"rawSize,omitempty"`
+	StoredSize int `json:"storedSize,omitempty"`
 	Error string `json:"error,omitempty"`
+	Violations []jsonschema.Error `json:"violations,omitempty"`
+}
+
+// HashResponse represents the response payload of hash operations.
+type HashResponse struct {
+	Key    string            `json:"key"`
+	Field  string            `json:"field,omitempty"`
+	Value  string            `json:"value,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// ListResponse represents the response payload of list operations.
+type ListResponse struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values,omitempty"`
+	Length int64    `json:"length,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// SetResponse represents the response payload of set operations.
+// Count is the number of the members added or removed.
+type SetResponse struct {
+	Key      string   `json:"key"`
+	Members  []string `json:"members,omitempty"`
+	IsMember *bool    `json:"isMember,omitempty"`
+	Count    int64    `json:"count,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// LeaderboardResponse represents the response payload of leaderboard operations.
+type LeaderboardResponse struct {
+	Key     string             `json:"key"`
+	Entries []LeaderboardEntry `json:"entries,omitempty"`
+	Total   int64              `json:"total,omitempty"`
+	Error   string             `json:"error,omitempty"`
+}
+
+// SnapshotResponse represents the response payload of snapshot operations.
+type SnapshotResponse struct {
+	Imported int64  `json:"imported"`
+	Skipped  int64  `json:"skipped"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NamespaceResponse represents the response payload of namespace operations.
+// Namespace is empty for the default namespace. Cursor is the cursor of the next
+// batch of the keys, the iteration is completed when it is zero.
+// MaxKeys and MaxBytes are the limits of the quota of the namespace.
+type NamespaceResponse struct {
+	Namespace string   `json:"namespace"`
+	Keys      []string `json:"keys,omitempty"`
+	Cursor    *uint64  `json:"cursor,omitempty"`
+	KeyCount  *int64   `json:"keyCount,omitempty"`
+	Bytes     *int64   `json:"bytes,omitempty"`
+	MaxKeys   *int64   `json:"maxKeys,omitempty"`
+	MaxBytes  *int64   `json:"maxBytes,omitempty"`
+	Removed   *int64   `json:"removed,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// SchemaResponse represents the response payload of schema operations.
+// Schemas holds the schemas by their key prefixes.
+type SchemaResponse struct {
+	Prefix  string                     `json:"prefix,omitempty"`
+	Schema  json.RawMessage            `json:"schema,omitempty"`
+	Schemas map[string]json.RawMessage `json:"schemas,omitempty"`
+	Error   string                     `json:"error,omitempty"`
 }
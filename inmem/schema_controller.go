@@ -0,0 +1,84 @@
+package inmem
+
+import (
+	"log"
+	"net/http"
+)
+
+// SchemaRepository interface is used by a SchemaController
+// to manage the JSON Schemas of the values by their key prefixes.
+type SchemaRepository interface {
+	List() (SchemaResponse, error)
+	Put(prefix string, schema []byte) (SchemaResponse, error)
+	Delete(prefix string) (SchemaResponse, error)
+}
+
+// maxSchemaRequestSize is the size of the largest request setting a schema.
+const maxSchemaRequestSize = 1024 * 1024
+
+// SchemaController is a handler for handling
+// requests coming to "/admin/in-memory/schemas" endpoint.
+type SchemaController struct {
+	Repository SchemaRepository
+}
+
+// ServeHTTP handles incoming requests to "/admin/in-memory/schemas" endpoint.
+// GET requests fetch the schemas of all the prefixes.
+// PUT requests set the schema of a prefix.
+// DELETE requests remove the schema of a prefix.
+func (c SchemaController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		resp, err := c.Repository.List()
+		if err != nil {
+			log.Printf("Error while listing the schemas: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodPut:
+		var payload SchemaRequest
+		err := parseJSON(rw, req, &payload, maxSchemaRequestSize)
+		if err != nil {
+			log.Printf("Error on parsing request JSON: %v", err)
+			writeJSON(rw, requestErrorStatus(err), SchemaResponse{Error: err.Error()})
+			return
+		}
+
+		if payload.Prefix == nil || *payload.Prefix == "" {
+			c.badRequest(rw, "prefix field is missing")
+			return
+		}
+
+		if payload.Schema == nil {
+			c.badRequest(rw, "schema field is missing")
+			return
+		}
+
+		resp, err := c.Repository.Put(*payload.Prefix, *payload.Schema)
+		if err != nil {
+			log.Printf("Error while setting the schema: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodDelete:
+		prefix := req.URL.Query().Get("prefix")
+		if prefix == "" {
+			c.badRequest(rw, "prefix parameter is missing")
+			return
+		}
+
+		resp, err := c.Repository.Delete(prefix)
+		if err != nil {
+			log.Printf("Error while removing the schema: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	default:
+		c.methodNotAllowed(rw)
+	}
+}
+
+func (c SchemaController) badRequest(rw http.ResponseWriter, message string) {
+	writeJSON(rw, http.StatusBadRequest, SchemaResponse{Error: message})
+}
+
+func (c SchemaController) methodNotAllowed(rw http.ResponseWriter) {
+	writeJSON(rw, http.StatusMethodNotAllowed, SchemaResponse{Error: "the method is not allowed for this endpoint."})
+}
@@ -0,0 +1,187 @@
+package inmem
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/jsonschema"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrSchemaViolation is returned when a value does not match the schema of its key.
+var ErrSchemaViolation = errors.New("value does not match the schema")
+
+// ErrInvalidSchema is returned when a schema cannot be compiled.
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// SchemaDao interface is used by a SchemaService to manage the JSON Schemas
+// of the values by their key prefixes and by a SchemaCache to validate the values.
+// The version of the schemas changes whenever a schema is set or deleted.
+type SchemaDao interface {
+	Schemas() (map[string]string, error)
+	SchemasVersion() (int64, error)
+	SetSchema(prefix string, schema string) error
+	DeleteSchema(prefix string) (bool, error)
+}
+
+// compiledSchema is a schema of a prefix compiled by a SchemaCache,
+// err is set if the stored schema cannot be compiled.
+type compiledSchema struct {
+	schema *jsonschema.Schema
+	err    error
+}
+
+// SchemaCache holds the schemas compiled by their key prefixes so that they are not fetched
+// and compiled for every value. The schemas are fetched again when their version changes,
+// so that the schemas set or deleted by any replica are enforced by the next write.
+type SchemaCache struct {
+	Dao SchemaDao
+
+	mu       sync.Mutex
+	loaded   bool
+	version  int64
+	compiled map[string]compiledSchema
+}
+
+// Schemas returns the compiled schemas by their key prefixes.
+// The returned map must not be modified.
+func (c *SchemaCache) Schemas() (map[string]compiledSchema, error) {
+	// the version is fetched before the schemas, so that the schemas
+	// changed in between are fetched again by the next call.
+	version, err := c.Dao.SchemasVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded && c.version == version {
+		return c.compiled, nil
+	}
+
+	schemas, err := c.Dao.Schemas()
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make(map[string]compiledSchema, len(schemas))
+	for prefix, schema := range schemas {
+		s, err := jsonschema.Parse([]byte(schema))
+		compiled[prefix] = compiledSchema{schema: s, err: err}
+	}
+
+	c.loaded, c.version, c.compiled = true, version, compiled
+	return compiled, nil
+}
+
+// SchemaService uses SchemaDao to manage the JSON Schemas
+// and creates responses according to the possible errors.
+type SchemaService struct {
+	Dao SchemaDao
+}
+
+// List fetches the schemas of all the prefixes.
+func (s SchemaService) List() (SchemaResponse, error) {
+	schemas, err := s.Dao.Schemas()
+	if err != nil {
+		return SchemaResponse{Error: errorMessage(err)}, err
+	}
+
+	resp := SchemaResponse{Schemas: make(map[string]json.RawMessage, len(schemas))}
+	for prefix, schema := range schemas {
+		resp.Schemas[prefix] = json.RawMessage(schema)
+	}
+	return resp, nil
+}
+
+// Put sets the schema of the values whose keys start with the prefix.
+// The schema is compiled before it is stored so that only valid schemas are enforced.
+func (s SchemaService) Put(prefix string, schema []byte) (SchemaResponse, error) {
+	if _, err := jsonschema.Parse(schema); err != nil {
+		return SchemaResponse{Prefix: prefix, Error: err.Error()}, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, schema); err != nil {
+		return SchemaResponse{Prefix: prefix, Error: err.Error()}, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	err := s.Dao.SetSchema(prefix, compacted.String())
+	if err != nil {
+		return SchemaResponse{Prefix: prefix, Error: errorMessage(err)}, err
+	}
+
+	return SchemaResponse{Prefix: prefix, Schema: compacted.Bytes()}, nil
+}
+
+// Delete removes the schema of the prefix.
+func (s SchemaService) Delete(prefix string) (SchemaResponse, error) {
+	removed, err := s.Dao.DeleteSchema(prefix)
+	if err != nil {
+		return SchemaResponse{Prefix: prefix, Error: errorMessage(err)}, err
+	}
+
+	if !removed {
+		return SchemaResponse{Prefix: prefix, Error: "prefix specified has no schema."}, ErrKeyNotFound
+	}
+
+	return SchemaResponse{Prefix: prefix}, nil
+}
+
+// validateValue validates the value of the dto against the schema of the longest prefix
+// matching its key. The values of the keys without a schema are not validated.
+// The violations are returned in the response with ErrSchemaViolation.
+func validateValue(cache *SchemaCache, dto Dto) (Response, error) {
+	schemas, err := cache.Schemas()
+	if err != nil {
+		return Response{Key: dto.Key, Error: errorMessage(err)}, err
+	}
+
+	prefix, ok := schemaPrefixOf(schemas, dto.Key)
+	if !ok {
+		return Response{Key: dto.Key}, nil
+	}
+
+	schema, err := schemas[prefix].schema, schemas[prefix].err
+	if err != nil {
+		return Response{Key: dto.Key, Error: errorMessage(err)}, fmt.Errorf("error on compiling the schema of %q: %w", prefix, err)
+	}
+
+	var violations []jsonschema.Error
+	if dto.Binary {
+		violations = []jsonschema.Error{{Message: "must be a JSON document, not a binary value"}}
+	} else if violations, err = schema.ValidateJSON([]byte(dto.Value)); err != nil {
+		violations = []jsonschema.Error{{Message: fmt.Sprintf("must be a JSON document: %v", err)}}
+	}
+
+	if len(violations) > 0 {
+		return Response{
+			Key:        dto.Key,
+			Error:      fmt.Sprintf("value does not match the schema of the prefix %q.", prefix),
+			Violations: violations,
+		}, ErrSchemaViolation
+	}
+
+	return Response{Key: dto.Key}, nil
+}
+
+// schemaPrefixOf returns the longest prefix of the key which has a schema.
+func schemaPrefixOf(schemas map[string]compiledSchema, key string) (string, bool) {
+	prefixes := make([]string, 0, len(schemas))
+	for prefix := range schemas {
+		if strings.HasPrefix(key, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	if len(prefixes) == 0 {
+		return "", false
+	}
+
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	return prefixes[0], true
+}
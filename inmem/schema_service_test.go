@@ -0,0 +1,174 @@
+package inmem
+
+import (
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/jsonschema"
+	"reflect"
+	"testing"
+)
+
+type mockSchemaDao struct {
+	SchemasMock        func() (map[string]string, error)
+	SchemasVersionMock func() (int64, error)
+	SetSchemaMock      func(string, string) error
+	DeleteSchemaMock   func(string) (bool, error)
+}
+
+func (m mockSchemaDao) Schemas() (map[string]string, error) {
+	return m.SchemasMock()
+}
+
+func (m mockSchemaDao) SchemasVersion() (int64, error) {
+	return m.SchemasVersionMock()
+}
+
+func (m mockSchemaDao) SetSchema(prefix string, schema string) error {
+	return m.SetSchemaMock(prefix, schema)
+}
+
+func (m mockSchemaDao) DeleteSchema(prefix string) (bool, error) {
+	return m.DeleteSchemaMock(prefix)
+}
+
+var userSchemas = mockSchemaDao{
+	SchemasMock: func() (map[string]string, error) {
+		return map[string]string{
+			"user:":       `{"type": "object", "required": ["name"]}`,
+			"user:admin:": `{"type": "object", "required": ["name", "role"]}`,
+		}, nil
+	},
+	SchemasVersionMock: func() (int64, error) {
+		return 1, nil
+	},
+}
+
+func TestService_SetSchemaViolation(t *testing.T) {
+	service := Service{
+		Dao: mockDao{
+			SetMock: func(dto Dto) (Dto, error) {
+				t.Errorf("value violating the schema is stored: %v", dto.Value)
+				return dto, nil
+			},
+		},
+		Schemas: &SchemaCache{Dao: userSchemas},
+	}
+
+	got, err := service.Set("user:admin:1", "{\"name\":\"getir\"}")
+	if !errors.Is(err, ErrSchemaViolation) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrSchemaViolation)
+	}
+
+	expected := Response{
+		Key:        "user:admin:1",
+		Error:      "value does not match the schema of the prefix \"user:admin:\".",
+		Violations: []jsonschema.Error{{Path: "", Message: "missing required property \"role\""}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("returned incorrect response. got: %+v, expected: %+v", got, expected)
+	}
+}
+
+func TestService_SetValidatesAgainstSchema(t *testing.T) {
+	tests := []struct {
+		key      string
+		value    string
+		expected error
+	}{
+		{"user:1", "{\"name\":\"getir\"}", nil},
+		{"user:1", "getir", ErrSchemaViolation},
+		{"user:admin:1", "{\"name\":\"getir\",\"role\":\"owner\"}", nil},
+		{"active-tabs", "getir", nil},
+	}
+
+	for _, test := range tests {
+		service := Service{
+			Dao: mockDao{
+				SetMock: func(dto Dto) (Dto, error) {
+					return dto, nil
+				},
+			},
+			Schemas: &SchemaCache{Dao: userSchemas},
+		}
+
+		_, err := service.Set(test.key, test.value)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: %v", test.key, err, test.expected)
+		}
+	}
+}
+
+func TestService_StoreBinaryValueWithSchema(t *testing.T) {
+	service := Service{Dao: mockDao{}, Schemas: &SchemaCache{Dao: userSchemas}}
+
+	_, err := service.Store(Dto{Key: "user:1", Value: "{\"name\":\"getir\"}", Binary: true})
+	if !errors.Is(err, ErrSchemaViolation) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrSchemaViolation)
+	}
+}
+
+func TestSchemaService_PutInvalidSchema(t *testing.T) {
+	service := SchemaService{Dao: mockSchemaDao{
+		SetSchemaMock: func(prefix string, schema string) error {
+			t.Errorf("invalid schema is stored: %v", schema)
+			return nil
+		},
+	}}
+
+	_, err := service.Put("user:", []byte("{\"type\": \"text\"}"))
+	if !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrInvalidSchema)
+	}
+}
+
+func TestSchemaService_PutCompactsSchema(t *testing.T) {
+	var stored string
+	service := SchemaService{Dao: mockSchemaDao{
+		SetSchemaMock: func(prefix string, schema string) error {
+			stored = schema
+			return nil
+		},
+	}}
+
+	_, err := service.Put("user:", []byte("{\n\t\"type\": \"object\"\n}"))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if stored != "{\"type\":\"object\"}" {
+		t.Errorf("stored incorrect schema. got: %v, expected: %v", stored, "{\"type\":\"object\"}")
+	}
+}
+
+func TestSchemaCache_Schemas(t *testing.T) {
+	fetched := 0
+	version := int64(1)
+	cache := &SchemaCache{Dao: mockSchemaDao{
+		SchemasMock: func() (map[string]string, error) {
+			fetched++
+			return userSchemas.SchemasMock()
+		},
+		SchemasVersionMock: func() (int64, error) {
+			return version, nil
+		},
+	}}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Schemas(); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	if fetched != 1 {
+		t.Errorf("returned incorrect number of the fetches. got: %v, expected: %v", fetched, 1)
+	}
+
+	version++
+	got, err := cache.Schemas()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if fetched != 2 || len(got) != 2 {
+		t.Errorf("returned incorrect schemas after the version changed. got: %v fetches %v schemas, expected: %v fetches %v schemas", fetched, len(got), 2, 2)
+	}
+}
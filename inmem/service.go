@@ -1,16 +1,34 @@
 package inmem
 
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// EncodingBase64 is the encoding of the binary values in the requests and the responses.
+const EncodingBase64 = "base64"
+
 // Dao interface is used by a Service to construct a response model
 // using data obtained from the database.
 type Dao interface{
 	Get(string) (Dto, error)
-	Set(dto Dto) error
+	Set(dto Dto) (Dto, error)
+	Delete(string) (bool, error)
 }
 
 // Service uses Dao to access the in-memory database.
 // creates responses according to the possible errors.
+// The values longer than MaxValueSize bytes are rejected,
+// zero MaxValueSize means that there is no limit.
+// If Schemas is set, the values are validated against the JSON Schemas of their keys.
 type Service struct{
 	Dao Dao
+	MaxValueSize int
+	Schemas *SchemaCache
 }
 
 func (s Service) Get(key string) (Response, error) {
@@ -18,7 +36,7 @@ func (s Service) Get(key string) (Response, error) {
 	if err != nil {
 		return Response{
 			Key: key,
-			Error: "internal server error occurred.",
+			Error: errorMessage(err),
 		}, err
 	}
 	
@@ -26,31 +44,143 @@ func (s Service) Get(key string) (Response, error) {
 		return Response{
 			Key:   key,
 			Error: "key specified does not exist.",
-		}, nil
+		}, ErrKeyNotFound
 	}
 
+	value, encoding := encodeResponseValue(dto.Value, dto.Binary)
 	resp := Response{
 		Key:   key,
-		Value: dto.Value,
+		Value: value,
+		Encoding: encoding,
+		ContentType: dto.ContentType,
+		RawSize: dto.RawSize,
+		StoredSize: dto.StoredSize,
 	}
 	return resp, nil
 }
 
 func (s Service) Set(key string, value string) (Response, error) {
-	err := s.Dao.Set(Dto{
-		Key:    key,
-		Value:  value,
+	return s.Store(Dto{Key: key, Value: value})
+}
+
+// Put sets the value of the key with its content type.
+// The value is stored without a content type if it is empty.
+// The values of non-textual content types are stored as binary values.
+func (s Service) Put(key string, value string, contentType string) (Response, error) {
+	return s.Store(Dto{
+		Key:         key,
+		Value:       value,
+		ContentType: contentType,
+		Binary:      contentType != "" && !isTextContentType(contentType),
 	})
+}
+
+// Store sets the value of the key with the metadata of the dto.
+func (s Service) Store(dto Dto) (Response, error) {
+	key := dto.Key
+	if s.MaxValueSize > 0 && len(dto.Value) > s.MaxValueSize {
+		return Response{
+			Key: key,
+			Error: fmt.Sprintf("value must not be larger than %v bytes.", s.MaxValueSize),
+		}, ErrValueTooLarge
+	}
+
+	if s.Schemas != nil {
+		resp, err := validateValue(s.Schemas, dto)
+		if err != nil {
+			return resp, err
+		}
+	}
+
+	stored, err := s.Dao.Set(dto)
 	if err != nil {
 		return Response{
 			Key: key,
-			Error: "internal server error occurred.",
+			Error: errorMessage(err),
 		}, err
 	}
 
+	value, encoding := encodeResponseValue(dto.Value, dto.Binary)
 	resp := Response{
 		Key:   key,
 		Value: value,
+		Encoding: encoding,
+		ContentType: dto.ContentType,
+		RawSize: stored.RawSize,
+		StoredSize: stored.StoredSize,
 	}
 	return resp, nil
-}
\ No newline at end of file
+}
+
+// Delete removes the key.
+func (s Service) Delete(key string) (Response, error) {
+	removed, err := s.Dao.Delete(key)
+	if err != nil {
+		return Response{
+			Key: key,
+			Error: errorMessage(err),
+		}, err
+	}
+
+	if !removed {
+		return Response{
+			Key:   key,
+			Error: "key specified does not exist.",
+		}, ErrKeyNotFound
+	}
+
+	return Response{Key: key}, nil
+}
+
+// encodeResponseValue returns the value written to a JSON response with its encoding.
+// Binary values and the values which are not valid UTF-8 are base64 encoded
+// since they cannot be represented by JSON strings faithfully.
+func encodeResponseValue(value string, binary bool) (string, string) {
+	if !binary && utf8.ValidString(value) {
+		return value, ""
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(value)), EncodingBase64
+}
+
+// isTextContentType reports whether the values of the content type are text.
+func isTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == "application/xml",
+		mediaType == "application/javascript",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	default:
+		return false
+	}
+}
+
+// errorMessage returns the message written to a response
+// for an error occurred while accessing the in-memory database.
+func errorMessage(err error) string {
+	if errors.Is(err, ErrWrongType) {
+		return "key specified holds a value of another data type."
+	}
+
+	if errors.Is(err, ErrKeyQuotaExceeded) {
+		return "namespace has reached its key quota."
+	}
+
+	if errors.Is(err, ErrByteQuotaExceeded) {
+		return "namespace has reached its byte quota."
+	}
+
+	if errors.Is(err, ErrEncryptionUnsupported) {
+		return "data type is not available while the values are encrypted."
+	}
+
+	return "internal server error occurred."
+}
@@ -1,23 +1,30 @@
 package inmem
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
-type mockDao struct{
-	GetMock func(string) (Dto, error)
-	SetMock func(dto Dto) error
+type mockDao struct {
+	GetMock    func(string) (Dto, error)
+	SetMock    func(dto Dto) (Dto, error)
+	DeleteMock func(string) (bool, error)
 }
 
 func (m mockDao) Get(key string) (Dto, error) {
 	return m.GetMock(key)
 }
 
-func (m mockDao) Set(dto Dto) error {
+func (m mockDao) Set(dto Dto) (Dto, error) {
 	return m.SetMock(dto)
 }
 
+func (m mockDao) Delete(key string) (bool, error) {
+	return m.DeleteMock(key)
+}
+
 func TestService_GetWithExistingKey(t *testing.T) {
 	mock := mockDao{
 		GetMock: func(s string) (Dto, error) {
@@ -86,8 +93,8 @@ func TestService_GetWithNonExistingKey(t *testing.T) {
 
 func TestService_SetSuccess(t *testing.T) {
 	mock := mockDao{
-		SetMock: func(dto Dto) error {
-			return nil
+		SetMock: func(dto Dto) (Dto, error) {
+			return dto, nil
 		},
 	}
 	service := Service{Dao: mock}
@@ -114,8 +121,8 @@ func TestService_SetSuccess(t *testing.T) {
 
 func TestService_SetInternalError(t *testing.T) {
 	mock := mockDao{
-		SetMock: func(dto Dto) error {
-			return fmt.Errorf("mock error")
+		SetMock: func(dto Dto) (Dto, error) {
+			return dto, fmt.Errorf("mock error")
 		},
 	}
 	service := Service{Dao: mock}
@@ -138,4 +145,92 @@ func TestService_SetInternalError(t *testing.T) {
 	if got.Error != expected.Error {
 		t.Errorf("returned incorrect error. got: %v, expected: %v", got.Error, expected.Error)
 	}
-}
\ No newline at end of file
+}
+
+func TestService_SetValueTooLarge(t *testing.T) {
+	mock := mockDao{
+		SetMock: func(dto Dto) (Dto, error) {
+			t.Errorf("value larger than the maximum size is stored")
+			return dto, nil
+		},
+	}
+	service := Service{Dao: mock, MaxValueSize: 4}
+	got, err := service.Set("active-tabs", "getir")
+
+	if !errors.Is(err, ErrValueTooLarge) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrValueTooLarge)
+	}
+
+	expected := "value must not be larger than 4 bytes."
+	if got.Error != expected {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", got.Error, expected)
+	}
+}
+
+func TestService_SetReportsSizes(t *testing.T) {
+	mock := mockDao{
+		SetMock: func(dto Dto) (Dto, error) {
+			dto.RawSize, dto.StoredSize = 2048, 64
+			return dto, nil
+		},
+	}
+	service := Service{Dao: mock}
+	got, _ := service.Set("active-tabs", "getir")
+
+	if got.RawSize != 2048 || got.StoredSize != 64 {
+		t.Errorf("returned incorrect sizes. got: %v/%v, expected: %v/%v", got.RawSize, got.StoredSize, 2048, 64)
+	}
+}
+
+func TestService_GetBinaryValue(t *testing.T) {
+	mock := mockDao{
+		GetMock: func(s string) (Dto, error) {
+			return Dto{
+				Key:         "blob",
+				Value:       "\x00\x01\x02",
+				ContentType: "image/png",
+				Binary:      true,
+				Exists:      true,
+			}, nil
+		},
+	}
+	service := Service{Dao: mock}
+	got, _ := service.Get("blob")
+
+	expected := Response{Key: "blob", Value: "AAEC", Encoding: EncodingBase64, ContentType: "image/png"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("returned incorrect response. got: %+v, expected: %+v", got, expected)
+	}
+}
+
+func TestService_PutMarksBinaryContentTypes(t *testing.T) {
+	tests := []struct {
+		contentType string
+		binary      bool
+	}{
+		{"", false},
+		{"text/csv; charset=utf-8", false},
+		{"application/vnd.api+json", false},
+		{"image/png", true},
+		{"application/octet-stream", true},
+	}
+
+	for _, test := range tests {
+		var got Dto
+		mock := mockDao{
+			SetMock: func(dto Dto) (Dto, error) {
+				got = dto
+				return dto, nil
+			},
+		}
+		service := Service{Dao: mock}
+		_, err := service.Put("key", "value", test.contentType)
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if got.Binary != test.binary {
+			t.Errorf("returned incorrect binary flag for %q. got: %v, expected: %v", test.contentType, got.Binary, test.binary)
+		}
+	}
+}
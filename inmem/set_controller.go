@@ -0,0 +1,129 @@
+package inmem
+
+import (
+	"log"
+	"net/http"
+)
+
+// SetRepository interface is used by a SetController
+// to operate on the sets stored in an in-memory database.
+type SetRepository interface {
+	Add(key string, members []string) (SetResponse, error)
+	Remove(key string, members []string) (SetResponse, error)
+	Members(key string) (SetResponse, error)
+	IsMember(key string, member string) (SetResponse, error)
+}
+
+// SetController is a handler for handling
+// requests coming to "/in-memory/sets" endpoint.
+// The request bodies are read up to the size of the values of MaxValueSize bytes,
+// zero MaxValueSize means that there is no limit.
+// The accesses to the sets are authorized by Acl if it is set.
+type SetController struct {
+	Repository   SetRepository
+	Acl          *Acl
+	MaxValueSize int
+}
+
+// ServeHTTP handles incoming requests to "/in-memory/sets" endpoint.
+// POST requests add members to a set.
+// DELETE requests remove members from a set.
+// GET requests check the membership if member parameter is given,
+// otherwise fetch all the members of a set.
+func (c SetController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		var payload SetRequest
+		err := parseJSON(rw, req, &payload, jsonBodyLimit(c.MaxValueSize))
+		if err != nil {
+			log.Printf("Error on parsing request JSON: %v", err)
+			writeJSON(rw, requestErrorStatus(err), SetResponse{Error: err.Error()})
+			return
+		}
+
+		if payload.Key == nil {
+			c.badRequest(rw, "key field is missing")
+			return
+		}
+
+		if len(payload.Members) == 0 {
+			c.badRequest(rw, "members field is missing or empty")
+			return
+		}
+
+		if !c.authorize(rw, req, ActionWrite, *payload.Key) {
+			return
+		}
+
+		resp, err := c.Repository.Add(*payload.Key, payload.Members)
+		if err != nil {
+			log.Printf("Error while adding to the set: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodDelete:
+		query := req.URL.Query()
+		key, members := query.Get("key"), query["member"]
+		if key == "" {
+			c.badRequest(rw, "key parameter is missing")
+			return
+		}
+
+		if len(members) == 0 {
+			c.badRequest(rw, "member parameter is missing")
+			return
+		}
+
+		if !c.authorize(rw, req, ActionWrite, key) {
+			return
+		}
+
+		resp, err := c.Repository.Remove(key, members)
+		if err != nil {
+			log.Printf("Error while removing from the set: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	case http.MethodGet:
+		query := req.URL.Query()
+		key := query.Get("key")
+		if key == "" {
+			c.badRequest(rw, "key parameter is missing")
+			return
+		}
+
+		if !c.authorize(rw, req, ActionRead, key) {
+			return
+		}
+
+		var resp SetResponse
+		var err error
+		if _, ok := query["member"]; ok {
+			resp, err = c.Repository.IsMember(key, query.Get("member"))
+		} else {
+			resp, err = c.Repository.Members(key)
+		}
+		if err != nil {
+			log.Printf("Error while fetching the set: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	default:
+		c.methodNotAllowed(rw)
+	}
+}
+
+// authorize checks the access of the client of the request to the set.
+func (c SetController) authorize(rw http.ResponseWriter, req *http.Request, action, key string) bool {
+	if err := checkAccess(c.Acl, req, action, key); err != nil {
+		writeJSON(rw, statusCodeOf(err), SetResponse{Key: key, Error: err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func (c SetController) badRequest(rw http.ResponseWriter, message string) {
+	writeJSON(rw, http.StatusBadRequest, SetResponse{Error: message})
+}
+
+func (c SetController) methodNotAllowed(rw http.ResponseWriter) {
+	writeJSON(rw, http.StatusMethodNotAllowed, SetResponse{Error: "the method is not allowed for this endpoint."})
+}
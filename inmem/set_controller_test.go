@@ -0,0 +1,105 @@
+package inmem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type mockSetDao struct {
+	SetAddMock      func(string, []string) (int64, error)
+	SetRemoveMock   func(string, []string) (int64, error)
+	SetMembersMock  func(string) (SetDto, error)
+	SetIsMemberMock func(string, string) (bool, error)
+}
+
+func (m mockSetDao) SetAdd(key string, members []string) (int64, error) {
+	return m.SetAddMock(key, members)
+}
+
+func (m mockSetDao) SetRemove(key string, members []string) (int64, error) {
+	return m.SetRemoveMock(key, members)
+}
+
+func (m mockSetDao) SetMembers(key string) (SetDto, error) {
+	return m.SetMembersMock(key)
+}
+
+func (m mockSetDao) SetIsMember(key string, member string) (bool, error) {
+	return m.SetIsMemberMock(key, member)
+}
+
+func TestSetController_ServeHTTPValidPost(t *testing.T) {
+	mock := mockSetDao{
+		SetAddMock: func(s string, members []string) (int64, error) {
+			return int64(len(members)), nil
+		},
+	}
+
+	request := "{\"key\":\"tags\",\"members\":[\"a\",\"b\",\"c\"]}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory/sets", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := SetController{Repository: SetService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expected := "{\"key\":\"tags\",\"count\":3}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestSetController_ServeHTTPEmptyMembers(t *testing.T) {
+	request := "{\"key\":\"tags\",\"members\":[]}"
+	req, err := http.NewRequest(http.MethodPost, "/in-memory/sets", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := SetController{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+
+	expected := "{\"key\":\"\",\"error\":\"members field is missing or empty\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestSetController_ServeHTTPIsMember(t *testing.T) {
+	mock := mockSetDao{
+		SetIsMemberMock: func(s string, member string) (bool, error) {
+			return member == "a", nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/in-memory/sets?key=tags&member=b", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := SetController{Repository: SetService{Dao: mock}}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	expected := "{\"key\":\"tags\",\"isMember\":false}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
@@ -0,0 +1,60 @@
+package inmem
+
+// SetDao interface is used by a SetService to access the sets
+// stored in the in-memory database.
+type SetDao interface {
+	SetAdd(key string, members []string) (int64, error)
+	SetRemove(key string, members []string) (int64, error)
+	SetMembers(key string) (SetDto, error)
+	SetIsMember(key string, member string) (bool, error)
+}
+
+// SetService uses SetDao to operate on sets and
+// creates responses according to the possible errors.
+type SetService struct {
+	Dao SetDao
+}
+
+// Add adds the members to the set.
+func (s SetService) Add(key string, members []string) (SetResponse, error) {
+	added, err := s.Dao.SetAdd(key, members)
+	if err != nil {
+		return SetResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	return SetResponse{Key: key, Count: added}, nil
+}
+
+// Remove removes the members from the set.
+func (s SetService) Remove(key string, members []string) (SetResponse, error) {
+	removed, err := s.Dao.SetRemove(key, members)
+	if err != nil {
+		return SetResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	return SetResponse{Key: key, Count: removed}, nil
+}
+
+// Members fetches all the members of the set.
+func (s SetService) Members(key string) (SetResponse, error) {
+	dto, err := s.Dao.SetMembers(key)
+	if err != nil {
+		return SetResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	if !dto.Exists {
+		return SetResponse{Key: key, Error: "key specified does not exist."}, nil
+	}
+
+	return SetResponse{Key: key, Members: dto.Members}, nil
+}
+
+// IsMember checks whether the member is in the set.
+func (s SetService) IsMember(key string, member string) (SetResponse, error) {
+	isMember, err := s.Dao.SetIsMember(key, member)
+	if err != nil {
+		return SetResponse{Key: key, Error: errorMessage(err)}, err
+	}
+
+	return SetResponse{Key: key, IsMember: &isMember}, nil
+}
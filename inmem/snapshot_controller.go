@@ -0,0 +1,91 @@
+package inmem
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"path"
+)
+
+// SnapshotRepository interface is used by a SnapshotController
+// to export and import the keyspace of an in-memory database.
+type SnapshotRepository interface {
+	Export(pattern string, w io.Writer) (int64, error)
+	Import(r io.Reader, policy ConflictPolicy) (SnapshotResponse, error)
+}
+
+// SnapshotController is a handler for handling requests coming to
+// "/admin/in-memory/export" and "/admin/in-memory/import" endpoints.
+type SnapshotController struct {
+	Repository SnapshotRepository
+}
+
+// ServeHTTP handles incoming requests to the snapshot endpoints.
+// GET requests to export endpoint stream the keys matching pattern parameter as an NDJSON archive.
+// POST requests to import endpoint write the keys in the NDJSON archive sent as the request body,
+// the existing keys are handled according to policy parameter which is skip, overwrite or fail.
+func (c SnapshotController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch path.Base(req.URL.Path) {
+	case "export":
+		if req.Method != http.MethodGet {
+			c.methodNotAllowed(rw)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		rw.Header().Set("Content-Disposition", "attachment; filename=\"in-memory.ndjson\"")
+		exported, err := c.Repository.Export(req.URL.Query().Get("pattern"), rw)
+		if err != nil {
+			// the status code is already sent, the archive is terminated
+			// by a line which cannot be imported to notice the client.
+			log.Printf("Error while exporting the keyspace: %v", err)
+			writeJSONLine(rw, SnapshotResponse{Error: errorMessage(err)})
+			return
+		}
+
+		log.Printf("Exported %v keys", exported)
+	case "import":
+		if req.Method != http.MethodPost {
+			c.methodNotAllowed(rw)
+			return
+		}
+
+		policy := ConflictPolicy(req.URL.Query().Get("policy"))
+		switch policy {
+		case "":
+			policy = ConflictSkip
+		case ConflictSkip, ConflictOverwrite, ConflictFail:
+		default:
+			writeJSON(rw, http.StatusBadRequest, SnapshotResponse{Error: "policy must be skip, overwrite or fail"})
+			return
+		}
+
+		resp, err := c.Repository.Import(req.Body, policy)
+		if err != nil {
+			log.Printf("Error while importing the keyspace: %v", err)
+		}
+		writeJSON(rw, statusCodeOf(err), resp)
+	default:
+		writeJSON(rw, http.StatusNotFound, SnapshotResponse{Error: "the endpoint does not exist."})
+	}
+}
+
+func (c SnapshotController) methodNotAllowed(rw http.ResponseWriter) {
+	rw.Header().Set("Content-Type", "application/json")
+	writeJSON(rw, http.StatusMethodNotAllowed, SnapshotResponse{Error: "the method is not allowed for this endpoint."})
+}
+
+// writeJSONLine appends the JSON encoded object as a line to an NDJSON body.
+func writeJSONLine(w io.Writer, v interface{}) {
+	respBytes, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Error on marshalling to JSON: %v", err)
+		return
+	}
+
+	_, err = w.Write(append(respBytes, '\n'))
+	if err != nil {
+		log.Printf("Error on writing response: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package inmem
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	// snapshotBatchSize is the number of the keys fetched or written in a pipeline.
+	snapshotBatchSize = 100
+	// maxSnapshotLineSize is the maximum size of an entry in an archive.
+	maxSnapshotLineSize = 64 * 1024 * 1024
+)
+
+// SnapshotDao interface is used by a SnapshotService to
+// read and write the keyspace of the in-memory database in batches.
+type SnapshotDao interface {
+	ScanKeys(pattern string, cursor uint64, count int64) ([]string, uint64, error)
+	DumpEntries(keys []string) ([]SnapshotEntry, error)
+	RestoreEntries(entries []SnapshotEntry, policy ConflictPolicy) (int64, int64, error)
+}
+
+// SnapshotService uses SnapshotDao to export the keyspace to
+// NDJSON archives and to import the archives back.
+// Each line of an archive is a JSON encoded SnapshotEntry.
+type SnapshotService struct {
+	Dao SnapshotDao
+}
+
+// Export writes the keys of the namespaces matching the pattern with their values
+// and TTLs to w. All the keys of the namespaces are exported if the pattern is empty.
+// It returns the number of the exported keys.
+func (s SnapshotService) Export(pattern string, w io.Writer) (int64, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	var exported int64
+	encoder := json.NewEncoder(w)
+	var cursor uint64
+	for {
+		keys, next, err := s.Dao.ScanKeys(pattern, cursor, snapshotBatchSize)
+		if err != nil {
+			return exported, err
+		}
+
+		entries, err := s.Dao.DumpEntries(keys)
+		if err != nil {
+			return exported, err
+		}
+
+		for _, entry := range entries {
+			if entry.Type == TypeString && !utf8.ValidString(entry.Value) {
+				entry.Value = base64.StdEncoding.EncodeToString([]byte(entry.Value))
+				entry.Encoding = "base64"
+			}
+
+			err = encoder.Encode(entry)
+			if err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		// SCAN may return the same key more than once, but it is harmless
+		// since importing a key twice results in the same keyspace.
+		cursor = next
+		if cursor == 0 {
+			return exported, nil
+		}
+	}
+}
+
+// Import reads the entries from the archive and writes them in batches.
+// If the policy is ConflictFail, the import stops at the batch containing
+// the first existing key, the batches before it remain imported.
+func (s SnapshotService) Import(r io.Reader, policy ConflictPolicy) (SnapshotResponse, error) {
+	var resp SnapshotResponse
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxSnapshotLineSize)
+
+	batch := make([]SnapshotEntry, 0, snapshotBatchSize)
+	flush := func() error {
+		imported, skipped, err := s.Dao.RestoreEntries(batch, policy)
+		resp.Imported += imported
+		resp.Skipped += skipped
+		batch = batch[:0]
+		return err
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry SnapshotEntry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err == nil {
+			err = decodeSnapshotEntry(&entry)
+		}
+
+		if err != nil {
+			resp.Error = fmt.Sprintf("invalid entry at line %v: %v", line, err)
+			return resp, fmt.Errorf("%w: %v", ErrInvalidSnapshot, resp.Error)
+		}
+
+		batch = append(batch, entry)
+		if len(batch) == snapshotBatchSize {
+			if err = flush(); err != nil {
+				resp.Error = snapshotErrorMessage(err)
+				return resp, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		resp.Error = fmt.Sprintf("error on reading the archive: %v", err)
+		return resp, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			resp.Error = snapshotErrorMessage(err)
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
+
+// decodeSnapshotEntry validates the entry read from an archive
+// and decodes its value if it is base64 encoded.
+func decodeSnapshotEntry(entry *SnapshotEntry) error {
+	if entry.Key == "" {
+		return fmt.Errorf("key is missing")
+	}
+
+	switch entry.Type {
+	case TypeString:
+		if entry.Encoding == "base64" {
+			value, err := base64.StdEncoding.DecodeString(entry.Value)
+			if err != nil {
+				return err
+			}
+			entry.Value, entry.Encoding = string(value), ""
+		} else if entry.Encoding != "" {
+			return fmt.Errorf("unsupported encoding %v", entry.Encoding)
+		}
+	case TypeHash:
+		if len(entry.Fields) == 0 {
+			return fmt.Errorf("fields of a hash must not be empty")
+		}
+	case TypeList, TypeSet:
+		if len(entry.Elements) == 0 {
+			return fmt.Errorf("elements of a %v must not be empty", entry.Type)
+		}
+	case TypeSortedSet:
+		if len(entry.Members) == 0 {
+			return fmt.Errorf("members of a zset must not be empty")
+		}
+	default:
+		return fmt.Errorf("unsupported type %v", entry.Type)
+	}
+
+	if entry.TTLMillis < 0 {
+		return fmt.Errorf("ttlMillis must not be negative")
+	}
+
+	return nil
+}
+
+// snapshotErrorMessage returns the message written to a response
+// for an error occurred while importing an archive.
+func snapshotErrorMessage(err error) string {
+	if errors.Is(err, ErrConflict) {
+		return err.Error()
+	}
+
+	return errorMessage(err)
+}
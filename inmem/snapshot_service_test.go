@@ -0,0 +1,121 @@
+package inmem
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type mockSnapshotDao struct {
+	ScanKeysMock       func(string, uint64, int64) ([]string, uint64, error)
+	DumpEntriesMock    func([]string) ([]SnapshotEntry, error)
+	RestoreEntriesMock func([]SnapshotEntry, ConflictPolicy) (int64, int64, error)
+}
+
+func (m mockSnapshotDao) ScanKeys(pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+	return m.ScanKeysMock(pattern, cursor, count)
+}
+
+func (m mockSnapshotDao) DumpEntries(keys []string) ([]SnapshotEntry, error) {
+	return m.DumpEntriesMock(keys)
+}
+
+func (m mockSnapshotDao) RestoreEntries(entries []SnapshotEntry, policy ConflictPolicy) (int64, int64, error) {
+	return m.RestoreEntriesMock(entries, policy)
+}
+
+func TestSnapshotService_ExportAllPages(t *testing.T) {
+	mock := mockSnapshotDao{
+		ScanKeysMock: func(pattern string, cursor uint64, count int64) ([]string, uint64, error) {
+			if cursor == 0 {
+				return []string{"active-tabs"}, 7, nil
+			}
+			return []string{"binary"}, 0, nil
+		},
+		DumpEntriesMock: func(keys []string) ([]SnapshotEntry, error) {
+			if keys[0] == "binary" {
+				return []SnapshotEntry{{Key: "binary", Type: TypeString, Value: "\xff\xfe"}}, nil
+			}
+			return []SnapshotEntry{{Key: "active-tabs", Type: TypeString, Value: "getir", TTLMillis: 1000}}, nil
+		},
+	}
+
+	var archive bytes.Buffer
+	service := SnapshotService{Dao: mock}
+	exported, err := service.Export("", &archive)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if exported != 2 {
+		t.Errorf("returned incorrect number of keys. got: %v, expected: %v", exported, 2)
+	}
+
+	expected := "{\"key\":\"active-tabs\",\"type\":\"string\",\"ttlMillis\":1000,\"value\":\"getir\"}\n" +
+		"{\"key\":\"binary\",\"type\":\"string\",\"value\":\"//4=\",\"encoding\":\"base64\"}\n"
+	if archive.String() != expected {
+		t.Errorf("returned incorrect archive. got: %v, expected: %v", archive.String(), expected)
+	}
+}
+
+func TestSnapshotService_ImportDecodesEntries(t *testing.T) {
+	var got []SnapshotEntry
+	mock := mockSnapshotDao{
+		RestoreEntriesMock: func(entries []SnapshotEntry, policy ConflictPolicy) (int64, int64, error) {
+			got = append(got, entries...)
+			return int64(len(entries)), 0, nil
+		},
+	}
+
+	archive := "{\"key\":\"binary\",\"type\":\"string\",\"value\":\"//4=\",\"encoding\":\"base64\"}\n\n" +
+		"{\"key\":\"tags\",\"type\":\"set\",\"elements\":[\"a\",\"b\"]}\n"
+	service := SnapshotService{Dao: mock}
+	resp, err := service.Import(strings.NewReader(archive), ConflictSkip)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if resp.Imported != 2 {
+		t.Errorf("returned incorrect number of keys. got: %v, expected: %v", resp.Imported, 2)
+	}
+
+	if len(got) != 2 || got[0].Value != "\xff\xfe" || got[0].Encoding != "" {
+		t.Errorf("restored incorrect entries. got: %+v", got)
+	}
+}
+
+func TestSnapshotService_ImportInvalidEntry(t *testing.T) {
+	service := SnapshotService{Dao: mockSnapshotDao{}}
+	resp, err := service.Import(strings.NewReader("{\"key\":\"tags\",\"type\":\"stream\"}\n"), ConflictSkip)
+
+	if !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, ErrInvalidSnapshot)
+	}
+
+	expected := "invalid entry at line 1: unsupported type stream"
+	if resp.Error != expected {
+		t.Errorf("returned incorrect error message. got: %v, expected: %v", resp.Error, expected)
+	}
+}
+
+func TestSnapshotService_ImportConflict(t *testing.T) {
+	mock := mockSnapshotDao{
+		RestoreEntriesMock: func(entries []SnapshotEntry, policy ConflictPolicy) (int64, int64, error) {
+			return 0, 0, fmt.Errorf("%w: %v", ErrConflict, entries[0].Key)
+		},
+	}
+
+	service := SnapshotService{Dao: mock}
+	resp, err := service.Import(strings.NewReader("{\"key\":\"active-tabs\",\"type\":\"string\",\"value\":\"getir\"}"), ConflictFail)
+
+	if statusCodeOf(err) != 409 {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", statusCodeOf(err), 409)
+	}
+
+	expected := "key already exists: active-tabs"
+	if resp.Error != expected {
+		t.Errorf("returned incorrect error message. got: %v, expected: %v", resp.Error, expected)
+	}
+}
@@ -0,0 +1,383 @@
+// Package jsonschema validates JSON documents against JSON Schemas.
+// It supports the validation keywords of the JSON Schema specification listed in supportedKeywords,
+// the annotation keywords are ignored. The schemas using the other keywords, e.g. the references
+// and the conditionals, are rejected rather than validating the documents partially.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+)
+
+// supportedKeywords are the keywords validated by a Schema.
+var supportedKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true,
+	"properties": true, "required": true, "additionalProperties": true, "minProperties": true, "maxProperties": true,
+	"items": true, "minItems": true, "maxItems": true, "uniqueItems": true,
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true, "multipleOf": true,
+	"minLength": true, "maxLength": true, "pattern": true,
+	"allOf": true, "anyOf": true, "oneOf": true, "not": true,
+}
+
+// annotationKeywords are the keywords which do not affect the validation.
+var annotationKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true, "default": true,
+	"examples": true, "readOnly": true, "writeOnly": true, "deprecated": true, "format": true,
+	"contentEncoding": true, "contentMediaType": true,
+}
+
+// Schema represents a compiled JSON Schema.
+// The keywords which are not given are nil.
+type Schema struct {
+	// always is set if the schema is a boolean schema.
+	always *bool
+
+	types    []string
+	enum     []interface{}
+	constant *interface{}
+
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema
+	minProperties        *int
+	maxProperties        *int
+
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	allOf []*Schema
+	anyOf []*Schema
+	oneOf []*Schema
+	not   *Schema
+}
+
+// Error represents a violation of a schema. Path is the JSON Pointer
+// of the violating value in the document, it is empty for the document itself.
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%v: %v", e.Path, e.Message)
+}
+
+// Parse compiles the JSON Schema.
+func Parse(data []byte) (*Schema, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %w", err)
+	}
+
+	return compile(document, "")
+}
+
+// ValidateJSON validates the JSON document against the schema.
+// It returns an error if the document is not valid JSON.
+func (s *Schema) ValidateJSON(data []byte) ([]Error, error) {
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return s.Validate(document), nil
+}
+
+// Validate validates the document decoded by encoding/json against the schema.
+// It returns all the violations found, nil if the document is valid.
+func (s *Schema) Validate(document interface{}) []Error {
+	return s.validate(document, "")
+}
+
+// compile compiles a schema, path is the location of the schema used in the error messages.
+func compile(document interface{}, path string) (*Schema, error) {
+	if always, ok := document.(bool); ok {
+		return &Schema{always: &always}, nil
+	}
+
+	keywords, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema%v must be an object or a boolean", at(path))
+	}
+
+	names := make([]string, 0, len(keywords))
+	for name := range keywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !supportedKeywords[name] && !annotationKeywords[name] {
+			return nil, fmt.Errorf("schema%v: %v is not supported", at(path), name)
+		}
+	}
+
+	c := compiler{keywords: keywords, path: path}
+	s := &Schema{}
+	s.types = c.types("type")
+	s.enum = c.array("enum")
+	if constant, ok := keywords["const"]; ok {
+		s.constant = &constant
+	}
+
+	s.properties = c.schemaMap("properties")
+	s.required = c.strings("required")
+	s.additionalProperties = c.schema("additionalProperties")
+	s.minProperties = c.count("minProperties")
+	s.maxProperties = c.count("maxProperties")
+
+	s.items = c.schema("items")
+	s.minItems = c.count("minItems")
+	s.maxItems = c.count("maxItems")
+	s.uniqueItems = c.boolean("uniqueItems")
+
+	s.minimum = c.number("minimum")
+	s.maximum = c.number("maximum")
+	s.exclusiveMinimum = c.number("exclusiveMinimum")
+	s.exclusiveMaximum = c.number("exclusiveMaximum")
+	s.multipleOf = c.number("multipleOf")
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		c.fail("multipleOf", "must be greater than 0")
+	}
+
+	s.minLength = c.count("minLength")
+	s.maxLength = c.count("maxLength")
+	s.pattern = c.regexp("pattern")
+
+	s.allOf = c.schemas("allOf")
+	s.anyOf = c.schemas("anyOf")
+	s.oneOf = c.schemas("oneOf")
+	s.not = c.schema("not")
+
+	return s, c.err
+}
+
+// compiler reads the keywords of a schema, keeping the first error occurred.
+type compiler struct {
+	keywords map[string]interface{}
+	path     string
+	err      error
+}
+
+func (c *compiler) fail(keyword string, message string) {
+	if c.err == nil {
+		c.err = fmt.Errorf("schema%v: %v %v", at(c.path), keyword, message)
+	}
+}
+
+func (c *compiler) types(keyword string) []string {
+	switch value := c.keywords[keyword].(type) {
+	case nil:
+		return nil
+	case string:
+		return c.validTypes(keyword, []string{value})
+	case []interface{}:
+		return c.validTypes(keyword, c.strings(keyword))
+	default:
+		c.fail(keyword, "must be a string or an array of strings")
+		return nil
+	}
+}
+
+func (c *compiler) validTypes(keyword string, types []string) []string {
+	for _, t := range types {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			c.fail(keyword, fmt.Sprintf("has an unknown type %q", t))
+		}
+	}
+
+	return types
+}
+
+func (c *compiler) array(keyword string) []interface{} {
+	value, ok := c.keywords[keyword]
+	if !ok {
+		return nil
+	}
+
+	array, ok := value.([]interface{})
+	if !ok {
+		c.fail(keyword, "must be an array")
+	}
+	return array
+}
+
+func (c *compiler) strings(keyword string) []string {
+	array := c.array(keyword)
+	if array == nil {
+		return nil
+	}
+
+	strings := make([]string, 0, len(array))
+	for _, element := range array {
+		s, ok := element.(string)
+		if !ok {
+			c.fail(keyword, "must be an array of strings")
+			return nil
+		}
+		strings = append(strings, s)
+	}
+
+	return strings
+}
+
+func (c *compiler) number(keyword string) *float64 {
+	value, ok := c.keywords[keyword]
+	if !ok {
+		return nil
+	}
+
+	number, ok := value.(float64)
+	if !ok {
+		c.fail(keyword, "must be a number")
+		return nil
+	}
+	return &number
+}
+
+func (c *compiler) count(keyword string) *int {
+	number := c.number(keyword)
+	if number == nil {
+		return nil
+	}
+
+	if *number < 0 || *number != math.Trunc(*number) {
+		c.fail(keyword, "must be a non-negative integer")
+		return nil
+	}
+
+	count := int(*number)
+	return &count
+}
+
+func (c *compiler) boolean(keyword string) bool {
+	value, ok := c.keywords[keyword]
+	if !ok {
+		return false
+	}
+
+	b, ok := value.(bool)
+	if !ok {
+		c.fail(keyword, "must be a boolean")
+	}
+	return b
+}
+
+func (c *compiler) regexp(keyword string) *regexp.Regexp {
+	value, ok := c.keywords[keyword]
+	if !ok {
+		return nil
+	}
+
+	pattern, ok := value.(string)
+	if !ok {
+		c.fail(keyword, "must be a string")
+		return nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		c.fail(keyword, fmt.Sprintf("is not a valid regular expression: %v", err))
+	}
+	return re
+}
+
+func (c *compiler) schema(keyword string) *Schema {
+	value, ok := c.keywords[keyword]
+	if !ok {
+		return nil
+	}
+
+	s, err := compile(value, c.path+"/"+keyword)
+	if err != nil && c.err == nil {
+		c.err = err
+	}
+	return s
+}
+
+func (c *compiler) schemas(keyword string) []*Schema {
+	array := c.array(keyword)
+	if array == nil {
+		return nil
+	}
+
+	if len(array) == 0 {
+		c.fail(keyword, "must not be empty")
+	}
+
+	schemas := make([]*Schema, 0, len(array))
+	for i, value := range array {
+		s, err := compile(value, fmt.Sprintf("%v/%v/%v", c.path, keyword, i))
+		if err != nil && c.err == nil {
+			c.err = err
+		}
+		schemas = append(schemas, s)
+	}
+
+	return schemas
+}
+
+func (c *compiler) schemaMap(keyword string) map[string]*Schema {
+	value, ok := c.keywords[keyword]
+	if !ok {
+		return nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		c.fail(keyword, "must be an object")
+		return nil
+	}
+
+	schemas := make(map[string]*Schema, len(object))
+	for name, value := range object {
+		s, err := compile(value, c.path+"/"+keyword+"/"+escape(name))
+		if err != nil && c.err == nil {
+			c.err = err
+		}
+		schemas[name] = s
+	}
+
+	return schemas
+}
+
+// at formats the location of a schema in the error messages.
+func at(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	return " at " + path
+}
+
+// sortedKeys returns the keys of the object in order so that the errors are reported deterministically.
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
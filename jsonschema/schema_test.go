@@ -0,0 +1,118 @@
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+const userSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "User",
+	"type": "object",
+	"required": ["name", "age"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 8},
+		"age": {"type": "integer", "minimum": 0},
+		"email": {"type": "string", "format": "email", "pattern": "^[^@]+@[^@]+$"},
+		"role": {"enum": ["admin", "member"]},
+		"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true, "maxItems": 3}
+	}
+}`
+
+func TestSchema_ValidateJSON(t *testing.T) {
+	schema, err := Parse([]byte(userSchema))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tests := []struct {
+		document string
+		expected []Error
+	}{
+		{`{"name": "getir", "age": 7, "email": "a@b", "role": "admin", "tags": ["x", "y"]}`, nil},
+		{`{"name": "getir"}`, []Error{{Path: "", Message: "missing required property \"age\""}}},
+		{`[]`, []Error{{Path: "", Message: "must be of type object, got array"}}},
+		{`{"name": "", "age": 1.5}`, []Error{
+			{Path: "/age", Message: "must be of type integer, got number"},
+			{Path: "/name", Message: "must be at least 1 characters long"},
+		}},
+		{`{"name": "getir", "age": -1, "admin": true}`, []Error{
+			{Path: "", Message: "property \"admin\" is not allowed"},
+			{Path: "/age", Message: "must be greater than or equal to 0"},
+		}},
+		{`{"name": "getir", "age": 1, "email": "getir", "role": "owner"}`, []Error{
+			{Path: "/email", Message: "must match the pattern \"^[^@]+@[^@]+$\""},
+			{Path: "/role", Message: "must be one of [\"admin\",\"member\"]"},
+		}},
+		{`{"name": "getir", "age": 1, "tags": ["x", "x", 1, "z"]}`, []Error{
+			{Path: "/tags", Message: "must have at most 3 items"},
+			{Path: "/tags", Message: "must not have duplicate items, item 1 is repeated"},
+			{Path: "/tags/2", Message: "must be of type string, got integer"},
+		}},
+	}
+
+	for _, test := range tests {
+		got, err := schema.ValidateJSON([]byte(test.document))
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("returned incorrect errors for %v. got: %v, expected: %v", test.document, got, test.expected)
+		}
+	}
+}
+
+func TestSchema_ValidateCombinators(t *testing.T) {
+	schema, err := Parse([]byte(`{
+		"oneOf": [{"type": "integer"}, {"type": "number", "multipleOf": 0.5}],
+		"not": {"const": 2}
+	}`))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tests := []struct {
+		document string
+		valid    bool
+	}{
+		{`1.5`, true},
+		{`1`, false},
+		{`2`, false},
+		{`1.25`, false},
+	}
+
+	for _, test := range tests {
+		got, err := schema.ValidateJSON([]byte(test.document))
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if valid := len(got) == 0; valid != test.valid {
+			t.Errorf("returned incorrect result for %v. got: %v, expected valid: %v", test.document, got, test.valid)
+		}
+	}
+}
+
+func TestParse_InvalidSchema(t *testing.T) {
+	tests := []string{
+		`[]`,
+		`{"type": "text"}`,
+		`{"minLength": -1}`,
+		`{"pattern": "("}`,
+		`{"properties": {"a": {"$ref": "#/definitions/a"}}}`,
+		`{"anyOf": []}`,
+		`{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`,
+		`{"patternProperties": {"^x-": true}, "additionalProperties": false}`,
+		`{"items": {"contains": {"type": "string"}}}`,
+		`{"propertyNames": {"maxLength": 3}}`,
+		`{"dependentRequired": {"a": ["b"]}}`,
+	}
+
+	for _, test := range tests {
+		if _, err := Parse([]byte(test)); err == nil {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: an error", test, err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validate validates the value at the path against the schema.
+func (s *Schema) validate(value interface{}, path string) []Error {
+	if s.always != nil {
+		if *s.always {
+			return nil
+		}
+		return []Error{{Path: path, Message: "no value is allowed"}}
+	}
+
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.types) > 0 && !hasType(value, s.types) {
+		// the other keywords are not meaningful for a value of another type.
+		fail("must be of type %v, got %v", strings.Join(s.types, " or "), typeOf(value))
+		return errs
+	}
+
+	if s.enum != nil && !contains(s.enum, value) {
+		fail("must be one of %v", format(s.enum))
+	}
+
+	if s.constant != nil && !reflect.DeepEqual(*s.constant, value) {
+		fail("must be %v", format(*s.constant))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(v, path)...)
+	case []interface{}:
+		errs = append(errs, s.validateArray(v, path)...)
+	case float64:
+		errs = append(errs, s.validateNumber(v, path)...)
+	case string:
+		errs = append(errs, s.validateString(v, path)...)
+	}
+
+	for _, sub := range s.allOf {
+		errs = append(errs, sub.validate(value, path)...)
+	}
+
+	if s.anyOf != nil && matching(s.anyOf, value, path) == 0 {
+		fail("must match at least one of the schemas in anyOf")
+	}
+
+	if s.oneOf != nil {
+		if matched := matching(s.oneOf, value, path); matched != 1 {
+			fail("must match exactly one of the schemas in oneOf, matched %v", matched)
+		}
+	}
+
+	if s.not != nil && len(s.not.validate(value, path)) == 0 {
+		fail("must not match the schema in not")
+	}
+
+	return errs
+}
+
+func (s *Schema) validateObject(object map[string]interface{}, path string) []Error {
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range s.required {
+		if _, ok := object[name]; !ok {
+			fail("missing required property %q", name)
+		}
+	}
+
+	if s.minProperties != nil && len(object) < *s.minProperties {
+		fail("must have at least %v properties", *s.minProperties)
+	}
+
+	if s.maxProperties != nil && len(object) > *s.maxProperties {
+		fail("must have at most %v properties", *s.maxProperties)
+	}
+
+	for _, name := range sortedKeys(object) {
+		propertyPath := path + "/" + escape(name)
+		if property, ok := s.properties[name]; ok {
+			errs = append(errs, property.validate(object[name], propertyPath)...)
+		} else if s.additionalProperties != nil {
+			if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+				fail("property %q is not allowed", name)
+				continue
+			}
+			errs = append(errs, s.additionalProperties.validate(object[name], propertyPath)...)
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) validateArray(array []interface{}, path string) []Error {
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.minItems != nil && len(array) < *s.minItems {
+		fail("must have at least %v items", *s.minItems)
+	}
+
+	if s.maxItems != nil && len(array) > *s.maxItems {
+		fail("must have at most %v items", *s.maxItems)
+	}
+
+	if s.uniqueItems {
+		for i := range array {
+			if contains(array[:i], array[i]) {
+				fail("must not have duplicate items, item %v is repeated", i)
+				break
+			}
+		}
+	}
+
+	if s.items != nil {
+		for i, item := range array {
+			errs = append(errs, s.items.validate(item, path+"/"+strconv.Itoa(i))...)
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) validateNumber(number float64, path string) []Error {
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.minimum != nil && number < *s.minimum {
+		fail("must be greater than or equal to %v", *s.minimum)
+	}
+
+	if s.maximum != nil && number > *s.maximum {
+		fail("must be less than or equal to %v", *s.maximum)
+	}
+
+	if s.exclusiveMinimum != nil && number <= *s.exclusiveMinimum {
+		fail("must be greater than %v", *s.exclusiveMinimum)
+	}
+
+	if s.exclusiveMaximum != nil && number >= *s.exclusiveMaximum {
+		fail("must be less than %v", *s.exclusiveMaximum)
+	}
+
+	if s.multipleOf != nil {
+		quotient := number / *s.multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			fail("must be a multiple of %v", *s.multipleOf)
+		}
+	}
+
+	return errs
+}
+
+func (s *Schema) validateString(str string, path string) []Error {
+	var errs []Error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := utf8.RuneCountInString(str)
+	if s.minLength != nil && length < *s.minLength {
+		fail("must be at least %v characters long", *s.minLength)
+	}
+
+	if s.maxLength != nil && length > *s.maxLength {
+		fail("must be at most %v characters long", *s.maxLength)
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		fail("must match the pattern %q", s.pattern.String())
+	}
+
+	return errs
+}
+
+// matching returns the number of the schemas which the value is valid against.
+func matching(schemas []*Schema, value interface{}, path string) int {
+	matched := 0
+	for _, s := range schemas {
+		if len(s.validate(value, path)) == 0 {
+			matched++
+		}
+	}
+
+	return matched
+}
+
+// hasType checks whether the value is one of the types.
+func hasType(value interface{}, types []string) bool {
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// typeOf returns the JSON type of the value, the numbers
+// without a fractional part are considered as integers.
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func contains(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// format formats a value of the schema as JSON in the error messages.
+func format(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(data)
+}
+
+// escape escapes a property name as a JSON Pointer reference token.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
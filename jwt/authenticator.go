@@ -0,0 +1,101 @@
+package jwt
+
+import (
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Authenticator authenticates the clients by the tokens in Authorization header with
+// the Bearer scheme. The tokens must be signed by a key of Keys, issued by Issuer for
+// Audience and must not be expired, ClockSkew is tolerated on the time claims.
+// The client is granted the scopes in ScopeClaim and restricted to the namespace
+// in NamespaceClaim if it is set, the tokens without the claim are not restricted.
+type Authenticator struct {
+	Keys           KeySet
+	Issuer         string
+	Audience       string
+	ClockSkew      time.Duration
+	ScopeClaim     string
+	NamespaceClaim string
+
+	now func() time.Time
+}
+
+// Authenticate authenticates the client by the bearer token.
+func (a Authenticator) Authenticate(req *http.Request) (api.Principal, error) {
+	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+		return api.Principal{}, api.ErrNoCredentials
+	}
+
+	keys, err := a.Keys.Keys()
+	if err != nil {
+		return api.Principal{}, err
+	}
+
+	claims, err := parse(strings.TrimSpace(parts[1]), keys)
+	if err != nil {
+		return api.Principal{}, fmt.Errorf("%w: %v", api.ErrInvalidCredentials, err)
+	}
+
+	if err := a.validate(claims); err != nil {
+		return api.Principal{}, fmt.Errorf("%w: %v", api.ErrInvalidCredentials, err)
+	}
+
+	principal := api.Principal{Subject: "jwt:" + claims.String("sub"), Scopes: claims.Strings(a.ScopeClaim)}
+	if a.NamespaceClaim != "" {
+		principal.Namespace = claims.String(a.NamespaceClaim)
+		if principal.Namespace != "" && !api.ValidNamespace(principal.Namespace) {
+			return api.Principal{}, fmt.Errorf("%w: namespace %q of the token is not valid", api.ErrInvalidCredentials, principal.Namespace)
+		}
+	}
+
+	return principal, nil
+}
+
+// validate checks the issuer, the audience and the validity period of the token.
+func (a Authenticator) validate(claims Claims) error {
+	if iss := claims.String("iss"); iss != a.Issuer {
+		return fmt.Errorf("token is issued by %q", iss)
+	}
+
+	if !contains(claims.Audience(), a.Audience) {
+		return fmt.Errorf("token is not issued for %q", a.Audience)
+	}
+
+	now := time.Now()
+	if a.now != nil {
+		now = a.now()
+	}
+
+	exp, ok := claims.Time("exp")
+	if !ok {
+		return fmt.Errorf("token does not have an expiry")
+	}
+	if !now.Before(exp.Add(a.ClockSkew)) {
+		return fmt.Errorf("token is expired at %v", exp.UTC())
+	}
+
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(a.ClockSkew).Before(nbf) {
+		return fmt.Errorf("token is not valid before %v", nbf.UTC())
+	}
+
+	if iat, ok := claims.Time("iat"); ok && now.Add(a.ClockSkew).Before(iat) {
+		return fmt.Errorf("token is issued in the future at %v", iat.UTC())
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,191 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sign returns a token of the claims signed by the private key, the key is a *rsa.PrivateKey,
+// an *ecdsa.PrivateKey or the secret for HS256.
+func sign(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	h, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	c, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		r, s, signErr := ecdsa.Sign(rand.Reader, key, digest[:])
+		signature, err = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...), signErr
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	}
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	secret := []byte("a secret shared with the issuer")
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	authenticator := Authenticator{
+		Keys: StaticKeys{
+			{Id: "rsa", Algorithm: RS256, Public: &rsaKey.PublicKey},
+			{Id: "ec", Algorithm: ES256, Public: &ecKey.PublicKey},
+			{Algorithm: HS256, Secret: secret},
+		},
+		Issuer:         "https://auth.getir.com",
+		Audience:       "g-case-challenge",
+		ClockSkew:      time.Minute,
+		ScopeClaim:     "scope",
+		NamespaceClaim: "namespace",
+		now:            func() time.Time { return now },
+	}
+
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":       "https://auth.getir.com",
+			"aud":       []string{"g-case-challenge", "another-service"},
+			"sub":       "billing",
+			"exp":       now.Add(time.Hour).Unix(),
+			"iat":       now.Unix(),
+			"scope":     "records:read kv:read",
+			"namespace": "billing",
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(c, name)
+				continue
+			}
+			c[name] = value
+		}
+		return c
+	}
+
+	granted := api.Principal{Subject: "jwt:billing", Scopes: []string{"records:read", "kv:read"}, Namespace: "billing"}
+	tests := []struct {
+		name          string
+		authorization string
+		principal     api.Principal
+		err           error
+	}{
+		{"RS256", "Bearer " + sign(t, RS256, "rsa", rsaKey, claims(nil)), granted, nil},
+		{"ES256", "Bearer " + sign(t, ES256, "ec", ecKey, claims(nil)), granted, nil},
+		{"HS256", "bearer " + sign(t, HS256, "", secret, claims(nil)), granted, nil},
+		{"audience string", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"aud": "g-case-challenge"})), granted, nil},
+		{"scope list", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"scope": []string{"records:read", "kv:read"}})), granted, nil},
+		{"expired within clock skew", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()})), granted, nil},
+		{"no credentials", "", api.Principal{}, api.ErrNoCredentials},
+		{"basic scheme", "Basic YWxhZGRpbjpvcGVuc2VzYW1l", api.Principal{}, api.ErrNoCredentials},
+		{"malformed", "Bearer token", api.Principal{}, api.ErrInvalidCredentials},
+		{"expired", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})), api.Principal{}, api.ErrInvalidCredentials},
+		{"without expiry", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"exp": nil})), api.Principal{}, api.ErrInvalidCredentials},
+		{"not valid yet", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()})), api.Principal{}, api.ErrInvalidCredentials},
+		{"another issuer", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"iss": "https://auth.example.com"})), api.Principal{}, api.ErrInvalidCredentials},
+		{"another audience", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"aud": "another-service"})), api.Principal{}, api.ErrInvalidCredentials},
+		{"without namespace", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"namespace": nil})), api.Principal{Subject: "jwt:billing", Scopes: []string{"records:read", "kv:read"}}, nil},
+		{"invalid namespace", "Bearer " + sign(t, HS256, "", secret, claims(map[string]interface{}{"namespace": "billing:*"})), api.Principal{}, api.ErrInvalidCredentials},
+		{"another secret", "Bearer " + sign(t, HS256, "", []byte("another secret"), claims(nil)), api.Principal{}, api.ErrInvalidCredentials},
+		{"unknown key id", "Bearer " + sign(t, RS256, "ec", rsaKey, claims(nil)), api.Principal{}, api.ErrInvalidCredentials},
+		// the public key is known by anyone, it must not be accepted as the secret.
+		{"public key as secret", "Bearer " + sign(t, HS256, "rsa", rsaKey.PublicKey.N.Bytes(), claims(nil)), api.Principal{}, api.ErrInvalidCredentials},
+		{"none algorithm", "Bearer " + base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"billing"}`)) + ".", api.Principal{}, api.ErrInvalidCredentials},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/in-memory", nil)
+		if test.authorization != "" {
+			req.Header.Set("Authorization", test.authorization)
+		}
+
+		principal, err := authenticator.Authenticate(req)
+		if !errors.Is(err, test.err) {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: %v", test.name, err, test.err)
+		}
+
+		if !reflect.DeepEqual(principal, test.principal) {
+			t.Errorf("returned incorrect principal for %v. got: %v, expected: %v", test.name, principal, test.principal)
+		}
+	}
+}
+
+func TestAuthenticator_NestedScopeClaim(t *testing.T) {
+	secret := []byte("secret")
+	authenticator := Authenticator{
+		Keys:       StaticKeys{{Algorithm: HS256, Secret: secret}},
+		Issuer:     "issuer",
+		Audience:   "audience",
+		ScopeClaim: "realm_access.roles",
+	}
+
+	token := sign(t, HS256, "", secret, map[string]interface{}{
+		"iss":          "issuer",
+		"aud":          "audience",
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]interface{}{"roles": []string{"kv:read", "kv:write"}},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/in-memory", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	principal, err := authenticator.Authenticate(req)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	expected := api.Principal{Subject: "jwt:", Scopes: []string{"kv:read", "kv:write"}}
+	if !reflect.DeepEqual(principal, expected) {
+		t.Errorf("returned incorrect principal. got: %v, expected: %v", principal, expected)
+	}
+}
+
+func TestAuthenticator_KeysError(t *testing.T) {
+	authenticator := Authenticator{Keys: NewJwksFile("missing.json"), Issuer: "issuer", Audience: "audience"}
+
+	req := httptest.NewRequest(http.MethodGet, "/in-memory", nil)
+	req.Header.Set("Authorization", "Bearer token")
+
+	// the tokens which cannot be verified are not considered invalid.
+	if _, err := authenticator.Authenticate(req); err == nil || errors.Is(err, api.ErrInvalidCredentials) {
+		t.Errorf("returned incorrect error. got: %v, expected: an error reading the keys", err)
+	}
+}
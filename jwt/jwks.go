@@ -0,0 +1,230 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+)
+
+// Signing algorithms.
+const (
+	RS256 = "RS256"
+	ES256 = "ES256"
+	HS256 = "HS256"
+)
+
+// Key is a key verifying the signatures of the tokens signed by Algorithm.
+// Public is the key of RS256 and ES256, and Secret is the key of HS256.
+type Key struct {
+	Id        string
+	Algorithm string
+	Public    crypto.PublicKey
+	Secret    []byte
+}
+
+// KeySet provides the keys verifying the tokens.
+type KeySet interface {
+	Keys() ([]Key, error)
+}
+
+// StaticKeys is a KeySet of the keys which never change, e.g. the configured secret.
+type StaticKeys []Key
+
+// Keys returns the keys.
+func (k StaticKeys) Keys() ([]Key, error) {
+	return k, nil
+}
+
+// KeySets is a KeySet of the keys of all the sets, e.g. a JWKS file and the configured secret.
+type KeySets []KeySet
+
+// Keys returns the keys of all the sets.
+func (s KeySets) Keys() ([]Key, error) {
+	var keys []Key
+	for _, set := range s {
+		k, err := set.Keys()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k...)
+	}
+
+	return keys, nil
+}
+
+// JwksFile is a KeySet of the keys in a JSON Web Key Set file. The file is read
+// again when it is modified, so that the keys can be rotated without restarting
+// the application. If the modified file is not valid, the keys read last are kept.
+type JwksFile struct {
+	path string
+
+	mu      sync.Mutex
+	keys    []Key
+	err     error
+	modTime time.Time
+	size    int64
+}
+
+// NewJwksFile creates a KeySet of the keys in the file at the path.
+func NewJwksFile(path string) *JwksFile {
+	return &JwksFile{path: path}
+}
+
+// Keys returns the keys in the file, the file is read if it is modified since it is read last.
+func (f *JwksFile) Keys() ([]Key, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		if f.keys != nil {
+			log.Printf("Error on reading the JWKS file, the keys read last are used: %v", err)
+			return f.keys, nil
+		}
+		return nil, err
+	}
+
+	if f.modTime.IsZero() || !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+		// the file is not read again until it is modified even if it is not valid.
+		f.modTime, f.size = info.ModTime(), info.Size()
+
+		keys, err := readJwks(f.path)
+		if err != nil && f.keys != nil {
+			log.Printf("Error on reading the JWKS file, the keys read last are used: %v", err)
+		}
+		if err == nil {
+			f.keys = keys
+		}
+		f.err = err
+	}
+
+	if f.keys == nil {
+		return nil, f.err
+	}
+
+	return f.keys, nil
+}
+
+// jwk is a JSON Web Key of RFC 7517, the members of the RSA, EC and symmetric keys are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// readJwks reads the signing keys in the JWKS file, the encryption
+// keys and the keys of other algorithms are skipped.
+func readJwks(path string) ([]Key, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("error on parsing the JWKS file: %w", err)
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := parseJwk(k)
+		if errors.Is(err, errUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error on parsing the key %q of the JWKS file: %w", k.Kid, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+var errUnsupportedKey = errors.New("unsupported key")
+
+func parseJwk(k jwk) (Key, error) {
+	key := Key{Id: k.Kid}
+	switch {
+	case k.Kty == "RSA" && (k.Alg == "" || k.Alg == RS256):
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return Key{}, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return Key{}, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return Key{}, errors.New("exponent is not valid")
+		}
+
+		key.Algorithm, key.Public = RS256, &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case k.Kty == "EC" && k.Crv == "P-256" && (k.Alg == "" || k.Alg == ES256):
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return Key{}, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return Key{}, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return Key{}, errors.New("point is not on the curve")
+		}
+
+		key.Algorithm, key.Public = ES256, &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	case k.Kty == "oct" && (k.Alg == "" || k.Alg == HS256):
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return Key{}, err
+		}
+		if len(secret) == 0 {
+			return Key{}, errors.New("secret is empty")
+		}
+
+		key.Algorithm, key.Secret = HS256, secret
+	default:
+		return Key{}, errUnsupportedKey
+	}
+
+	return key, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("value is empty")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// writeJwks writes the keys to the file and moves its modification time so that it is read again.
+func writeJwks(t *testing.T, path string, keys ...map[string]string) {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	modTime := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	}
+
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+}
+
+func TestJwksFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJwks(t, path,
+		map[string]string{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		map[string]string{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes())},
+		map[string]string{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": encode([]byte("secret"))},
+		map[string]string{"kty": "RSA", "kid": "encryption", "use": "enc", "n": encode(rsaKey.N.Bytes()), "e": "AQAB"},
+		map[string]string{"kty": "OKP", "kid": "ed25519", "crv": "Ed25519", "x": encode(make([]byte, 32))},
+	)
+
+	file := NewJwksFile(path)
+	keys, err := file.Keys()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("returned incorrect number of keys. got: %v, expected: %v", len(keys), 3)
+	}
+
+	for i, expected := range []Key{
+		{Id: "rsa", Algorithm: RS256, Public: &rsaKey.PublicKey},
+		{Id: "ec", Algorithm: ES256, Public: &ecKey.PublicKey},
+		{Id: "hmac", Algorithm: HS256, Secret: []byte("secret")},
+	} {
+		if keys[i].Id != expected.Id || keys[i].Algorithm != expected.Algorithm {
+			t.Errorf("returned incorrect key. got: %v %v, expected: %v %v", keys[i].Id, keys[i].Algorithm, expected.Id, expected.Algorithm)
+		}
+	}
+
+	if public, ok := keys[0].Public.(*rsa.PublicKey); !ok || !public.Equal(&rsaKey.PublicKey) {
+		t.Errorf("returned incorrect RSA key. got: %v, expected: %v", keys[0].Public, &rsaKey.PublicKey)
+	}
+
+	if public, ok := keys[1].Public.(*ecdsa.PublicKey); !ok || !public.Equal(&ecKey.PublicKey) {
+		t.Errorf("returned incorrect EC key. got: %v, expected: %v", keys[1].Public, &ecKey.PublicKey)
+	}
+}
+
+func TestJwksFile_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJwks(t, path, map[string]string{"kty": "oct", "kid": "first", "k": encode([]byte("first"))})
+
+	file := NewJwksFile(path)
+	if keys, err := file.Keys(); err != nil || len(keys) != 1 || keys[0].Id != "first" {
+		t.Fatalf("Error on testing: %v %v", keys, err)
+	}
+
+	// the keys are rotated.
+	writeJwks(t, path, map[string]string{"kty": "oct", "kid": "second", "k": encode([]byte("second"))})
+	keys, err := file.Keys()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if len(keys) != 1 || keys[0].Id != "second" {
+		t.Errorf("returned incorrect keys. got: %v, expected: the rotated key", keys)
+	}
+
+	// the keys read last are kept while the file is not valid.
+	writeJwks(t, path, map[string]string{"kty": "EC", "kid": "third", "crv": "P-256", "x": encode([]byte{1}), "y": encode([]byte{2})})
+	keys, err = file.Keys()
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if len(keys) != 1 || keys[0].Id != "second" {
+		t.Errorf("returned incorrect keys. got: %v, expected: the keys read last", keys)
+	}
+
+	if _, err := NewJwksFile(path).Keys(); err == nil {
+		t.Errorf("returned incorrect error. got: %v, expected: an error for the invalid key", err)
+	}
+}
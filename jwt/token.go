@@ -0,0 +1,186 @@
+// Package jwt authenticates the clients by the JSON Web Tokens they send as bearer tokens.
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrMalformedToken is returned when a token is not a JWS in compact serialization.
+var ErrMalformedToken = errors.New("malformed token")
+
+// ErrInvalidSignature is returned when no key verifies the signature of a token.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Claims are the claims of a token.
+type Claims map[string]interface{}
+
+type header struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Crit []string `json:"crit"`
+}
+
+// parse verifies the signature of the token by the keys and returns its claims.
+// The keys which are not of the algorithm of the token are never used, so that a
+// public key cannot be used as the secret of a token signed by HS256.
+func parse(token string, keys []Key) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: token must consist of 3 parts", ErrMalformedToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformedToken, err)
+	}
+
+	switch h.Alg {
+	case RS256, ES256, HS256:
+	default:
+		return nil, fmt.Errorf("%w: algorithm %q is not supported", ErrMalformedToken, h.Alg)
+	}
+
+	if len(h.Crit) > 0 {
+		return nil, fmt.Errorf("%w: critical header parameters %v are not supported", ErrMalformedToken, h.Crit)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformedToken, err)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if key.Algorithm != h.Alg || (h.Kid != "" && key.Id != "" && key.Id != h.Kid) {
+			continue
+		}
+
+		if verify(key, signed, signature) {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return nil, fmt.Errorf("%w: no %v key with id %q verifies the token", ErrInvalidSignature, h.Alg, h.Kid)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrMalformedToken, err)
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func verify(key Key, signed, signature []byte) bool {
+	digest := sha256.Sum256(signed)
+	switch key.Algorithm {
+	case RS256:
+		public, ok := key.Public.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		public, ok := key.Public.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(public, digest[:], r, s)
+	case HS256:
+		if len(key.Secret) == 0 {
+			return false
+		}
+
+		mac := hmac.New(sha256.New, key.Secret)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), signature)
+	default:
+		return false
+	}
+}
+
+// String returns the string claim with the name.
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Time returns the NumericDate claim with the name, it returns false if the claim is not a number.
+func (c Claims) Time(name string) (time.Time, bool) {
+	number, ok := c[name].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	seconds, err := number.Float64()
+	if err != nil || math.IsInf(seconds, 0) {
+		return time.Time{}, false
+	}
+
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), true
+}
+
+// Strings returns the claim at the path as a list. The nested claims are separated by dots
+// in the path, e.g. "realm_access.roles". A string claim is split by the spaces as "scope".
+func (c Claims) Strings(path string) []string {
+	var value interface{} = map[string]interface{}(c)
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Audience returns the "aud" claim, which is either a string or a list.
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	default:
+		return c.Strings("aud")
+	}
+}
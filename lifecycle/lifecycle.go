@@ -0,0 +1,91 @@
+// Package lifecycle starts and stops the components of the application in order.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Hook represents a component of the application with its start and stop functions.
+// The functions are optional, a component may only need to be stopped.
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Manager starts the components in the order they are registered
+// and stops them in the reverse order, so that a component is stopped
+// before the components it depends on.
+type Manager struct {
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+// Register appends the hook of a component. The components must be
+// registered after the components they depend on.
+func (m *Manager) Register(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook)
+}
+
+// Start starts the components in order. If a component fails to start,
+// the components already started are stopped and the error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for m.started < len(m.hooks) {
+		hook := m.hooks[m.started]
+		if hook.Start != nil {
+			log.Printf("Starting %v.", hook.Name)
+			if err := hook.Start(ctx); err != nil {
+				startErr := fmt.Errorf("error on starting %v: %w", hook.Name, err)
+				if stopErr := m.stop(ctx); stopErr != nil {
+					return fmt.Errorf("%v; %v", startErr, stopErr)
+				}
+				return startErr
+			}
+		}
+		m.started++
+	}
+
+	return nil
+}
+
+// Stop stops the started components in the reverse order within the deadline of the context.
+// All the components are stopped even if some of them fail, the errors are returned together.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stop(ctx)
+}
+
+func (m *Manager) stop(ctx context.Context) error {
+	var errs []string
+	for ; m.started > 0; m.started-- {
+		hook := m.hooks[m.started-1]
+		if hook.Stop == nil {
+			continue
+		}
+
+		log.Printf("Stopping %v.", hook.Name)
+		if err := hook.Stop(ctx); err != nil {
+			log.Printf("Error on stopping %v: %v", hook.Name, err)
+			errs = append(errs, fmt.Sprintf("error on stopping %v: %v", hook.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recorder records the calls of the hooks in order.
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) hook(name string, startErr error, stopErr error) Hook {
+	return Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			r.calls = append(r.calls, "start "+name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			r.calls = append(r.calls, "stop "+name)
+			return stopErr
+		},
+	}
+}
+
+func TestManager_StartStop(t *testing.T) {
+	r := &recorder{}
+	var m Manager
+	m.Register(r.hook("mongo", nil, nil))
+	m.Register(r.hook("redis", nil, nil))
+	m.Register(r.hook("http", nil, nil))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	expected := []string{"start mongo", "start redis", "start http", "stop http", "stop redis", "stop mongo"}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Errorf("returned incorrect calls. got: %v, expected: %v", r.calls, expected)
+	}
+}
+
+func TestManager_StartFailure(t *testing.T) {
+	r := &recorder{}
+	failure := errors.New("connection refused")
+	var m Manager
+	m.Register(r.hook("mongo", nil, nil))
+	m.Register(r.hook("redis", failure, nil))
+	m.Register(r.hook("http", nil, nil))
+
+	err := m.Start(context.Background())
+	if !errors.Is(err, failure) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, failure)
+	}
+
+	// the failed component is not stopped since it is not started.
+	expected := []string{"start mongo", "start redis", "stop mongo"}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Errorf("returned incorrect calls. got: %v, expected: %v", r.calls, expected)
+	}
+
+	if err := m.Stop(context.Background()); err != nil || len(r.calls) != len(expected) {
+		t.Errorf("stopped the components again. got: %v, expected: %v", r.calls, expected)
+	}
+}
+
+func TestManager_StopFailure(t *testing.T) {
+	r := &recorder{}
+	var m Manager
+	m.Register(r.hook("mongo", nil, nil))
+	m.Register(r.hook("http", nil, errors.New("deadline exceeded")))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	err := m.Stop(context.Background())
+	if err == nil || err.Error() != "error on stopping http: deadline exceeded" {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, "error on stopping http: deadline exceeded")
+	}
+
+	expected := []string{"start mongo", "start http", "stop http", "stop mongo"}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Errorf("returned incorrect calls. got: %v, expected: %v", r.calls, expected)
+	}
+}
@@ -1,52 +1,483 @@
 package main
 
 import (
+	"context"
+	"github.com/go-redis/redis/v8"
 	"github.com/skarakasoglu/g-case-challenge/api"
+	"github.com/skarakasoglu/g-case-challenge/apikey"
+	"github.com/skarakasoglu/g-case-challenge/certs"
+	"github.com/skarakasoglu/g-case-challenge/concurrency"
 	"github.com/skarakasoglu/g-case-challenge/config"
+	"github.com/skarakasoglu/g-case-challenge/health"
 	"github.com/skarakasoglu/g-case-challenge/inmem"
+	"github.com/skarakasoglu/g-case-challenge/jwt"
+	"github.com/skarakasoglu/g-case-challenge/lifecycle"
+	"github.com/skarakasoglu/g-case-challenge/metrics"
 	"github.com/skarakasoglu/g-case-challenge/mongodb"
+	"github.com/skarakasoglu/g-case-challenge/openapi"
+	"github.com/skarakasoglu/g-case-challenge/queue"
+	"github.com/skarakasoglu/g-case-challenge/ratelimit"
 	"github.com/skarakasoglu/g-case-challenge/record"
 	rediscl "github.com/skarakasoglu/g-case-challenge/redis"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("error on running %v command: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	appConfig := config.ReadFromEnvironmentVariables()
 	dbConfig := appConfig.Database
 
+	// the components are started in the order they are registered and
+	// stopped in the reverse order, so that the HTTP server is drained
+	// before the databases it depends on are disconnected.
+	var components lifecycle.Manager
+	appMetrics := metrics.New()
+
 	conn := mongodb.NewConnection(dbConfig.ConnectionString, dbConfig.DefaultDatabaseName)
-	conn.Connect()
-	defer conn.Disconnect()
+	conn.PoolMonitor = appMetrics.MongoPool()
+	components.Register(lifecycle.Hook{Name: "MongoDB connection", Start: conn.Connect, Stop: conn.Disconnect})
+
+	var recordDao record.Dao = record.MongoDao{Db: conn, OnError: appMetrics.DaoError("record.MongoDao")}
+	if appConfig.Records.BreakerThreshold > 0 {
+		recordDao = &record.CircuitBreaker{
+			Dao:       recordDao,
+			Threshold: appConfig.Records.BreakerThreshold,
+			Cooldown:  appConfig.Records.BreakerCooldown,
+		}
+	}
+
+	// the identical queries in flight share a single aggregation, and the last records
+	// found for the filters are served while MongoDB is not available.
+	recordService := record.Service{Dao: recordDao, Queries: &record.QueryGroup{OnCoalesced: appMetrics.Coalesced("records")}}
+	if appConfig.Records.StaleMaxEntries > 0 {
+		recordService.Stale = record.NewStaleStore(appConfig.Records.StaleMaxEntries, appConfig.Records.StaleMaxAge)
+	}
+	var recordRepository record.Repository = recordService
+
+	// the records of the filters queried recently are served from Redis, the services
+	// writing the records invalidate them through "/admin/records/cache". The cache can be
+	// kept in a database of its own, apart from the keys written by the clients.
+	var recordsCache record.Invalidator
+	if appConfig.Records.CacheTtl > 0 {
+		recordsCacheCl := rediscl.NewClient(appConfig.Records.CacheRedisConnectionString)
+		recordsCacheCl.AddHook(appMetrics.RedisErrors("record.RedisCacheDao"))
+		appMetrics.RegisterRedisPool("records", recordsCacheCl)
+		components.Register(lifecycle.Hook{Name: "records cache Redis client", Stop: func(ctx context.Context) error {
+			return recordsCacheCl.Close()
+		}})
 
-	recordDao := record.MongoDao{Db: conn}
-	recordService := record.Service{Dao: recordDao}
-	recordController := record.Controller{Repository: recordService}
+		cachedRepository := record.CachedRepository{
+			Repository: recordRepository,
+			Dao:        record.RedisCacheDao{Db: recordsCacheCl},
+			Ttl:        appConfig.Records.CacheTtl,
+		}
+		recordRepository, recordsCache = cachedRepository, cachedRepository
+	}
+	recordController := record.Controller{Repository: recordRepository}
 
+	// the in-memory dao has a client of its own so that its
+	// errors and its connection pool are measured separately.
 	redisCl := rediscl.NewClient(appConfig.RedisConnectionString)
+	inMemoryCl := rediscl.NewClient(appConfig.RedisConnectionString)
+	inMemoryCl.AddHook(appMetrics.RedisErrors("inmem.RedisDao"))
+	appMetrics.RegisterRedisPool("queue", redisCl)
+	appMetrics.RegisterRedisPool("inmem", inMemoryCl)
+	for _, client := range []*redis.Client{redisCl, inMemoryCl} {
+		client := client
+		components.Register(lifecycle.Hook{
+			Name: "Redis client",
+			Start: func(ctx context.Context) error {
+				return client.Ping(ctx).Err()
+			},
+			Stop: func(ctx context.Context) error {
+				return client.Close()
+			},
+		})
+	}
+
+	inMemoryDao := inmem.RedisDao{Db: inMemoryCl, CompressionThreshold: appConfig.InMemory.CompressionThreshold}
+	if appConfig.InMemory.EncryptionKeyfile != "" {
+		keyring, err := inmem.LoadKeyring(appConfig.InMemory.EncryptionKeyfile)
+		if err != nil {
+			log.Fatalf("error on loading the encryption keyfile: %v", err)
+		}
+		inMemoryDao.Keyring = keyring
 
-	inMemoryDao := inmem.RedisDao{Db: redisCl}
-	inMemoryService := inmem.Service{Dao: inMemoryDao}
-	inMemoryController := inmem.Controller{ Repository: inMemoryService}
+		// the values written before a key rotation are re-encrypted in the background,
+		// they remain readable by the old keys in the meantime.
+		reencryptCtx, cancelReencrypt := context.WithCancel(context.Background())
+		reencryptDone := make(chan struct{})
+		components.Register(lifecycle.Hook{
+			Name: "in-memory re-encryption",
+			Start: func(ctx context.Context) error {
+				go func() {
+					defer close(reencryptDone)
+					reencrypted, err := inMemoryDao.Reencrypt(reencryptCtx)
+					if err != nil {
+						log.Printf("Error while re-encrypting the in-memory values: %v", err)
+					}
+					log.Printf("Re-encrypted %v in-memory values by the key %v.", reencrypted, keyring.Primary())
+				}()
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				cancelReencrypt()
+				select {
+				case <-reencryptDone:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		})
+	}
+	quotas := inmem.Quotas{Default: inmem.Quota{
+		MaxKeys:  int64(appConfig.InMemory.QuotaMaxKeys),
+		MaxBytes: int64(appConfig.InMemory.QuotaMaxBytes),
+	}}
+	if appConfig.InMemory.QuotaFile != "" {
+		var err error
+		quotas, err = inmem.LoadQuotas(appConfig.InMemory.QuotaFile, quotas.Default)
+		if err != nil {
+			log.Fatalf("error on loading the quota file: %v", err)
+		}
+	}
 
+	var acl *inmem.Acl
+	if appConfig.InMemory.AclFile != "" {
+		var err error
+		acl, err = inmem.LoadAcl(appConfig.InMemory.AclFile)
+		if err != nil {
+			log.Fatalf("error on loading the acl file: %v", err)
+		}
+	}
+
+	// the in-memory handlers are built per request for the namespace of the request,
+	// the dao given to them prefixes the keys by the namespace and enforces its quota.
+	namespaced := func(handler func(dao inmem.RedisDao) http.Handler) http.Handler {
+		return inmem.NamespaceHandler{Dao: inMemoryDao, Quotas: quotas, Handler: handler}
+	}
+	// the compiled schemas are shared by the requests of all the namespaces.
+	schemaCache := &inmem.SchemaCache{Dao: inMemoryDao}
+	inMemoryService := func(dao inmem.RedisDao) inmem.Service {
+		return inmem.Service{Dao: dao, MaxValueSize: appConfig.InMemory.MaxValueSize, Schemas: schemaCache}
+	}
+	maxValueSize := appConfig.InMemory.MaxValueSize
+	inMemoryController := namespaced(func(dao inmem.RedisDao) http.Handler {
+		return inmem.Controller{Repository: inMemoryService(dao), Acl: acl, MaxValueSize: maxValueSize}
+	})
+	keyController := namespaced(func(dao inmem.RedisDao) http.Handler {
+		return inmem.KeyController{Repository: inMemoryService(dao), Acl: acl, MaxValueSize: maxValueSize}
+	})
+	hashController := namespaced(func(dao inmem.RedisDao) http.Handler {
+		return inmem.HashController{Repository: inmem.HashService{Dao: dao}, Acl: acl, MaxValueSize: maxValueSize}
+	})
+	listController := namespaced(func(dao inmem.RedisDao) http.Handler {
+		return inmem.ListController{Repository: inmem.ListService{Dao: dao}, Acl: acl, MaxValueSize: maxValueSize}
+	})
+	setController := namespaced(func(dao inmem.RedisDao) http.Handler {
+		return inmem.SetController{Repository: inmem.SetService{Dao: dao}, Acl: acl, MaxValueSize: maxValueSize}
+	})
+	leaderboardController := namespaced(func(dao inmem.RedisDao) http.Handler {
+		return inmem.LeaderboardController{Repository: inmem.LeaderboardService{Dao: dao}, Acl: acl, MaxValueSize: maxValueSize}
+	})
+	namespaceController := namespaced(func(dao inmem.RedisDao) http.Handler {
+		return inmem.NamespaceController{Repository: inmem.NamespaceService{Dao: dao}, Acl: acl}
+	})
+
+	queueService := queue.Service{
+		Dao:               queue.RedisDao{Db: redisCl},
+		MaxAttempts:       appConfig.Queue.MaxAttempts,
+		VisibilityTimeout: appConfig.Queue.VisibilityTimeout,
+		RetryDelay:        appConfig.Queue.RetryDelay,
+	}
+	queueController := queue.Controller{Repository: queueService}
+	snapshotController := inmem.SnapshotController{Repository: inmem.SnapshotService{Dao: inMemoryDao}}
+	schemaController := inmem.SchemaController{Repository: inmem.SchemaService{Dao: inMemoryDao}}
+
+	// the endpoints are measured by their paths, the metrics of the in-memory
+	// endpoints serving the keys in the path are labelled by "/in-memory/". The keys are served
+	// under uncleaned paths since they may contain "//" or "..".
 	endpoints := []api.Endpoint{
 		{ Path: "/records", Handler: recordController},
 		{ Path: "/in-memory", Handler: inMemoryController},
+		{ Path: "/in-memory/", Handler: keyController, Uncleaned: true},
+		{ Path: "/in-memory/hashes", Handler: hashController},
+		{ Path: "/in-memory/lists", Handler: listController},
+		{ Path: "/in-memory/sets", Handler: setController},
+		{ Path: "/in-memory/leaderboards", Handler: leaderboardController},
+		{ Path: "/namespaces/", Handler: namespaceController},
+		{ Path: "/queues/", Handler: queueController},
+		{ Path: "/admin/in-memory/schemas", Handler: schemaController},
+		{ Path: "/openapi.json", Handler: openapi.Handler()},
+		{ Path: "/docs", Handler: openapi.DocsHandler()},
+	}
+	if recordsCache != nil {
+		endpoints = append(endpoints, api.Endpoint{Path: "/admin/records/cache", Handler: record.CacheController{Cache: recordsCache}})
+	}
+	for i := range endpoints {
+		endpoints[i].Middlewares = append(endpoints[i].Middlewares, appMetrics.Instrument(endpoints[i].Path))
+	}
+
+	// the requests are shed before they are authenticated so that an overloaded replica
+	// does no work for the requests it can not handle. The endpoints share the memory and
+	// the connections of the replica, so they share a limit which admits the requests by
+	// their priorities, the record queries can take a share of it only.
+	if appConfig.Concurrency.MaxLimit > 0 {
+		limiter := concurrency.NewLimiter(concurrency.Options{
+			InitialLimit:     appConfig.Concurrency.InitialLimit,
+			MinLimit:         appConfig.Concurrency.MinLimit,
+			MaxLimit:         appConfig.Concurrency.MaxLimit,
+			LatencyThreshold: appConfig.Concurrency.LatencyThreshold,
+			QueueSize:        appConfig.Concurrency.QueueSize,
+			MaxWait:          appConfig.Concurrency.MaxWait,
+			SheddableShare:   appConfig.Concurrency.SheddableShare,
+		})
+		appMetrics.RegisterConcurrencyLimiter("*", limiter)
+		for i := range endpoints {
+			endpoints[i].Middlewares = append(endpoints[i].Middlewares, api.LimitConcurrency(limiter, priorityOf))
+		}
+	}
+
+	// the endpoints require the read scope for the safe methods and the write scope for
+	// the others, the endpoints without scopes and the probes are not authenticated.
+	scopes := map[string][2]string{
+		"/records":                 {apikey.ScopeRecordsRead, apikey.ScopeRecordsRead},
+		"/in-memory":               {apikey.ScopeKVRead, apikey.ScopeKVWrite},
+		"/in-memory/":              {apikey.ScopeKVRead, apikey.ScopeKVWrite},
+		"/in-memory/hashes":        {apikey.ScopeKVRead, apikey.ScopeKVWrite},
+		"/in-memory/lists":         {apikey.ScopeKVRead, apikey.ScopeKVWrite},
+		"/in-memory/sets":          {apikey.ScopeKVRead, apikey.ScopeKVWrite},
+		"/in-memory/leaderboards":  {apikey.ScopeKVRead, apikey.ScopeKVWrite},
+		"/namespaces/":             {apikey.ScopeKVRead, apikey.ScopeKVWrite},
+		"/queues/":                 {apikey.ScopeQueuesRead, apikey.ScopeQueuesWrite},
+		"/admin/in-memory/schemas": {apikey.ScopeAdmin, apikey.ScopeAdmin},
+		"/admin/records/cache":     {apikey.ScopeAdmin, apikey.ScopeAdmin},
+	}
+	// the buckets of the rate limits are shared by the replicas through Redis.
+	var limiter api.RateLimiter
+	if appConfig.RateLimit.Rate > 0 || appConfig.RateLimit.File != "" || appConfig.RateLimit.AddressRate > 0 {
+		rateLimitCl := rediscl.NewClient(appConfig.RedisConnectionString)
+		rateLimitCl.AddHook(appMetrics.RedisErrors("ratelimit.RedisLimiter"))
+		appMetrics.RegisterRedisPool("ratelimit", rateLimitCl)
+		components.Register(lifecycle.Hook{Name: "rate limit Redis client", Stop: func(ctx context.Context) error {
+			return rateLimitCl.Close()
+		}})
+
+		limiter = &ratelimit.Fallback{
+			Primary:   ratelimit.RedisLimiter{Db: rateLimitCl},
+			Secondary: ratelimit.NewLocalLimiter(),
+			Cooldown:  appConfig.RateLimit.FallbackCooldown,
+		}
 	}
-	go func() {
-		err := api.Start(appConfig.Api.Address, endpoints...)
+
+	authenticators := newAuthenticators(appConfig.Auth, conn)
+	// the credentials sent in the headers take precedence over the certificate of the connection.
+	if appConfig.Api.TlsClientCaFile != "" {
+		authenticators = append(authenticators, certs.Authenticator{})
+	}
+	if len(authenticators) == 0 {
+		log.Println("API_KEY_STORE, JWT_JWKS_FILE, JWT_SECRET and TLS_CLIENT_CA_FILE are not set, the requests are not authenticated.")
+	}
+	// the requests are limited by the addresses of the clients before they are authenticated so that
+	// the credentials can not be guessed at the rate of the requests and the authenticators, e.g. the API key
+	// store, are not flooded by them. The limit is shared by the authenticated endpoints.
+	addressLimit := api.Limit{Rate: appConfig.RateLimit.AddressRate, Burst: appConfig.RateLimit.AddressBurst}
+	for i := range endpoints {
+		scope, ok := scopes[endpoints[i].Path]
+		if !ok || len(authenticators) == 0 {
+			continue
+		}
+
+		if addressLimit.Rate > 0 {
+			endpoints[i].Middlewares = append(endpoints[i].Middlewares, api.RateLimit(limiter, "authenticate", addressLimit))
+		}
+		endpoints[i].Middlewares = append(endpoints[i].Middlewares,
+			api.Authenticate(authenticators...), api.RequireScope(scope[0], scope[1]), restrictNamespace)
+	}
+
+	// the requests are limited after they are authenticated so that the clients are limited by their
+	// credentials rather than their addresses.
+	if appConfig.RateLimit.Rate > 0 || appConfig.RateLimit.File != "" {
+		limits := ratelimit.Limits{Default: api.Limit{Rate: appConfig.RateLimit.Rate, Burst: appConfig.RateLimit.Burst}}
+		if appConfig.RateLimit.File != "" {
+			var err error
+			limits, err = ratelimit.LoadLimits(appConfig.RateLimit.File, limits.Default)
+			if err != nil {
+				log.Fatalf("error on loading the rate limit file: %v", err)
+			}
+		}
+
+		for i := range endpoints {
+			endpoints[i].Middlewares = append(endpoints[i].Middlewares,
+				api.RateLimit(limiter, endpoints[i].Path, limits.Of(endpoints[i].Path)))
+		}
+	}
+
+	// the probes are not measured, they would outnumber the requests of the clients.
+	readiness := health.NewChecker(appConfig.Api.ReadinessTimeout, appConfig.Api.ReadinessCacheDuration,
+		health.Check{Name: "mongodb", Ping: conn.PingPrimary},
+		health.Check{Name: "redis", Ping: func(ctx context.Context) error {
+			return redisCl.Ping(ctx).Err()
+		}},
+		health.Check{Name: "in-memory redis", Ping: func(ctx context.Context) error {
+			return inMemoryCl.Ping(ctx).Err()
+		}},
+	)
+	endpoints = append(endpoints,
+		api.Endpoint{Path: "/healthz", Handler: http.HandlerFunc(health.Liveness)},
+		api.Endpoint{Path: "/readyz", Handler: readiness.Readiness()},
+	)
+
+	metricsEndpoint := api.Endpoint{Path: "/metrics", Handler: appMetrics.Handler()}
+	if appConfig.Api.MetricsAddress == "" {
+		endpoints = append(endpoints, metricsEndpoint)
+		log.Println("METRICS_ADDRESS is not set, the keyspace snapshot endpoints are not served.")
+	} else {
+		// the metrics are not exposed on the public listener when they have a listener of their own.
+		// The keyspace snapshots read and write every key regardless of the namespaces and the ACLs,
+		// so they are served only on the internal listener.
+		metricsServer := api.NewServer(appConfig.Api.MetricsAddress, metricsEndpoint,
+			api.Endpoint{Path: "/admin/in-memory/export", Handler: snapshotController,
+				Middlewares: []api.Middleware{appMetrics.Instrument("/admin/in-memory/export"), api.JSON}},
+			api.Endpoint{Path: "/admin/in-memory/import", Handler: snapshotController,
+				Middlewares: []api.Middleware{appMetrics.Instrument("/admin/in-memory/import"), api.JSON}},
+		)
+		components.Register(lifecycle.Hook{Name: "metrics server", Start: metricsServer.Start, Stop: metricsServer.Stop})
+	}
+
+	server := api.NewServer(appConfig.Api.Address, endpoints...)
+	if appConfig.Api.TlsCertFile != "" {
+		tlsFiles, err := certs.Load(appConfig.Api.TlsCertFile, appConfig.Api.TlsKeyFile,
+			appConfig.Api.TlsClientCaFile, appConfig.Api.TlsClientCertRequired)
 		if err != nil {
-			log.Fatalf("error on serving HTTP: %v", err)
+			log.Fatalf("error on loading the TLS certificate files: %v", err)
 		}
-	}()
+		server.UseTLS(tlsFiles.TLSConfig())
+
+		// the files are reloaded in the background so that the handshakes do not access them.
+		watchCtx, cancelWatch := context.WithCancel(context.Background())
+		components.Register(lifecycle.Hook{
+			Name: "TLS certificate files watcher",
+			Start: func(ctx context.Context) error {
+				go tlsFiles.Watch(watchCtx, appConfig.Api.TlsReloadInterval)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				cancelWatch()
+				return nil
+			},
+		})
+	}
+	// the access log is written after the panics are recovered so that they are logged as 500.
+	// The responses are JSON unless the handlers set another content type.
+	server.Use(api.RequestID, api.AccessLog(log.Writer()), api.Recover, api.JSON)
+	components.Register(lifecycle.Hook{Name: "HTTP server", Start: server.Start, Stop: server.Stop})
+	// the readiness is stopped first, the server keeps serving for the drain delay
+	// while the orchestrator stops routing the traffic to the application.
+	components.Register(lifecycle.Hook{
+		Name: "readiness",
+		Stop: func(ctx context.Context) error {
+			readiness.ShutDown()
+			select {
+			case <-time.After(appConfig.Api.DrainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	if err := components.Start(context.Background()); err != nil {
+		log.Fatalf("error on starting the application: %v", err)
+	}
 
 	log.Println("Getir Case Challenge API is now running. Press CTRL + C to interrupt.")
 
-	signalHandler := make(chan os.Signal)
-	signal.Notify(signalHandler, os.Interrupt, syscall.SIGUSR1)
-	receivedSignal := <-signalHandler
+	signalHandler := make(chan os.Signal, 1)
+	signal.Notify(signalHandler, os.Interrupt, syscall.SIGTERM, syscall.SIGUSR1)
+	select {
+	case receivedSignal := <-signalHandler:
+		log.Printf("API received %v signal. Gracefully shutting down the application.", receivedSignal)
+	case err := <-server.Errors():
+		log.Printf("Error on serving HTTP: %v. Shutting down the application.", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.Api.ShutdownTimeout)
+	err := components.Stop(ctx)
+	cancel()
+	if err != nil {
+		log.Fatalf("error on shutting down the application: %v", err)
+	}
+
+	log.Println("Getir Case Challenge API is shut down.")
+}
+
+// newAuthenticators returns the authenticators of the API keys in the configured
+// store and of the bearer tokens verified by the configured keys.
+func newAuthenticators(cnf config.Auth, conn *mongodb.Connection) []api.Authenticator {
+	var authenticators []api.Authenticator
+	switch cnf.ApiKeyStore {
+	case config.ApiKeyStoreMongo:
+		authenticators = append(authenticators, apikey.Service{Dao: apikey.MongoDao{Db: conn}})
+	case config.ApiKeyStoreFile:
+		authenticators = append(authenticators, apikey.Service{Dao: apikey.NewFileDao(cnf.ApiKeyFile)})
+	}
 
-	log.Printf("API received %v signal. Gracefully shutting down the application.", receivedSignal)
+	var keys jwt.KeySets
+	if cnf.JwksFile != "" {
+		keys = append(keys, jwt.NewJwksFile(cnf.JwksFile))
+	}
+	if cnf.JwtSecret != "" {
+		keys = append(keys, jwt.StaticKeys{{Algorithm: jwt.HS256, Secret: []byte(cnf.JwtSecret)}})
+	}
+	if len(keys) > 0 {
+		authenticators = append(authenticators, jwt.Authenticator{
+			Keys:           keys,
+			Issuer:         cnf.JwtIssuer,
+			Audience:       cnf.JwtAudience,
+			ClockSkew:      cnf.JwtClockSkew,
+			ScopeClaim:     cnf.JwtScopeClaim,
+			NamespaceClaim: cnf.JwtNamespaceClaim,
+		})
+	}
+
+	return authenticators
+}
+
+// restrictNamespace restricts the in-memory requests of the authenticated
+// clients to the namespaces of their credentials if they have one.
+func restrictNamespace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if principal, ok := api.PrincipalFromContext(req.Context()); ok && principal.Namespace != "" {
+			req = req.WithContext(inmem.ContextWithNamespace(req.Context(), principal.Namespace))
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// priorityOf returns the priority of the request while the server is overloaded. The record
+// queries are shed first, the in-memory reads are admitted before the other requests.
+func priorityOf(req *http.Request) api.Priority {
+	switch {
+	case req.URL.Path == "/records":
+		return api.PrioritySheddable
+	case strings.HasPrefix(req.URL.Path, "/in-memory") && (req.Method == http.MethodGet || req.Method == http.MethodHead):
+		return api.PriorityCritical
+	default:
+		return api.PriorityNormal
+	}
 }
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConcurrencyLimiter is a limiter whose limit and requests in flight are exposed.
+type ConcurrencyLimiter interface {
+	Limit() int
+	InFlight() int
+}
+
+// RegisterConcurrencyLimiter registers a collector reading the limit and the
+// requests in flight of the limiter of the endpoint when the metrics are scraped.
+func (m *Metrics) RegisterConcurrencyLimiter(endpoint string, limiter ConcurrencyLimiter) {
+	m.registry.MustRegister(concurrencyCollector{endpoint: endpoint, limiter: limiter})
+}
+
+var (
+	concurrencyLimit = prometheus.NewDesc("concurrency_limit",
+		"Number of the requests the endpoint is allowed to handle concurrently.", []string{"endpoint"}, nil)
+	concurrencyInFlight = prometheus.NewDesc("concurrency_in_flight",
+		"Number of the requests the endpoint is handling.", []string{"endpoint"}, nil)
+)
+
+// concurrencyCollector exposes the state of the concurrency limiter of an endpoint.
+type concurrencyCollector struct {
+	endpoint string
+	limiter  ConcurrencyLimiter
+}
+
+func (c concurrencyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- concurrencyLimit
+	ch <- concurrencyInFlight
+}
+
+func (c concurrencyCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(concurrencyLimit, prometheus.GaugeValue, float64(c.limiter.Limit()), c.endpoint)
+	ch <- prometheus.MustNewConstMetric(concurrencyInFlight, prometheus.GaugeValue, float64(c.limiter.InFlight()), c.endpoint)
+}
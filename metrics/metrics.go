@@ -0,0 +1,87 @@
+// Package metrics collects the metrics of the application in Prometheus format.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the collectors of the application in a registry of its own.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests  *prometheus.CounterVec
+	latencies *prometheus.HistogramVec
+	daoErrors *prometheus.CounterVec
+	coalesced *prometheus.CounterVec
+}
+
+// New creates the collectors of the HTTP endpoints and the data access objects
+// with the collectors of the Go runtime and the process.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of the HTTP requests by endpoint, method and status code.",
+		}, []string{"endpoint", "method", "code"}),
+		latencies: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of the HTTP requests by endpoint and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint", "method"}),
+		daoErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dao_errors_total",
+			Help: "Number of the errors occurred while accessing the databases by data access object and operation.",
+		}, []string{"dao", "operation"}),
+		coalesced: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "coalesced_requests_total",
+			Help: "Number of the requests which shared the result of an identical query in flight by query.",
+		}, []string{"query"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.latencies,
+		m.daoErrors,
+		m.coalesced,
+	)
+	return m
+}
+
+// Handler serves the metrics in Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Instrument returns a middleware counting the requests of the endpoint
+// by their status codes and observing their latencies. The endpoint is
+// the path pattern the handler is served at, not the path of the requests,
+// so that the number of the series stays bounded.
+func (m *Metrics) Instrument(endpoint string) func(http.Handler) http.Handler {
+	labels := prometheus.Labels{"endpoint": endpoint}
+	requests := m.requests.MustCurryWith(labels)
+	latencies := m.latencies.MustCurryWith(labels)
+
+	return func(next http.Handler) http.Handler {
+		return promhttp.InstrumentHandlerCounter(requests, promhttp.InstrumentHandlerDuration(latencies, next))
+	}
+}
+
+// DaoError returns a function counting the errors of the data access object by operation.
+func (m *Metrics) DaoError(dao string) func(operation string, err error) {
+	return func(operation string, err error) {
+		m.daoErrors.WithLabelValues(dao, operation).Inc()
+	}
+}
+
+// Coalesced returns a function counting the requests of the query which are coalesced.
+func (m *Metrics) Coalesced(query string) func() {
+	counter := m.coalesced.WithLabelValues(query)
+	return counter.Inc
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// scrape returns the metrics served by the handler of m.
+func scrape(t *testing.T, m *Metrics) string {
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Error on testing: metrics handler returned %v", recorder.Code)
+	}
+
+	body, err := io.ReadAll(recorder.Body)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	return string(body)
+}
+
+func assertContains(t *testing.T, body string, expected ...string) {
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("returned incorrect metrics. got: %v, expected to contain: %v", body, line)
+		}
+	}
+}
+
+func TestMetrics_Instrument(t *testing.T) {
+	m := New()
+	handler := m.Instrument("/records")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+
+	for _, method := range []string{http.MethodPost, http.MethodPost, http.MethodGet} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/records", nil))
+	}
+
+	assertContains(t, scrape(t, m),
+		`http_requests_total{code="200",endpoint="/records",method="post"} 2`,
+		`http_requests_total{code="405",endpoint="/records",method="get"} 1`,
+		`http_request_duration_seconds_count{endpoint="/records",method="post"} 2`,
+		`go_goroutines`,
+	)
+}
+
+func TestMetrics_DaoError(t *testing.T) {
+	m := New()
+	countError := m.DaoError("record.MongoDao")
+	countError("find", errors.New("connection refused"))
+	countError("find", errors.New("connection refused"))
+
+	assertContains(t, scrape(t, m), `dao_errors_total{dao="record.MongoDao",operation="find"} 2`)
+}
+
+func TestMetrics_Coalesced(t *testing.T) {
+	m := New()
+	countCoalesced := m.Coalesced("records")
+	countCoalesced()
+	countCoalesced()
+	countCoalesced()
+
+	assertContains(t, scrape(t, m), `coalesced_requests_total{query="records"} 3`)
+}
+
+func TestMetrics_RedisErrors(t *testing.T) {
+	m := New()
+	hook := m.RedisErrors("inmem.RedisDao")
+	ctx := context.Background()
+
+	failed := redis.NewStringCmd(ctx, "get", "key")
+	failed.SetErr(errors.New("i/o timeout"))
+	missing := redis.NewStringCmd(ctx, "get", "missing")
+	missing.SetErr(redis.Nil)
+	noScript := redis.NewCmd(ctx, "evalsha", "sha", 0)
+	noScript.SetErr(errors.New("NOSCRIPT No matching script. Please use EVAL."))
+	wrongType := redis.NewStatusCmd(ctx, "set", "key", "value")
+	wrongType.SetErr(errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"))
+
+	hook.AfterProcess(ctx, failed)
+	hook.AfterProcess(ctx, missing)
+	hook.AfterProcess(ctx, noScript)
+	hook.AfterProcessPipeline(ctx, []redis.Cmder{failed, wrongType, redis.NewStatusCmd(ctx, "ping")})
+
+	body := scrape(t, m)
+	assertContains(t, body,
+		`dao_errors_total{dao="inmem.RedisDao",operation="get"} 2`,
+		`dao_errors_total{dao="inmem.RedisDao",operation="set"} 1`,
+	)
+	if strings.Contains(body, `operation="evalsha"`) || strings.Contains(body, `operation="ping"`) {
+		t.Errorf("returned incorrect metrics. got: %v, expected no errors of evalsha and ping", body)
+	}
+}
+
+func TestMetrics_RegisterRedisPool(t *testing.T) {
+	m := New()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	m.RegisterRedisPool("inmem", client)
+
+	assertContains(t, scrape(t, m),
+		`redis_pool_hits_total{client="inmem"} 0`,
+		`redis_pool_connections{client="inmem",state="idle"} 0`,
+		`redis_pool_connections{client="inmem",state="total"} 0`,
+	)
+}
+
+func TestMetrics_MongoPool(t *testing.T) {
+	m := New()
+	monitor := m.MongoPool()
+	for _, eventType := range []string{
+		event.ConnectionCreated, event.ConnectionCreated, event.ConnectionCreated, event.ConnectionClosed,
+		event.GetSucceeded, event.GetSucceeded, event.ConnectionReturned, event.GetFailed,
+	} {
+		monitor.Event(&event.PoolEvent{Type: eventType})
+	}
+
+	assertContains(t, scrape(t, m),
+		`mongodb_pool_connections{state="open"} 2`,
+		`mongodb_pool_connections{state="in_use"} 1`,
+		`mongodb_pool_checkout_failures_total 1`,
+	)
+}
+
+type concurrencyLimiterMock struct {
+	limit    int
+	inFlight int
+}
+
+func (m concurrencyLimiterMock) Limit() int {
+	return m.limit
+}
+
+func (m concurrencyLimiterMock) InFlight() int {
+	return m.inFlight
+}
+
+func TestMetrics_RegisterConcurrencyLimiter(t *testing.T) {
+	m := New()
+	m.RegisterConcurrencyLimiter("/records", concurrencyLimiterMock{limit: 12, inFlight: 5})
+
+	assertContains(t, scrape(t, m),
+		`concurrency_limit{endpoint="/records"} 12`,
+		`concurrency_in_flight{endpoint="/records"} 5`,
+	)
+}
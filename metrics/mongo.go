@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoPool returns a monitor of a MongoDB connection pool
+// tracking the open and the checked out connections.
+func (m *Metrics) MongoPool() *event.PoolMonitor {
+	connections := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mongodb_pool_connections",
+		Help: "Number of the connections in the pool by state.",
+	}, []string{"state"})
+	checkoutFailures := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "mongodb_pool_checkout_failures_total",
+		Help: "Number of the times a connection could not be checked out from the pool.",
+	})
+	m.registry.MustRegister(connections, checkoutFailures)
+
+	open := connections.WithLabelValues("open")
+	inUse := connections.WithLabelValues("in_use")
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				open.Inc()
+			case event.ConnectionClosed:
+				open.Dec()
+			case event.GetSucceeded:
+				inUse.Inc()
+			case event.ConnectionReturned:
+				inUse.Dec()
+			case event.GetFailed:
+				checkoutFailures.Inc()
+			}
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RedisErrors returns a hook of a Redis client counting the failed commands
+// as the errors of the data access object using the client.
+func (m *Metrics) RedisErrors(dao string) redis.Hook {
+	return redisErrorHook{countError: m.DaoError(dao)}
+}
+
+// RegisterRedisPool registers a collector reading the statistics
+// of the connection pool of the client when the metrics are scraped.
+func (m *Metrics) RegisterRedisPool(name string, client *redis.Client) {
+	m.registry.MustRegister(redisPoolCollector{name: name, client: client})
+}
+
+// redisErrorHook counts the errors of the commands. The replies which
+// are not failures are not counted: nil replies of the missing keys and
+// the missing scripts which are loaded and retried by the client.
+type redisErrorHook struct {
+	countError func(operation string, err error)
+}
+
+func (h redisErrorHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h redisErrorHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.observe(cmd)
+	return nil
+}
+
+func (h redisErrorHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (h redisErrorHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	for _, cmd := range cmds {
+		h.observe(cmd)
+	}
+	return nil
+}
+
+func (h redisErrorHook) observe(cmd redis.Cmder) {
+	err := cmd.Err()
+	if err == nil || err == redis.Nil || strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		return
+	}
+
+	h.countError(cmd.Name(), err)
+}
+
+var (
+	redisPoolHits = prometheus.NewDesc("redis_pool_hits_total",
+		"Number of the times a free connection is found in the pool.", []string{"client"}, nil)
+	redisPoolMisses = prometheus.NewDesc("redis_pool_misses_total",
+		"Number of the times a free connection is not found in the pool.", []string{"client"}, nil)
+	redisPoolTimeouts = prometheus.NewDesc("redis_pool_timeouts_total",
+		"Number of the times a wait for a connection timed out.", []string{"client"}, nil)
+	redisPoolStaleConnections = prometheus.NewDesc("redis_pool_stale_connections_total",
+		"Number of the stale connections removed from the pool.", []string{"client"}, nil)
+	redisPoolConnections = prometheus.NewDesc("redis_pool_connections",
+		"Number of the connections in the pool by state.", []string{"client", "state"}, nil)
+)
+
+// redisPoolCollector exposes the statistics of the connection pool of a Redis client.
+type redisPoolCollector struct {
+	name   string
+	client *redis.Client
+}
+
+func (c redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisPoolHits
+	ch <- redisPoolMisses
+	ch <- redisPoolTimeouts
+	ch <- redisPoolStaleConnections
+	ch <- redisPoolConnections
+}
+
+func (c redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisPoolHits, prometheus.CounterValue, float64(stats.Hits), c.name)
+	ch <- prometheus.MustNewConstMetric(redisPoolMisses, prometheus.CounterValue, float64(stats.Misses), c.name)
+	ch <- prometheus.MustNewConstMetric(redisPoolTimeouts, prometheus.CounterValue, float64(stats.Timeouts), c.name)
+	ch <- prometheus.MustNewConstMetric(redisPoolStaleConnections, prometheus.CounterValue, float64(stats.StaleConns), c.name)
+	ch <- prometheus.MustNewConstMetric(redisPoolConnections, prometheus.GaugeValue, float64(stats.TotalConns), c.name, "total")
+	ch <- prometheus.MustNewConstMetric(redisPoolConnections, prometheus.GaugeValue, float64(stats.IdleConns), c.name, "idle")
+}
@@ -3,15 +3,20 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-	"log"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 	"time"
 )
 
 // Connection represents a mongo db client connection.
+// PoolMonitor receives the events of the connection pool if it is set before connecting.
 type Connection struct{
 	*mongo.Client
+	PoolMonitor *event.PoolMonitor
 
 	connectionString string
 	databaseName string
@@ -29,25 +34,56 @@ func NewConnection(connectionString, databaseName string) *Connection {
 }
 
 // Connect creates a mongodb client and connects to it by using the connection string.
-func (c *Connection) Connect() {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.timeoutDuration) * time.Second)
+// The primary is pinged, since the client connects lazily otherwise.
+// It gives up when the context is done or the connection timeout is exceeded.
+func (c *Connection) Connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.timeoutDuration) * time.Second)
 	defer cancel()
 
+	opts := options.Client().ApplyURI(c.connectionString)
+	if c.PoolMonitor != nil {
+		opts.SetPoolMonitor(c.PoolMonitor)
+	}
+
 	var err error
-	c.Client, err = mongo.Connect(ctx, options.Client().ApplyURI(c.connectionString))
+	c.Client, err = mongo.Connect(ctx, opts)
 	if err != nil {
-		log.Fatalf("error on connecting to the database: %v", err)
+		return fmt.Errorf("error on connecting to the database: %w", err)
+	}
+
+	if err := c.Client.Ping(ctx, readpref.Primary()); err != nil {
+		c.Client.Disconnect(ctx)
+		return fmt.Errorf("error on pinging the database: %w", err)
+	}
+
+	return nil
+}
+
+// PingPrimary checks whether the primary of the connected mongodb host is reachable.
+func (c *Connection) PingPrimary(ctx context.Context) error {
+	if c.Client == nil {
+		return errors.New("database is not connected")
 	}
+
+	return c.Client.Ping(ctx, readpref.Primary())
 }
 
-// Disconnect disconnects from the connected mongodb host.
-func (c *Connection) Disconnect() {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.timeoutDuration) * time.Second)
+// Disconnect disconnects from the connected mongodb host. It waits for the
+// in-use connections until the context is done or the connection timeout is exceeded,
+// then the connections are closed forcibly.
+func (c *Connection) Disconnect(ctx context.Context) error {
+	if c.Client == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(c.timeoutDuration) * time.Second)
 	defer cancel()
 
 	if err := c.Client.Disconnect(ctx); err != nil {
-		log.Fatalf("error on disconnecting from the database: %v", err)
+		return fmt.Errorf("error on disconnecting from the database: %w", err)
 	}
+
+	return nil
 }
 
 // DatabaseName returns the default database name.
@@ -0,0 +1,53 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// Handler serves the OpenAPI document as JSON.
+func Handler() http.Handler {
+	document, err := json.Marshal(Spec())
+	if err != nil {
+		log.Printf("Error on marshalling the OpenAPI document: %v", err)
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte(`{"error":"internal server error occurred."}`))
+		})
+	}
+
+	return staticHandler("application/json", document)
+}
+
+// DocsHandler serves the documentation page rendering the OpenAPI document
+// served at "/openapi.json". The page has no external dependencies.
+func DocsHandler() http.Handler {
+	return staticHandler("text/html; charset=utf-8", docsPage)
+}
+
+// staticHandler serves the content for GET and HEAD requests.
+func staticHandler(contentType string, content []byte) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			rw.Write([]byte(`{"error":"the method is not allowed for this endpoint."}`))
+			return
+		}
+
+		rw.Header().Set("Content-Type", contentType)
+		if req.Method == http.MethodHead {
+			return
+		}
+
+		if _, err := rw.Write(content); err != nil {
+			log.Printf("Error on writing response: %v", err)
+		}
+	})
+}
@@ -0,0 +1,415 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"github.com/skarakasoglu/g-case-challenge/apikey"
+	"github.com/skarakasoglu/g-case-challenge/concurrency"
+	"github.com/skarakasoglu/g-case-challenge/inmem"
+	"github.com/skarakasoglu/g-case-challenge/jsonschema"
+	"github.com/skarakasoglu/g-case-challenge/ratelimit"
+	"github.com/skarakasoglu/g-case-challenge/record"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeRecordDao struct{}
+
+// Find fails for a negative minimum count so that the errors can be requested.
+func (d fakeRecordDao) Find(options record.FilterOptions) ([]record.Dto, error) {
+	if options.MinCount < 0 {
+		return nil, errors.New("connection refused")
+	}
+
+	return []record.Dto{{Key: "TAKwGc6Jr4i8Z487", CreatedAt: time.Unix(1485566534, 0), TotalCount: 2800}}, nil
+}
+
+type fakeInMemoryDao map[string]string
+
+// Get fails for the key "wrong-type" so that the errors can be requested.
+func (d fakeInMemoryDao) Get(key string) (inmem.Dto, error) {
+	if key == "wrong-type" {
+		return inmem.Dto{}, inmem.ErrWrongType
+	}
+
+	value, ok := d[key]
+	return inmem.Dto{Key: key, Value: value, Exists: ok}, nil
+}
+
+func (d fakeInMemoryDao) Set(dto inmem.Dto) (inmem.Dto, error) {
+	if dto.Key == "wrong-type" {
+		return inmem.Dto{}, inmem.ErrWrongType
+	}
+
+	d[dto.Key] = dto.Value
+	dto.Exists = true
+	return dto, nil
+}
+
+func (d fakeInMemoryDao) Delete(key string) (bool, error) {
+	_, ok := d[key]
+	delete(d, key)
+	return ok, nil
+}
+
+// acl denies the access to the key "secret" so that the denials can be requested.
+var acl = &inmem.Acl{Rules: []inmem.AclRule{
+	{Effect: inmem.EffectAllow, Subjects: []string{"*"}, Actions: []string{inmem.ActionRead, inmem.ActionWrite}, Keys: []string{"*"}},
+	{Effect: inmem.EffectDeny, Subjects: []string{"*"}, Actions: []string{inmem.ActionRead, inmem.ActionWrite}, Keys: []string{"secret"}},
+}}
+
+// handlers are the handlers of the documented paths as they are served by the application.
+var handlers = map[string]http.Handler{
+	"/records": record.Controller{Repository: record.Service{Dao: fakeRecordDao{}}},
+	"/in-memory": inmem.NamespaceHandler{Handler: func(dao inmem.RedisDao) http.Handler {
+		return inmem.Controller{Repository: inmem.Service{Dao: fakeInMemoryDao{"active-tabs": "getir"}, MaxValueSize: 16}, Acl: acl}
+	}},
+}
+
+// scenario is a request to a documented operation, the response is
+// expected to have the status code and to match the documented schema.
+type scenario struct {
+	method      string
+	path        string
+	query       string
+	header      http.Header
+	contentType string
+	// body is the example of the operation if it is not given.
+	body       string
+	statusCode int
+}
+
+var scenarios = []scenario{
+	{method: http.MethodPost, path: "/records", statusCode: http.StatusOK},
+	{method: http.MethodPost, path: "/records", contentType: "application/json", body: `{"startDate": "2016-01-26"}`, statusCode: http.StatusBadRequest},
+	{method: http.MethodPost, path: "/records", contentType: "application/json", body: `{"startDate": "2016-01-26", "endDate": "2018-02-02", "minCount": -1, "maxCount": 3000}`, statusCode: http.StatusInternalServerError},
+	{method: http.MethodGet, path: "/in-memory", query: "key=active-tabs", statusCode: http.StatusOK},
+	{method: http.MethodGet, path: "/in-memory", query: "key=missing", statusCode: http.StatusNotFound},
+	{method: http.MethodGet, path: "/in-memory", query: "key=wrong-type", statusCode: http.StatusConflict},
+	{method: http.MethodGet, path: "/in-memory", query: "key=active-tabs", header: http.Header{inmem.NamespaceHeader: {"not valid"}}, statusCode: http.StatusBadRequest},
+	{method: http.MethodGet, path: "/in-memory", query: "key=secret", statusCode: http.StatusForbidden},
+	{method: http.MethodPost, path: "/in-memory", statusCode: http.StatusOK},
+	{method: http.MethodPost, path: "/in-memory", contentType: "application/json", body: `{"key": "secret", "value": "getir"}`, statusCode: http.StatusForbidden},
+	{method: http.MethodPost, path: "/in-memory", contentType: "application/json", body: `{"key": "active-tabs"}`, statusCode: http.StatusBadRequest},
+	{method: http.MethodPost, path: "/in-memory", contentType: "application/json", body: `{"key": "wrong-type", "value": "getir"}`, statusCode: http.StatusConflict},
+	{method: http.MethodPost, path: "/in-memory", contentType: "application/json", body: `{"key": "k", "value": "longer than sixteen bytes"}`, statusCode: http.StatusRequestEntityTooLarge},
+	{method: http.MethodPost, path: "/in-memory", query: "key=avatar&contentType=image/png", contentType: "application/octet-stream", body: "\x89PNG", statusCode: http.StatusOK},
+}
+
+// TestSpec_Scenarios fails when a handler responds a status code or a payload which is not documented.
+func TestSpec_Scenarios(t *testing.T) {
+	spec := Spec()
+	for _, test := range scenarios {
+		name := test.method + " " + test.path + "?" + test.query
+		operation := spec.Paths[test.path].Operations()[test.method]
+		if operation == nil {
+			t.Fatalf("Error on testing: %v is not documented", name)
+		}
+
+		contentType, body := test.contentType, test.body
+		if operation.RequestBody != nil {
+			if body == "" {
+				contentType = "application/json"
+				example, err := json.Marshal(operation.RequestBody.Content[contentType].Example)
+				if err != nil {
+					t.Fatalf("Error on testing: %v", err)
+				}
+				body = string(example)
+			}
+
+			if _, ok := operation.RequestBody.Content[contentType]; !ok {
+				t.Errorf("returned undocumented request content type for %v. got: %v, documented: %v", name, contentType, operation.RequestBody.Content)
+			}
+		}
+
+		req := httptest.NewRequest(test.method, test.path+"?"+test.query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		for header, values := range test.header {
+			req.Header[header] = values
+		}
+
+		rr := httptest.NewRecorder()
+		api.Chain(handlers[test.path], api.JSON).ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", name, rr.Code, test.statusCode)
+		}
+
+		response, ok := operation.Responses[strconv.Itoa(rr.Code)]
+		if !ok {
+			t.Errorf("returned undocumented status code for %v. got: %v, documented: %v", name, rr.Code, operation.Responses)
+			continue
+		}
+
+		responseType := rr.Header().Get("Content-Type")
+		mediaType, ok := response.Content[responseType]
+		if !ok {
+			t.Errorf("returned undocumented content type for %v. got: %v, documented: %v", name, responseType, response.Content)
+			continue
+		}
+
+		if violations := validate(t, mediaType.Schema, rr.Body.Bytes()); len(violations) > 0 {
+			t.Errorf("returned undocumented payload for %v. got: %v, violations: %v", name, rr.Body.String(), violations)
+		}
+	}
+}
+
+// TestSpec_AuthenticationErrors fails when the responses of the authentication middlewares are not documented.
+func TestSpec_AuthenticationErrors(t *testing.T) {
+	keys := apikey.Service{Dao: apikey.NewFileDao(filepath.Join(t.TempDir(), "apikeys.json"))}
+	token, _, err := keys.Mint("reader", []string{apikey.ScopeKVRead}, "", 0)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tests := []struct {
+		method     string
+		path       string
+		token      string
+		statusCode int
+	}{
+		{http.MethodPost, "/records", "", http.StatusUnauthorized},
+		{http.MethodPost, "/records", token, http.StatusForbidden},
+		{http.MethodGet, "/in-memory", "gcc_invalid", http.StatusUnauthorized},
+		{http.MethodPost, "/in-memory", token, http.StatusForbidden},
+	}
+
+	spec := Spec()
+	for _, test := range tests {
+		handler := api.Chain(handlers[test.path], api.Authenticate(keys), api.RequireScope("kv:read", "kv:write"))
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader("{}"))
+		if test.token != "" {
+			req.Header.Set(apikey.Header, test.token)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v %v. got: %v, expected: %v", test.method, test.path, rr.Code, test.statusCode)
+		}
+
+		response, ok := spec.Paths[test.path].Operations()[test.method].Responses[strconv.Itoa(rr.Code)]
+		if !ok {
+			t.Errorf("returned undocumented status code for %v %v. got: %v", test.method, test.path, rr.Code)
+			continue
+		}
+
+		if violations := validate(t, response.Content["application/json"].Schema, rr.Body.Bytes()); len(violations) > 0 {
+			t.Errorf("returned undocumented payload for %v %v. got: %v, violations: %v", test.method, test.path, rr.Body.String(), violations)
+		}
+	}
+}
+
+// TestSpec_RateLimit fails when the responses of the rate limited requests are not documented.
+func TestSpec_RateLimit(t *testing.T) {
+	spec := Spec()
+	for path, operations := range map[string][]string{"/records": {http.MethodPost}, "/in-memory": {http.MethodGet, http.MethodPost}} {
+		for _, method := range operations {
+			handler := api.Chain(handlers[path], api.RateLimit(ratelimit.NewLocalLimiter(), path, api.Limit{Rate: 0.001, Burst: 1}))
+
+			var rr *httptest.ResponseRecorder
+			for i := 0; i < 2; i++ {
+				rr = httptest.NewRecorder()
+				handler.ServeHTTP(rr, httptest.NewRequest(method, path+"?key=active-tabs", strings.NewReader("{}")))
+			}
+
+			if rr.Code != http.StatusTooManyRequests {
+				t.Errorf("returned incorrect status code for %v %v. got: %v, expected: %v", method, path, rr.Code, http.StatusTooManyRequests)
+			}
+
+			response, ok := spec.Paths[path].Operations()[method].Responses[strconv.Itoa(rr.Code)]
+			if !ok {
+				t.Errorf("returned undocumented status code for %v %v. got: %v", method, path, rr.Code)
+				continue
+			}
+
+			documented := make(map[string]bool)
+			for header := range response.Headers {
+				documented[http.CanonicalHeaderKey(header)] = true
+			}
+
+			for header := range rr.Header() {
+				if !documented[header] && header != "Content-Type" {
+					t.Errorf("returned undocumented header for %v %v. got: %v", method, path, header)
+				}
+			}
+
+			if violations := validate(t, response.Content["application/json"].Schema, rr.Body.Bytes()); len(violations) > 0 {
+				t.Errorf("returned undocumented payload for %v %v. got: %v, violations: %v", method, path, rr.Body.String(), violations)
+			}
+		}
+	}
+}
+
+// TestSpec_LoadShedding fails when the responses of the shed requests are not documented.
+func TestSpec_LoadShedding(t *testing.T) {
+	spec := Spec()
+	for path, operations := range map[string][]string{"/records": {http.MethodPost}, "/in-memory": {http.MethodGet, http.MethodPost}} {
+		for _, method := range operations {
+			limiter := concurrency.NewLimiter(concurrency.Options{InitialLimit: 1, MaxLimit: 1, LatencyThreshold: time.Second})
+			release, err := limiter.Acquire(context.Background(), api.PriorityNormal)
+			if err != nil {
+				t.Fatalf("Error on testing: %v", err)
+			}
+
+			handler := api.Chain(handlers[path], api.LimitConcurrency(limiter, func(req *http.Request) api.Priority {
+				return api.PrioritySheddable
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(method, path+"?key=active-tabs", strings.NewReader("{}")))
+			release(false)
+
+			if rr.Code != http.StatusServiceUnavailable {
+				t.Errorf("returned incorrect status code for %v %v. got: %v, expected: %v", method, path, rr.Code, http.StatusServiceUnavailable)
+			}
+
+			response, ok := spec.Paths[path].Operations()[method].Responses[strconv.Itoa(rr.Code)]
+			if !ok {
+				t.Errorf("returned undocumented status code for %v %v. got: %v", method, path, rr.Code)
+				continue
+			}
+
+			if _, ok := response.Headers["Retry-After"]; !ok || rr.Header().Get("Retry-After") == "" {
+				t.Errorf("returned undocumented Retry-After header for %v %v. got: %q", method, path, rr.Header().Get("Retry-After"))
+			}
+
+			if violations := validate(t, response.Content["application/json"].Schema, rr.Body.Bytes()); len(violations) > 0 {
+				t.Errorf("returned undocumented payload for %v %v. got: %v, violations: %v", method, path, rr.Body.String(), violations)
+			}
+		}
+	}
+}
+
+// TestSpec_Methods fails when a handler serves a method which is not documented,
+// or an operation is not covered by the scenarios.
+func TestSpec_Methods(t *testing.T) {
+	methods := []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	for path, item := range Spec().Paths {
+		handler, ok := handlers[path]
+		if !ok {
+			t.Errorf("returned undocumented path. got: %v, expected: a handler of the path", path)
+			continue
+		}
+
+		operations := item.Operations()
+		for _, method := range methods {
+			if _, ok := operations[method]; ok {
+				if !covered(method, path) {
+					t.Errorf("returned untested operation. got: %v %v, expected: a scenario of the operation", method, path)
+				}
+				continue
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader("{}")))
+			if rr.Code != http.StatusMethodNotAllowed {
+				t.Errorf("returned incorrect status code for undocumented %v %v. got: %v, expected: %v", method, path, rr.Code, http.StatusMethodNotAllowed)
+			}
+		}
+	}
+}
+
+func TestSpec_RequestExamples(t *testing.T) {
+	for path, item := range Spec().Paths {
+		for method, operation := range item.Operations() {
+			if operation.RequestBody == nil {
+				continue
+			}
+
+			mediaType := operation.RequestBody.Content["application/json"]
+			example, err := json.Marshal(mediaType.Example)
+			if err != nil {
+				t.Fatalf("Error on testing: %v", err)
+			}
+
+			if violations := validate(t, mediaType.Schema, example); len(violations) > 0 {
+				t.Errorf("returned incorrect example for %v %v. got: %s, violations: %v", method, path, example, violations)
+			}
+		}
+	}
+}
+
+func TestRequestSchema(t *testing.T) {
+	schema := requestSchema(record.Request{})
+
+	expected := []string{"startDate", "endDate", "minCount", "maxCount"}
+	if !reflect.DeepEqual(schema.Required, expected) {
+		t.Errorf("returned incorrect required fields. got: %v, expected: %v", schema.Required, expected)
+	}
+
+	if date := schema.Properties["startDate"]; date.Format != "date" {
+		t.Errorf("returned incorrect format of startDate. got: %v, expected: %v", date.Format, "date")
+	}
+}
+
+func TestResponseSchema(t *testing.T) {
+	schema := responseSchema(record.Response{})
+
+	expected := []string{"code", "msg", "records"}
+	if !reflect.DeepEqual(schema.Required, expected) {
+		t.Errorf("returned incorrect required fields. got: %v, expected: %v", schema.Required, expected)
+	}
+
+	// a nil slice is written as null.
+	records := schema.Properties["records"]
+	if !reflect.DeepEqual(records.Type, Types{"array", "null"}) {
+		t.Errorf("returned incorrect type of records. got: %v, expected: %v", records.Type, Types{"array", "null"})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", rr.Code, http.StatusOK)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &document); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if document["openapi"] != "3.1.0" {
+		t.Errorf("returned incorrect version. got: %v, expected: %v", document["openapi"], "3.1.0")
+	}
+}
+
+func covered(method, path string) bool {
+	for _, test := range scenarios {
+		if test.method == method && test.path == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validate validates the JSON document against the schema by the JSON Schema validator of the application.
+func validate(t *testing.T, schema *Schema, document []byte) []jsonschema.Error {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	compiled, err := jsonschema.Parse(data)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	violations, err := compiled.ValidateJSON(document)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+	return violations
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/skarakasoglu/g-case-challenge/record"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// knownTypes are the schemas of the types which are not encoded by their fields.
+var knownTypes = map[reflect.Type]Schema{
+	reflect.TypeOf(time.Time{}):       {Type: Types{"string"}, Format: "date-time"},
+	reflect.TypeOf(record.Date{}):     {Type: Types{"string"}, Format: "date"},
+	reflect.TypeOf(json.RawMessage{}): {},
+}
+
+// requestSchema returns the schema of a request payload. The pointer fields
+// are required, since the payloads use them to detect the missing fields.
+func requestSchema(payload interface{}) *Schema {
+	return schemaOf(reflect.TypeOf(payload), true)
+}
+
+// responseSchema returns the schema of a response payload. The fields are
+// required unless they are omitted when empty, and the nil pointers, slices and
+// maps which are not omitted are written as null.
+func responseSchema(payload interface{}) *Schema {
+	return schemaOf(reflect.TypeOf(payload), false)
+}
+
+func schemaOf(t reflect.Type, request bool) *Schema {
+	if known, ok := knownTypes[t]; ok {
+		return &known
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaOf(t.Elem(), request)
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: Types{"integer"}, Minimum: &minimum}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: schemaOf(t.Elem(), request)}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: schemaOf(t.Elem(), request)}
+	case reflect.Struct:
+		return structSchema(t, request)
+	default:
+		// the other kinds are not encoded by encoding/json, any value is allowed.
+		return &Schema{}
+	}
+}
+
+// structSchema returns the schema of a struct by the JSON names of its exported fields.
+func structSchema(t reflect.Type, request bool) *Schema {
+	schema := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty := jsonName(field)
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		property := schemaOf(field.Type, request)
+		kind := field.Type.Kind()
+		nullable := kind == reflect.Ptr || kind == reflect.Slice || kind == reflect.Map
+		if request {
+			if kind == reflect.Ptr {
+				schema.Required = append(schema.Required, name)
+			}
+		} else if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+			if nullable && len(property.Type) > 0 {
+				nullableProperty := *property
+				nullableProperty.Type = append(Types{}, property.Type...)
+				nullableProperty.Type = append(nullableProperty.Type, "null")
+				property = &nullableProperty
+			}
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema
+}
+
+// jsonName returns the name of the field in JSON and whether it is omitted when empty.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return field.Name, false
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			return name, true
+		}
+	}
+
+	return name, false
+}
@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"github.com/skarakasoglu/g-case-challenge/apikey"
+	"github.com/skarakasoglu/g-case-challenge/inmem"
+	"github.com/skarakasoglu/g-case-challenge/record"
+)
+
+// Spec returns the OpenAPI document of the "/records" and "/in-memory" endpoints.
+// The schemas of the payloads are generated from their types, so that they follow
+// the changes of the types. The methods which are not documented are not allowed.
+// The clients are authenticated by API keys, bearer tokens or client certificates if the application is configured to.
+func Spec() Document {
+	return Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:       "Getir Case Challenge API",
+			Description: "Fetches the records from MongoDB and stores the key-value pairs in Redis.",
+			Version:     "1.0.0",
+		},
+		Components: &Components{SecuritySchemes: map[string]SecurityScheme{
+			"apiKey": {
+				Type:        "apiKey",
+				Description: "API key minted by the apikey command, required if API_KEY_STORE is set.",
+				Name:        apikey.Header,
+				In:          "header",
+			},
+			"bearer": {
+				Type:         "http",
+				Description:  "JWT signed by RS256, ES256 or HS256, required if JWT_JWKS_FILE or JWT_SECRET is set.",
+				Scheme:       "bearer",
+				BearerFormat: "JWT",
+			},
+			"mutualTLS": {
+				Type:        "mutualTLS",
+				Description: "Client certificate verified by the CAs in TLS_CLIENT_CA_FILE, the scopes are its organizational units.",
+			},
+		}},
+		Security: []map[string][]string{{}, {"apiKey": {}}, {"bearer": {}}, {"mutualTLS": {}}},
+		Paths: map[string]PathItem{
+			"/records": {
+				Post: &Operation{
+					OperationID: "fetchRecords",
+					Summary:     "Fetches the records created between the dates whose total counts are in the range.",
+					RequestBody: &RequestBody{
+						Required: true,
+						Content: map[string]MediaType{
+							"application/json": {
+								Schema: requestSchema(record.Request{}),
+								Example: map[string]interface{}{
+									"startDate": "2016-01-26",
+									"endDate":   "2018-02-02",
+									"minCount":  2700,
+									"maxCount":  3000,
+								},
+							},
+						},
+					},
+					Responses: map[string]Response{
+						"200": stale(cached(recordResponse("The records matching the filter, code is 0."))),
+						"400": recordResponse("The payload is not valid JSON or a field is missing, code is 2."),
+						"401": errorResponse("The credentials are missing or not valid."),
+						"403": errorResponse("The credentials are not granted the records:read scope."),
+						"429": rateLimited(errorResponse("The rate limit of the client is exceeded.")),
+						"500": cached(recordResponse("The records could not be fetched.")),
+						"503": shed(errorResponse("The credentials could not be verified, or the server is overloaded and the request is shed.")),
+					},
+				},
+			},
+			"/in-memory": {
+				Get: &Operation{
+					OperationID: "getValue",
+					Summary:     "Fetches the value of a key.",
+					Description: "Binary values and the values which are not valid UTF-8 are base64 encoded, encoding is \"base64\" for them.",
+					Parameters: []Parameter{
+						{Name: "key", In: "query", Required: true, Schema: &Schema{Type: Types{"string"}}, Example: "active-tabs"},
+						namespaceHeader,
+					},
+					Responses: map[string]Response{
+						"200": inMemoryResponse("The value of the key."),
+						"400": namespacedResponse("The namespace is not valid."),
+						"401": errorResponse("The credentials are missing or not valid."),
+						"403": namespacedResponse("The credentials are not granted the kv:read scope, the namespace is not accessible by them, or the ACL denies reading the key."),
+						"404": inMemoryResponse("The key does not exist."),
+						"409": inMemoryResponse("The key holds another data type."),
+						"429": rateLimited(errorResponse("The rate limit of the client is exceeded.")),
+						"500": inMemoryResponse("The value could not be fetched."),
+						"503": shed(errorResponse("The credentials could not be verified, or the server is overloaded and the request is shed.")),
+					},
+				},
+				Post: &Operation{
+					OperationID: "setValue",
+					Summary:     "Sets the value of a key.",
+					Description: "A JSON payload sets a text value, or a binary value if its encoding is \"base64\". " +
+						"An \"application/octet-stream\" body is stored as a binary value of the key given in the query.",
+					Parameters: []Parameter{
+						{Name: "key", In: "query", Description: "Key of an \"application/octet-stream\" upload.", Schema: &Schema{Type: Types{"string"}}},
+						{Name: "contentType", In: "query", Description: "Content type of an \"application/octet-stream\" upload.", Schema: &Schema{Type: Types{"string"}}},
+						namespaceHeader,
+					},
+					RequestBody: &RequestBody{
+						Required: true,
+						Content: map[string]MediaType{
+							"application/json": {
+								Schema:  requestSchema(inmem.Request{}),
+								Example: map[string]interface{}{"key": "active-tabs", "value": "getir"},
+							},
+							"application/octet-stream": {
+								Schema: &Schema{Type: Types{"string"}, ContentMediaType: "application/octet-stream"},
+							},
+						},
+					},
+					Responses: map[string]Response{
+						"200": inMemoryResponse("The key and the value set."),
+						"400": namespacedResponse("The payload or the namespace is not valid or a field is missing."),
+						"401": errorResponse("The credentials are missing or not valid."),
+						"403": namespacedResponse("The credentials are not granted the kv:write scope, the namespace is not accessible by them, or the ACL denies writing the key."),
+						"409": inMemoryResponse("The key holds another data type."),
+						"413": inMemoryResponse("The value is larger than the maximum value size."),
+						"422": inMemoryResponse("The value does not match the schema of its key, violations lists the reasons."),
+						"429": rateLimited(Response{
+							Description: "The key quota of the namespace or the rate limit of the client is exceeded.",
+							Content: map[string]MediaType{"application/json": {Schema: &Schema{AnyOf: []*Schema{
+								responseSchema(inmem.Response{}),
+								responseSchema(errorPayload{}),
+							}}}},
+						}),
+						"500": inMemoryResponse("The value could not be set."),
+						"503": shed(errorResponse("The credentials could not be verified, or the server is overloaded and the request is shed.")),
+						"507": inMemoryResponse("The byte quota of the namespace is exceeded."),
+					},
+				},
+			},
+		},
+	}
+}
+
+// namespaceHeader is the parameter selecting the namespace of the in-memory keys.
+var namespaceHeader = Parameter{
+	Name:        inmem.NamespaceHeader,
+	In:          "header",
+	Description: "Namespace of the key, the default namespace is used if it is not given.",
+	Schema:      &Schema{Type: Types{"string"}},
+}
+
+func recordResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: responseSchema(record.Response{})}},
+	}
+}
+
+func inMemoryResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: responseSchema(inmem.Response{})}},
+	}
+}
+
+// namespacedResponse is a response of an in-memory operation which may be rejected
+// before the operation, since the client or the namespace of the request is not valid.
+func namespacedResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content: map[string]MediaType{"application/json": {Schema: &Schema{AnyOf: []*Schema{
+			responseSchema(inmem.Response{}),
+			responseSchema(inmem.NamespaceResponse{}),
+			responseSchema(errorPayload{}),
+		}}}},
+	}
+}
+
+// errorPayload is the payload of the errors responded by the middlewares.
+type errorPayload struct {
+	Error string `json:"error"`
+}
+
+func errorResponse(description string) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: responseSchema(errorPayload{})}},
+	}
+}
+
+// rateLimited adds the headers of the rate limits to the response.
+func rateLimited(response Response) Response {
+	integer := &Schema{Type: Types{"integer"}}
+	response.Headers = map[string]Header{
+		"Retry-After":         {Description: "Seconds after which the request is allowed, sent if the rate limit is exceeded.", Schema: integer},
+		"RateLimit-Limit":     {Description: "Requests the client can send in a burst.", Schema: integer},
+		"RateLimit-Remaining": {Description: "Requests the client can send now.", Schema: integer},
+		"RateLimit-Reset":     {Description: "Seconds after which the client can send a burst again.", Schema: integer},
+		"RateLimit-Policy":    {Description: "The burst and the seconds it takes to refill it, e.g. 10;w=2.", Schema: &Schema{Type: Types{"string"}}},
+	}
+	return response
+}
+
+// cached adds the header of the responses served through the records cache to the response.
+func cached(response Response) Response {
+	response.Headers = map[string]Header{
+		"Cache-Status": {
+			Description: "How the response is served by the records cache if RECORDS_CACHE_TTL is set, " +
+				"e.g. \"records; hit; ttl=42\" or \"records; fwd=miss; stored\".",
+			Schema: &Schema{Type: Types{"string"}},
+		},
+	}
+	return response
+}
+
+// stale adds the headers of the stale records served while the database is not available to the response.
+func stale(response Response) Response {
+	if response.Headers == nil {
+		response.Headers = make(map[string]Header)
+	}
+	response.Headers["Age"] = Header{
+		Description: "Seconds since the records are found, sent if MongoDB is not available and the last records found for the filter are served.",
+		Schema:      &Schema{Type: Types{"integer"}},
+	}
+	response.Headers["Warning"] = Header{
+		Description: "110 - \"Response is Stale\", sent along with Age.",
+		Schema:      &Schema{Type: Types{"string"}},
+	}
+	return response
+}
+
+// shed adds the header of the responses of the requests shed by the concurrency limits to the response.
+func shed(response Response) Response {
+	response.Headers = map[string]Header{
+		"Retry-After": {Description: "Seconds after which the request can be retried, sent if the request is shed.", Schema: &Schema{Type: Types{"integer"}}},
+	}
+	return response
+}
@@ -0,0 +1,206 @@
+package queue
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"path"
+	"regexp"
+	"time"
+)
+
+// queueNamePattern restricts the queue names so that they can be embedded in Redis keys safely.
+var queueNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,128}$`)
+
+// Repository interface is used by a Controller to operate on the queues.
+type Repository interface {
+	Enqueue(queue string, payload string, delay time.Duration) (Response, error)
+	Dequeue(queue string, visibility time.Duration) (Response, error)
+	Ack(queue string, id string, receipt string) (Response, error)
+	Nack(queue string, id string, receipt string, delay time.Duration) (Response, error)
+	Stats(queue string) (Response, error)
+}
+
+// Controller is a handler for handling requests coming to "/queues/" endpoints.
+type Controller struct {
+	Repository Repository
+}
+
+// ServeHTTP handles incoming requests to "/queues/" endpoints.
+// The operation is specified by the last element of the path:
+//  - POST /queues/enqueue adds a job to a queue,
+//  - POST /queues/dequeue delivers a job from a queue,
+//  - POST /queues/ack acknowledges a delivered job,
+//  - POST /queues/nack schedules a delivered job to be retried,
+//  - GET /queues/stats?queue=name returns the depth of a queue.
+func (c Controller) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	action := path.Base(req.URL.Path)
+	if action == "stats" {
+		if req.Method != http.MethodGet {
+			c.methodNotAllowed(rw)
+			return
+		}
+
+		queue := req.URL.Query().Get("queue")
+		if !c.validateQueue(rw, &queue) {
+			return
+		}
+
+		resp, err := c.Repository.Stats(queue)
+		c.writeResult(rw, resp, err)
+		return
+	}
+
+	switch action {
+	case "enqueue", "dequeue", "ack", "nack":
+	default:
+		c.writeResponse(rw, http.StatusNotFound, Response{Error: "the endpoint does not exist."})
+		return
+	}
+
+	if req.Method != http.MethodPost {
+		c.methodNotAllowed(rw)
+		return
+	}
+
+	switch action {
+	case "enqueue":
+		var payload EnqueueRequest
+		if !c.parseRequestJSON(rw, req, &payload) || !c.validateQueue(rw, payload.Queue) {
+			return
+		}
+
+		if payload.Payload == nil {
+			c.badRequest(rw, "payload field is missing")
+			return
+		}
+
+		if payload.DelaySeconds < 0 {
+			c.badRequest(rw, "delaySeconds field must not be negative")
+			return
+		}
+
+		resp, err := c.Repository.Enqueue(*payload.Queue, *payload.Payload, time.Duration(payload.DelaySeconds)*time.Second)
+		c.writeResult(rw, resp, err)
+	case "dequeue":
+		var payload DequeueRequest
+		if !c.parseRequestJSON(rw, req, &payload) || !c.validateQueue(rw, payload.Queue) {
+			return
+		}
+
+		if payload.VisibilityTimeoutSeconds < 0 {
+			c.badRequest(rw, "visibilityTimeoutSeconds field must not be negative")
+			return
+		}
+
+		resp, err := c.Repository.Dequeue(*payload.Queue, time.Duration(payload.VisibilityTimeoutSeconds)*time.Second)
+		c.writeResult(rw, resp, err)
+	case "ack", "nack":
+		var payload AckRequest
+		if !c.parseRequestJSON(rw, req, &payload) || !c.validateAckRequest(rw, payload) {
+			return
+		}
+
+		var resp Response
+		var err error
+		if action == "ack" {
+			resp, err = c.Repository.Ack(*payload.Queue, *payload.Id, *payload.Receipt)
+		} else {
+			delay := time.Duration(payload.RetryDelaySeconds) * time.Second
+			resp, err = c.Repository.Nack(*payload.Queue, *payload.Id, *payload.Receipt, delay)
+		}
+		c.writeResult(rw, resp, err)
+	}
+}
+
+// validateQueue checks whether the queue name is given and valid.
+func (c Controller) validateQueue(rw http.ResponseWriter, queue *string) bool {
+	if queue == nil || *queue == "" {
+		c.badRequest(rw, "queue is missing")
+		return false
+	}
+
+	if !queueNamePattern.MatchString(*queue) {
+		c.badRequest(rw, "queue may only contain letters, digits and _.:- characters")
+		return false
+	}
+
+	return true
+}
+
+// validateAckRequest checks whether there are missing fields or not.
+func (c Controller) validateAckRequest(rw http.ResponseWriter, payload AckRequest) bool {
+	if !c.validateQueue(rw, payload.Queue) {
+		return false
+	}
+
+	if payload.Id == nil {
+		c.badRequest(rw, "id field is missing")
+		return false
+	}
+
+	if payload.Receipt == nil {
+		c.badRequest(rw, "receipt field is missing")
+		return false
+	}
+
+	if payload.RetryDelaySeconds < 0 {
+		c.badRequest(rw, "retryDelaySeconds field must not be negative")
+		return false
+	}
+
+	return true
+}
+
+// parseRequestJSON reads all request body and unmarshals the JSON to the payload.
+// If the body cannot be parsed, it sends "400 Bad Request" as response.
+func (c Controller) parseRequestJSON(rw http.ResponseWriter, req *http.Request, payload interface{}) bool {
+	body, err := ioutil.ReadAll(req.Body)
+	if err == nil {
+		err = json.Unmarshal(body, payload)
+	}
+
+	if err != nil {
+		log.Printf("Error on parsing request JSON: %v", err)
+		c.badRequest(rw, err.Error())
+		return false
+	}
+
+	return true
+}
+
+// writeResult writes the response returned by the Repository.
+// If an error is returned, it means that an internal server error occurred.
+func (c Controller) writeResult(rw http.ResponseWriter, resp Response, err error) {
+	statusCode := http.StatusOK
+	if err != nil {
+		log.Printf("Error while operating on the queue: %v", err)
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.writeResponse(rw, statusCode, resp)
+}
+
+func (c Controller) badRequest(rw http.ResponseWriter, message string) {
+	c.writeResponse(rw, http.StatusBadRequest, Response{Error: message})
+}
+
+func (c Controller) methodNotAllowed(rw http.ResponseWriter) {
+	c.writeResponse(rw, http.StatusMethodNotAllowed, Response{Error: "the method is not allowed for this endpoint."})
+}
+
+// writeResponse converts the response object to
+// byte slice and writes it to response body.
+func (c Controller) writeResponse(rw http.ResponseWriter, statusCode int, resp Response) {
+	respBytes, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error on marshalling to JSON: %v", err)
+	}
+
+	rw.WriteHeader(statusCode)
+	_, err = rw.Write(respBytes)
+	if err != nil {
+		log.Printf("Error on writing response: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package queue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockService struct {
+	EnqueueMock func(string, string, time.Duration) (Response, error)
+	DequeueMock func(string, time.Duration) (Response, error)
+	AckMock     func(string, string, string) (Response, error)
+	NackMock    func(string, string, string, time.Duration) (Response, error)
+	StatsMock   func(string) (Response, error)
+}
+
+func (m mockService) Enqueue(queue string, payload string, delay time.Duration) (Response, error) {
+	return m.EnqueueMock(queue, payload, delay)
+}
+
+func (m mockService) Dequeue(queue string, visibility time.Duration) (Response, error) {
+	return m.DequeueMock(queue, visibility)
+}
+
+func (m mockService) Ack(queue string, id string, receipt string) (Response, error) {
+	return m.AckMock(queue, id, receipt)
+}
+
+func (m mockService) Nack(queue string, id string, receipt string, delay time.Duration) (Response, error) {
+	return m.NackMock(queue, id, receipt, delay)
+}
+
+func (m mockService) Stats(queue string) (Response, error) {
+	return m.StatsMock(queue)
+}
+
+func TestController_ServeHTTPValidEnqueue(t *testing.T) {
+	var gotDelay time.Duration
+	mock := mockService{
+		EnqueueMock: func(queue string, payload string, delay time.Duration) (Response, error) {
+			gotDelay = delay
+			return Response{Queue: queue, Id: "1"}, nil
+		},
+	}
+
+	request := "{\"queue\":\"emails\",\"payload\":\"getir\",\"delaySeconds\":10}"
+	req, err := http.NewRequest(http.MethodPost, "/queues/enqueue", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := Controller{Repository: mock}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusOK)
+	}
+
+	if gotDelay != 10*time.Second {
+		t.Errorf("enqueued with incorrect delay. got: %v, expected: %v", gotDelay, 10*time.Second)
+	}
+
+	expected := "{\"queue\":\"emails\",\"id\":\"1\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestController_ServeHTTPInvalidQueueName(t *testing.T) {
+	request := "{\"queue\":\"{emails}\",\"payload\":\"getir\"}"
+	req, err := http.NewRequest(http.MethodPost, "/queues/enqueue", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := Controller{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+}
+
+func TestController_ServeHTTPAckMissingReceipt(t *testing.T) {
+	request := "{\"queue\":\"emails\",\"id\":\"1\"}"
+	req, err := http.NewRequest(http.MethodPost, "/queues/ack", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := Controller{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusBadRequest {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusBadRequest)
+	}
+
+	expected := "{\"queue\":\"\",\"error\":\"receipt field is missing\"}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestController_ServeHTTPStats(t *testing.T) {
+	mock := mockService{
+		StatsMock: func(queue string) (Response, error) {
+			return Response{Queue: queue, Stats: &StatsDto{Ready: 3, InFlight: 1}}, nil
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "/queues/stats?queue=emails", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := Controller{Repository: mock}
+	controller.ServeHTTP(rr, req)
+
+	expected := "{\"queue\":\"emails\",\"stats\":{\"ready\":3,\"delayed\":0,\"inFlight\":1,\"deadLetter\":0}}"
+	if rr.Body.String() != expected {
+		t.Errorf("returned incorrect response body. got: %v, expected: %v", rr.Body.String(), expected)
+	}
+}
+
+func TestController_ServeHTTPUnknownAction(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/queues/purge", nil)
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	controller := Controller{}
+	controller.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", status, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,33 @@
+package queue
+
+// JobDto is used for representing a job in a queue.
+// After a dequeue operation, if the queue is empty, Exists field is set to false.
+// Receipt identifies a single delivery of the job, it is required to acknowledge the delivery.
+type JobDto struct {
+	Queue    string
+	Id       string
+	Receipt  string
+	Payload  string
+	Attempts int
+	Exists   bool
+}
+
+// NackResult specifies what happened to a job after a negative acknowledgement.
+type NackResult int
+
+const (
+	// NackNotFound is returned if the job is not in flight or the receipt is outdated.
+	NackNotFound NackResult = iota
+	// NackRetried is returned if the job is scheduled to be retried.
+	NackRetried
+	// NackDeadLettered is returned if the job is moved to the dead-letter queue.
+	NackDeadLettered
+)
+
+// StatsDto is used for representing the depth of a queue.
+type StatsDto struct {
+	Ready      int64 `json:"ready"`
+	Delayed    int64 `json:"delayed"`
+	InFlight   int64 `json:"inFlight"`
+	DeadLetter int64 `json:"deadLetter"`
+}
@@ -0,0 +1,208 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+// KeyPrefix prefixes the keys of the queues in the database.
+const KeyPrefix = "queue:"
+
+// maxRequeuedPerDequeue limits the number of delayed and expired jobs
+// moved back to the ready list by a single dequeue operation.
+const maxRequeuedPerDequeue = 100
+
+// dequeueScript moves the delayed jobs which are due and the in-flight jobs
+// whose visibility timeout expired back to the ready list, or to the dead-letter list
+// if they ran out of attempts. Then, it pops a job from the ready list and marks it in flight.
+var dequeueScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local maxAttempts = tonumber(ARGV[3])
+
+local due = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', now, 'LIMIT', 0, ARGV[5])
+for _, id in ipairs(due) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('LPUSH', KEYS[1], id)
+end
+
+local expired = redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', now, 'LIMIT', 0, ARGV[5])
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[3], id)
+	redis.call('HDEL', KEYS[5], id)
+	if tonumber(redis.call('HGET', KEYS[4], id) or '0') >= maxAttempts then
+		redis.call('LPUSH', KEYS[6], id)
+	else
+		redis.call('RPUSH', KEYS[1], id)
+	end
+end
+
+local id = redis.call('RPOP', KEYS[1])
+if not id then
+	return false
+end
+
+local attempts = redis.call('HINCRBY', KEYS[4], id, 1)
+redis.call('ZADD', KEYS[3], now + tonumber(ARGV[2]), id)
+redis.call('HSET', KEYS[5], id, ARGV[4])
+return {id, redis.call('HGET', KEYS[7], id), attempts}
+`)
+
+// ackScript removes an in-flight job if the receipt matches the last delivery of it.
+var ackScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('HDEL', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+return 1
+`)
+
+// nackScript schedules an in-flight job to be retried if the receipt matches the last delivery of it.
+// If the job ran out of attempts, it is moved to the dead-letter list.
+var nackScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[2], ARGV[1])
+if tonumber(redis.call('HGET', KEYS[3], ARGV[1]) or '0') >= tonumber(ARGV[4]) then
+	redis.call('LPUSH', KEYS[5], ARGV[1])
+	return 2
+end
+
+redis.call('ZADD', KEYS[4], ARGV[3], ARGV[1])
+return 1
+`)
+
+// RedisDao manages the queues stored in the Redis database.
+// A queue consists of the keys below, all sharing the same hash tag
+// so that they are stored in the same slot on a Redis cluster:
+//  - ready: list of the job ids which can be dequeued,
+//  - delayed: sorted set of the job ids scored by the time they become ready,
+//  - inflight: sorted set of the job ids scored by their visibility deadline,
+//  - payloads, attempts, receipts: hashes of the job details by job ids,
+//  - dead: list of the job ids which ran out of attempts.
+type RedisDao struct {
+	Db *redis.Client
+}
+
+// Enqueue stores the job and pushes it to the ready list,
+// or to the delayed jobs if delay is positive.
+func (d RedisDao) Enqueue(dto JobDto, delay time.Duration) error {
+	_, err := d.Db.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		pipe.HSet(context.Background(), queueKey(dto.Queue, "payloads"), dto.Id, dto.Payload)
+		if delay > 0 {
+			readyAt := float64(toMillis(time.Now().Add(delay)))
+			pipe.ZAdd(context.Background(), queueKey(dto.Queue, "delayed"), &redis.Z{Score: readyAt, Member: dto.Id})
+		} else {
+			pipe.LPush(context.Background(), queueKey(dto.Queue, "ready"), dto.Id)
+		}
+		return nil
+	})
+	return err
+}
+
+// Dequeue pops a job from the queue and hides it from the other consumers
+// until the visibility timeout expires.
+func (d RedisDao) Dequeue(queue string, receipt string, visibility time.Duration, maxAttempts int) (JobDto, error) {
+	dto := JobDto{Queue: queue}
+
+	keys := []string{
+		queueKey(queue, "ready"),
+		queueKey(queue, "delayed"),
+		queueKey(queue, "inflight"),
+		queueKey(queue, "attempts"),
+		queueKey(queue, "receipts"),
+		queueKey(queue, "dead"),
+		queueKey(queue, "payloads"),
+	}
+	res, err := dequeueScript.Run(context.Background(), d.Db, keys,
+		toMillis(time.Now()), visibility.Milliseconds(), maxAttempts, receipt, maxRequeuedPerDequeue).Slice()
+	if err == redis.Nil {
+		return dto, nil
+	}
+
+	if err != nil {
+		return dto, err
+	}
+
+	if len(res) != 3 {
+		return dto, fmt.Errorf("unexpected reply of dequeue script: %v", res)
+	}
+
+	attempts, _ := res[2].(int64)
+	dto.Exists = true
+	dto.Id = fmt.Sprint(res[0])
+	dto.Receipt = receipt
+	dto.Attempts = int(attempts)
+	// the payload is nil if it is lost, e.g. the keys are evicted.
+	if res[1] != nil {
+		dto.Payload = fmt.Sprint(res[1])
+	}
+	return dto, nil
+}
+
+// Ack removes the delivered job from the queue.
+// It returns false if the job is not in flight or the receipt is outdated.
+func (d RedisDao) Ack(queue string, id string, receipt string) (bool, error) {
+	keys := []string{
+		queueKey(queue, "inflight"),
+		queueKey(queue, "receipts"),
+		queueKey(queue, "attempts"),
+		queueKey(queue, "payloads"),
+	}
+	acked, err := ackScript.Run(context.Background(), d.Db, keys, id, receipt).Int()
+	return acked == 1, err
+}
+
+// Nack schedules the delivered job to be retried after the delay,
+// or moves it to the dead-letter list if it ran out of attempts.
+func (d RedisDao) Nack(queue string, id string, receipt string, delay time.Duration, maxAttempts int) (NackResult, error) {
+	keys := []string{
+		queueKey(queue, "inflight"),
+		queueKey(queue, "receipts"),
+		queueKey(queue, "attempts"),
+		queueKey(queue, "delayed"),
+		queueKey(queue, "dead"),
+	}
+	retryAt := toMillis(time.Now().Add(delay))
+	result, err := nackScript.Run(context.Background(), d.Db, keys, id, receipt, retryAt, maxAttempts).Int()
+	return NackResult(result), err
+}
+
+// Stats fetches the number of the jobs in each state.
+func (d RedisDao) Stats(queue string) (StatsDto, error) {
+	var ready, delayed, inFlight, dead *redis.IntCmd
+	_, err := d.Db.Pipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		ready = pipe.LLen(context.Background(), queueKey(queue, "ready"))
+		delayed = pipe.ZCard(context.Background(), queueKey(queue, "delayed"))
+		inFlight = pipe.ZCard(context.Background(), queueKey(queue, "inflight"))
+		dead = pipe.LLen(context.Background(), queueKey(queue, "dead"))
+		return nil
+	})
+	if err != nil {
+		return StatsDto{}, err
+	}
+
+	return StatsDto{
+		Ready:      ready.Val(),
+		Delayed:    delayed.Val(),
+		InFlight:   inFlight.Val(),
+		DeadLetter: dead.Val(),
+	}, nil
+}
+
+// queueKey returns the Redis key of a part of the queue.
+func queueKey(queue string, part string) string {
+	return fmt.Sprintf("%v{%v}:%v", KeyPrefix, queue, part)
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
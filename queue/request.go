@@ -0,0 +1,26 @@
+package queue
+
+// EnqueueRequest represents the request payload to enqueue a job.
+// The job is ready to be dequeued after DelaySeconds.
+type EnqueueRequest struct {
+	Queue        *string `json:"queue"`
+	Payload      *string `json:"payload"`
+	DelaySeconds int     `json:"delaySeconds"`
+}
+
+// DequeueRequest represents the request payload to dequeue a job.
+// If VisibilityTimeoutSeconds is not given, the default visibility timeout is used.
+type DequeueRequest struct {
+	Queue                    *string `json:"queue"`
+	VisibilityTimeoutSeconds int     `json:"visibilityTimeoutSeconds"`
+}
+
+// AckRequest represents the request payload to acknowledge or
+// negatively acknowledge a delivered job. RetryDelaySeconds is only
+// used by negative acknowledgements, if it is not given, the default retry delay is used.
+type AckRequest struct {
+	Queue             *string `json:"queue"`
+	Id                *string `json:"id"`
+	Receipt           *string `json:"receipt"`
+	RetryDelaySeconds int     `json:"retryDelaySeconds"`
+}
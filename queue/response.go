@@ -0,0 +1,13 @@
+package queue
+
+// Response represents the response payload.
+type Response struct {
+	Queue        string    `json:"queue"`
+	Id           string    `json:"id,omitempty"`
+	Receipt      string    `json:"receipt,omitempty"`
+	Payload      string    `json:"payload,omitempty"`
+	Attempts     int       `json:"attempts,omitempty"`
+	DeadLettered bool      `json:"deadLettered,omitempty"`
+	Stats        *StatsDto `json:"stats,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
@@ -0,0 +1,135 @@
+// Package queue manages reliable work queues stored in an in-memory database.
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Dao interface is used by a Service to access the queues.
+type Dao interface {
+	Enqueue(dto JobDto, delay time.Duration) error
+	Dequeue(queue string, receipt string, visibility time.Duration, maxAttempts int) (JobDto, error)
+	Ack(queue string, id string, receipt string) (bool, error)
+	Nack(queue string, id string, receipt string, delay time.Duration, maxAttempts int) (NackResult, error)
+	Stats(queue string) (StatsDto, error)
+}
+
+// Service uses Dao to operate on the queues and
+// creates responses according to the possible errors.
+// A job is moved to the dead-letter queue after it is delivered MaxAttempts times without an acknowledgement.
+type Service struct {
+	Dao               Dao
+	MaxAttempts       int
+	VisibilityTimeout time.Duration
+	RetryDelay        time.Duration
+}
+
+// Enqueue adds a job with the payload to the queue.
+// The job can be dequeued after the delay.
+func (s Service) Enqueue(queue string, payload string, delay time.Duration) (Response, error) {
+	id, err := newToken()
+	if err != nil {
+		return Response{Queue: queue, Error: "internal server error occurred."}, err
+	}
+
+	err = s.Dao.Enqueue(JobDto{
+		Queue:   queue,
+		Id:      id,
+		Payload: payload,
+	}, delay)
+	if err != nil {
+		return Response{Queue: queue, Error: "internal server error occurred."}, err
+	}
+
+	return Response{Queue: queue, Id: id}, nil
+}
+
+// Dequeue delivers a job from the queue. The job is not delivered to the other
+// consumers until the visibility timeout expires. If the visibility timeout is zero,
+// the default visibility timeout is used.
+func (s Service) Dequeue(queue string, visibility time.Duration) (Response, error) {
+	if visibility <= 0 {
+		visibility = s.VisibilityTimeout
+	}
+
+	receipt, err := newToken()
+	if err != nil {
+		return Response{Queue: queue, Error: "internal server error occurred."}, err
+	}
+
+	dto, err := s.Dao.Dequeue(queue, receipt, visibility, s.MaxAttempts)
+	if err != nil {
+		return Response{Queue: queue, Error: "internal server error occurred."}, err
+	}
+
+	if !dto.Exists {
+		return Response{Queue: queue, Error: "queue specified is empty."}, nil
+	}
+
+	return Response{
+		Queue:    queue,
+		Id:       dto.Id,
+		Receipt:  dto.Receipt,
+		Payload:  dto.Payload,
+		Attempts: dto.Attempts,
+	}, nil
+}
+
+// Ack acknowledges the delivery of the job and removes it from the queue.
+func (s Service) Ack(queue string, id string, receipt string) (Response, error) {
+	acked, err := s.Dao.Ack(queue, id, receipt)
+	if err != nil {
+		return Response{Queue: queue, Id: id, Error: "internal server error occurred."}, err
+	}
+
+	if !acked {
+		return Response{Queue: queue, Id: id, Error: "job specified is not in flight or the receipt is outdated."}, nil
+	}
+
+	return Response{Queue: queue, Id: id}, nil
+}
+
+// Nack negatively acknowledges the delivery of the job so that it is retried after the delay.
+// If the delay is zero, the default retry delay is used.
+func (s Service) Nack(queue string, id string, receipt string, delay time.Duration) (Response, error) {
+	if delay <= 0 {
+		delay = s.RetryDelay
+	}
+
+	result, err := s.Dao.Nack(queue, id, receipt, delay, s.MaxAttempts)
+	if err != nil {
+		return Response{Queue: queue, Id: id, Error: "internal server error occurred."}, err
+	}
+
+	switch result {
+	case NackRetried:
+		return Response{Queue: queue, Id: id}, nil
+	case NackDeadLettered:
+		return Response{Queue: queue, Id: id, DeadLettered: true}, nil
+	default:
+		return Response{Queue: queue, Id: id, Error: "job specified is not in flight or the receipt is outdated."}, nil
+	}
+}
+
+// Stats returns the depth of the queue.
+func (s Service) Stats(queue string) (Response, error) {
+	stats, err := s.Dao.Stats(queue)
+	if err != nil {
+		return Response{Queue: queue, Error: "internal server error occurred."}, err
+	}
+
+	return Response{Queue: queue, Stats: &stats}, nil
+}
+
+// newToken generates a random identifier for jobs and deliveries.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
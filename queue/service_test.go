@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type mockDao struct {
+	EnqueueMock func(JobDto, time.Duration) error
+	DequeueMock func(string, string, time.Duration, int) (JobDto, error)
+	AckMock     func(string, string, string) (bool, error)
+	NackMock    func(string, string, string, time.Duration, int) (NackResult, error)
+	StatsMock   func(string) (StatsDto, error)
+}
+
+func (m mockDao) Enqueue(dto JobDto, delay time.Duration) error {
+	return m.EnqueueMock(dto, delay)
+}
+
+func (m mockDao) Dequeue(queue string, receipt string, visibility time.Duration, maxAttempts int) (JobDto, error) {
+	return m.DequeueMock(queue, receipt, visibility, maxAttempts)
+}
+
+func (m mockDao) Ack(queue string, id string, receipt string) (bool, error) {
+	return m.AckMock(queue, id, receipt)
+}
+
+func (m mockDao) Nack(queue string, id string, receipt string, delay time.Duration, maxAttempts int) (NackResult, error) {
+	return m.NackMock(queue, id, receipt, delay, maxAttempts)
+}
+
+func (m mockDao) Stats(queue string) (StatsDto, error) {
+	return m.StatsMock(queue)
+}
+
+func TestService_EnqueueGeneratesId(t *testing.T) {
+	var got JobDto
+	mock := mockDao{
+		EnqueueMock: func(dto JobDto, delay time.Duration) error {
+			got = dto
+			return nil
+		},
+	}
+
+	service := Service{Dao: mock}
+	resp, _ := service.Enqueue("emails", "{\"to\":\"getir\"}", 0)
+
+	if got.Id == "" || resp.Id != got.Id {
+		t.Errorf("returned incorrect id. got: %v, expected: %v", resp.Id, got.Id)
+	}
+
+	if got.Payload != "{\"to\":\"getir\"}" {
+		t.Errorf("enqueued incorrect payload. got: %v", got.Payload)
+	}
+}
+
+func TestService_DequeueUsesDefaultVisibilityTimeout(t *testing.T) {
+	var gotVisibility time.Duration
+	var gotMaxAttempts int
+	mock := mockDao{
+		DequeueMock: func(queue string, receipt string, visibility time.Duration, maxAttempts int) (JobDto, error) {
+			gotVisibility, gotMaxAttempts = visibility, maxAttempts
+			return JobDto{Queue: queue, Id: "1", Receipt: receipt, Payload: "getir", Attempts: 1, Exists: true}, nil
+		},
+	}
+
+	service := Service{Dao: mock, MaxAttempts: 3, VisibilityTimeout: 30 * time.Second}
+	resp, _ := service.Dequeue("emails", 0)
+
+	if gotVisibility != 30*time.Second {
+		t.Errorf("used incorrect visibility timeout. got: %v, expected: %v", gotVisibility, 30*time.Second)
+	}
+
+	if gotMaxAttempts != 3 {
+		t.Errorf("used incorrect max attempts. got: %v, expected: %v", gotMaxAttempts, 3)
+	}
+
+	if resp.Receipt == "" || resp.Payload != "getir" {
+		t.Errorf("returned incorrect job. got: %+v", resp)
+	}
+}
+
+func TestService_DequeueEmptyQueue(t *testing.T) {
+	mock := mockDao{
+		DequeueMock: func(queue string, receipt string, visibility time.Duration, maxAttempts int) (JobDto, error) {
+			return JobDto{Queue: queue}, nil
+		},
+	}
+
+	service := Service{Dao: mock}
+	resp, _ := service.Dequeue("emails", time.Second)
+
+	expected := "queue specified is empty."
+	if resp.Error != expected {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", resp.Error, expected)
+	}
+}
+
+func TestService_NackDeadLettered(t *testing.T) {
+	mock := mockDao{
+		NackMock: func(queue string, id string, receipt string, delay time.Duration, maxAttempts int) (NackResult, error) {
+			return NackDeadLettered, nil
+		},
+	}
+
+	service := Service{Dao: mock, MaxAttempts: 3, RetryDelay: time.Second}
+	resp, _ := service.Nack("emails", "1", "receipt", 0)
+
+	if !resp.DeadLettered {
+		t.Errorf("returned incorrect dead-letter state. got: %v, expected: %v", resp.DeadLettered, true)
+	}
+}
+
+func TestService_StatsInternalError(t *testing.T) {
+	mock := mockDao{
+		StatsMock: func(queue string) (StatsDto, error) {
+			return StatsDto{}, fmt.Errorf("mock error")
+		},
+	}
+
+	service := Service{Dao: mock}
+	resp, err := service.Stats("emails")
+
+	if err == nil {
+		t.Errorf("returned no error")
+	}
+
+	expected := "internal server error occurred."
+	if resp.Error != expected {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", resp.Error, expected)
+	}
+}
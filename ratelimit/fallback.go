@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"log"
+	"sync"
+	"time"
+)
+
+// Fallback limits the requests by Primary, and by Secondary while Primary fails.
+// After a failure, Primary is not used for Cooldown so that the requests are not
+// delayed by a limiter which is not reachable.
+type Fallback struct {
+	Primary   api.RateLimiter
+	Secondary api.RateLimiter
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failedAt time.Time
+	now      func() time.Time
+}
+
+// Allow takes a token from the bucket of the key by the limiter which is available.
+func (f *Fallback) Allow(ctx context.Context, key string, limit api.Limit) (api.RateLimitResult, error) {
+	now := time.Now()
+	if f.now != nil {
+		now = f.now()
+	}
+
+	f.mu.Lock()
+	cooling := !f.failedAt.IsZero() && now.Sub(f.failedAt) < f.Cooldown
+	f.mu.Unlock()
+
+	if !cooling {
+		result, err := f.Primary.Allow(ctx, key, limit)
+		if err == nil {
+			return result, nil
+		}
+
+		// the requests canceled by their clients do not indicate a failure of the limiter.
+		if ctx.Err() != nil {
+			return api.RateLimitResult{}, ctx.Err()
+		}
+
+		log.Printf("Error on rate limiting, falling back for %v: %v", f.Cooldown, err)
+		f.mu.Lock()
+		f.failedAt = now
+		f.mu.Unlock()
+	}
+
+	return f.Secondary.Allow(ctx, key, limit)
+}
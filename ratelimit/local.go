@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the interval the full buckets are removed from a LocalLimiter.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	fullAt    time.Time
+}
+
+// LocalLimiter keeps the token buckets in memory, the limits are not shared by the replicas
+// of the application. The buckets which are full are removed as they are not different from
+// the buckets which do not exist.
+type LocalLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+// NewLocalLimiter creates a limiter keeping the token buckets in memory.
+func NewLocalLimiter() *LocalLimiter {
+	return &LocalLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Allow takes a token from the bucket of the key if it is available.
+func (l *LocalLimiter) Allow(ctx context.Context, key string, limit api.Limit) (api.RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.sweptAt) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+	}
+	b.updatedAt = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.fullAt = now.Add(secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate))
+
+	return resultOf(allowed, b.tokens, limit), nil
+}
+
+// sweep removes the buckets which are full by now.
+func (l *LocalLimiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if !now.Before(b.fullAt) {
+			delete(l.buckets, key)
+		}
+	}
+	l.sweptAt = now
+}
@@ -0,0 +1,73 @@
+// Package ratelimit implements the token buckets limiting the requests of the clients.
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+// Limits holds the rate limits of the endpoints. The endpoints which
+// do not have a limit of their own are limited by Default.
+type Limits struct {
+	Default   api.Limit
+	Endpoints map[string]api.Limit
+}
+
+// LoadLimits reads the rate limits of the endpoints from a JSON file in the format of
+// {"/records": {"rate": 5, "burst": 10}}. A zero rate disables the limit of an endpoint.
+func LoadLimits(path string, defaultLimit api.Limit) (Limits, error) {
+	limits := Limits{Default: defaultLimit}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return limits, err
+	}
+
+	err = json.Unmarshal(content, &limits.Endpoints)
+	if err != nil {
+		return limits, fmt.Errorf("error on parsing the rate limit file: %w", err)
+	}
+
+	for endpoint, limit := range limits.Endpoints {
+		if limit.Rate < 0 || limit.Burst < 0 || (limit.Rate > 0 && limit.Burst == 0) {
+			return limits, fmt.Errorf("rate limit of the endpoint %q must have a positive burst", endpoint)
+		}
+	}
+
+	return limits, nil
+}
+
+// Of returns the rate limit of the endpoint.
+func (l Limits) Of(endpoint string) api.Limit {
+	if limit, ok := l.Endpoints[endpoint]; ok {
+		return limit
+	}
+
+	return l.Default
+}
+
+// resultOf returns the result of a request which left the tokens in the bucket.
+func resultOf(allowed bool, tokens float64, limit api.Limit) api.RateLimitResult {
+	result := api.RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / limit.Rate)
+	}
+
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds * float64(time.Second))
+}
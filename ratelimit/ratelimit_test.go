@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type mockLimiter struct {
+	AllowMock func(ctx context.Context, key string, limit api.Limit) (api.RateLimitResult, error)
+}
+
+func (m mockLimiter) Allow(ctx context.Context, key string, limit api.Limit) (api.RateLimitResult, error) {
+	return m.AllowMock(ctx, key, limit)
+}
+
+func TestLocalLimiter_Allow(t *testing.T) {
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewLocalLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := api.Limit{Rate: 2, Burst: 3}
+
+	tests := []struct {
+		elapsed  time.Duration
+		expected api.RateLimitResult
+	}{
+		{0, api.RateLimitResult{Allowed: true, Remaining: 2, Reset: 500 * time.Millisecond}},
+		{0, api.RateLimitResult{Allowed: true, Remaining: 1, Reset: time.Second}},
+		{0, api.RateLimitResult{Allowed: true, Remaining: 0, Reset: 1500 * time.Millisecond}},
+		{0, api.RateLimitResult{Allowed: false, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{250 * time.Millisecond, api.RateLimitResult{Allowed: false, Remaining: 0, Reset: 1250 * time.Millisecond, RetryAfter: 250 * time.Millisecond}},
+		{250 * time.Millisecond, api.RateLimitResult{Allowed: true, Remaining: 0, Reset: 1500 * time.Millisecond}},
+		{time.Hour, api.RateLimitResult{Allowed: true, Remaining: 2, Reset: 500 * time.Millisecond}},
+	}
+
+	for i, test := range tests {
+		now = now.Add(test.elapsed)
+		result, err := limiter.Allow(context.Background(), "/records:apikey:team-a", limit)
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if result != test.expected {
+			t.Errorf("returned incorrect result of request %v. got: %+v, expected: %+v", i, result, test.expected)
+		}
+	}
+
+	// the buckets of the other keys are not affected.
+	result, err := limiter.Allow(context.Background(), "/records:apikey:team-b", limit)
+	if err != nil || result.Remaining != 2 {
+		t.Errorf("returned incorrect result of another key. got: %+v %v, expected: 2 remaining", result, err)
+	}
+}
+
+func TestLocalLimiter_Sweep(t *testing.T) {
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewLocalLimiter()
+	limiter.now = func() time.Time { return now }
+
+	for _, key := range []string{"fast", "slow"} {
+		if _, err := limiter.Allow(context.Background(), key, api.Limit{Rate: 1, Burst: 1}); err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+	}
+
+	// the bucket refilled in 2 minutes is still used after a minute.
+	if _, err := limiter.Allow(context.Background(), "slow", api.Limit{Rate: 1.0 / 120, Burst: 1}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	now = now.Add(sweepInterval)
+	if _, err := limiter.Allow(context.Background(), "other", api.Limit{Rate: 1, Burst: 1}); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if _, ok := limiter.buckets["fast"]; ok {
+		t.Errorf("returned incorrect buckets. got: %v, expected: the full bucket is removed", limiter.buckets)
+	}
+
+	if _, ok := limiter.buckets["slow"]; !ok {
+		t.Errorf("returned incorrect buckets. got: %v, expected: the bucket which is not full is kept", limiter.buckets)
+	}
+}
+
+func TestFallback_Allow(t *testing.T) {
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	primaryCalls := 0
+	primaryErr := errors.New("connection refused")
+	fallback := &Fallback{
+		Primary: mockLimiter{AllowMock: func(ctx context.Context, key string, limit api.Limit) (api.RateLimitResult, error) {
+			primaryCalls++
+			return api.RateLimitResult{Allowed: true, Remaining: 9}, primaryErr
+		}},
+		Secondary: mockLimiter{AllowMock: func(ctx context.Context, key string, limit api.Limit) (api.RateLimitResult, error) {
+			return api.RateLimitResult{Allowed: true, Remaining: 1}, nil
+		}},
+		Cooldown: 5 * time.Second,
+		now:      func() time.Time { return now },
+	}
+
+	tests := []struct {
+		elapsed      time.Duration
+		err          error
+		remaining    int
+		primaryCalls int
+	}{
+		{0, primaryErr, 1, 1},
+		// the primary limiter is not used while it is cooling down.
+		{time.Second, primaryErr, 1, 1},
+		{5 * time.Second, nil, 9, 2},
+		{0, nil, 9, 3},
+	}
+
+	for i, test := range tests {
+		now = now.Add(test.elapsed)
+		primaryErr = test.err
+
+		result, err := fallback.Allow(context.Background(), "key", api.Limit{Rate: 1, Burst: 10})
+		if err != nil {
+			t.Fatalf("Error on testing: %v", err)
+		}
+
+		if result.Remaining != test.remaining || primaryCalls != test.primaryCalls {
+			t.Errorf("returned incorrect result of request %v. got: %v remaining, %v primary calls, expected: %v remaining, %v primary calls",
+				i, result.Remaining, primaryCalls, test.remaining, test.primaryCalls)
+		}
+	}
+}
+
+func TestLoadLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratelimits.json")
+	content := "{\"/records\": {\"rate\": 5, \"burst\": 10}, \"/in-memory\": {\"rate\": 0}}"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	limits, err := LoadLimits(path, api.Limit{Rate: 50, Burst: 100})
+	if err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	tests := []struct {
+		endpoint string
+		expected api.Limit
+	}{
+		{"/records", api.Limit{Rate: 5, Burst: 10}},
+		{"/in-memory", api.Limit{}},
+		{"/queues/", api.Limit{Rate: 50, Burst: 100}},
+	}
+
+	for _, test := range tests {
+		if got := limits.Of(test.endpoint); got != test.expected {
+			t.Errorf("returned incorrect limit of %q. got: %+v, expected: %+v", test.endpoint, got, test.expected)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte("{\"/records\": {\"rate\": 5}}"), 0600); err != nil {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	if _, err := LoadLimits(path, api.Limit{}); err == nil {
+		t.Errorf("returned incorrect error. got: %v, expected: an error for the missing burst", err)
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/skarakasoglu/g-case-challenge/api"
+	"math"
+	"strconv"
+)
+
+// allowScript refills the token bucket by the time elapsed since it is updated and takes
+// a token if it is available. The time of the Redis server is used so that the buckets are
+// refilled consistently by all the replicas. The bucket expires when it would be full.
+var allowScript = redis.NewScript(`
+redis.replicate_commands()
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updatedAt')
+local tokens = tonumber(bucket[1])
+if tokens == nil then
+	tokens = burst
+else
+	tokens = math.min(burst, tokens + math.max(0, now - tonumber(bucket[2])) * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updatedAt', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// KeyPrefix prefixes the keys of the token buckets in the database.
+const KeyPrefix = "ratelimit:"
+
+// RedisLimiter keeps the token buckets in the Redis database,
+// so that the limits are shared by the replicas of the application.
+type RedisLimiter struct {
+	Db *redis.Client
+}
+
+// Allow takes a token from the bucket of the key if it is available.
+func (l RedisLimiter) Allow(ctx context.Context, key string, limit api.Limit) (api.RateLimitResult, error) {
+	reply, err := allowScript.Run(ctx, l.Db, []string{KeyPrefix + key}, limit.Rate, limit.Burst).Slice()
+	if err != nil {
+		return api.RateLimitResult{}, err
+	}
+
+	if len(reply) != 2 {
+		return api.RateLimitResult{}, fmt.Errorf("unexpected reply of the rate limit script: %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	remaining, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil || math.IsNaN(tokens) {
+		return api.RateLimitResult{}, fmt.Errorf("unexpected tokens of the rate limit script: %v", reply[1])
+	}
+
+	return resultOf(allowed == 1, tokens, limit), nil
+}
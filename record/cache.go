@@ -0,0 +1,91 @@
+package record
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+)
+
+// CacheName identifies the cache of the records in Cache-Status header.
+const CacheName = "records"
+
+// CacheEntry is the entry of a filter read from a CacheDao. The records are set only if
+// Found is true, Ttl is the time left before they expire. Generation is the generation
+// of the cache the entry is read in, a missing entry must be stored in the same generation.
+type CacheEntry struct {
+	Generation int64
+	Found      bool
+	Records    []Dto
+	Ttl        time.Duration
+}
+
+// CacheDao stores the records of the filters for a while. The entries are stored in
+// generations, an invalidation starts a new generation so that the entries stored
+// before it, and the entries of the queries started before it, are not read anymore.
+type CacheDao interface {
+	Get(key string) (CacheEntry, error)
+	Set(generation int64, key string, records []Dto, ttl time.Duration) error
+	Invalidate() error
+}
+
+// CachedRepository is a Repository serving the records of the filters fetched
+// recently from the cache, and fetching the others from the wrapped Repository.
+// The records are cached for Ttl. The cache is bypassed if it fails.
+type CachedRepository struct {
+	Repository Repository
+	Dao        CacheDao
+	Ttl        time.Duration
+}
+
+// Fetch returns the cached records of the filter if they are found,
+// otherwise it fetches them from the wrapped Repository and caches them.
+// The status of the cache is set in the Cache field of the response.
+func (r CachedRepository) Fetch(options FilterOptions) (Response, error) {
+	key := keyOf(options)
+	entry, err := r.Dao.Get(key)
+	if err != nil {
+		log.Printf("Error on reading the cached records of %v, the cache is bypassed: %v", key, err)
+		resp, err := r.Repository.Fetch(options)
+		resp.Cache = CacheStatus(CacheName + "; fwd=miss; detail=unavailable")
+		return resp, err
+	}
+
+	if entry.Found {
+		resp := Response{
+			Code:    0,
+			Message: "Success",
+			Records: entry.Records,
+			Cache:   CacheStatus(fmt.Sprintf("%v; hit; ttl=%v", CacheName, int64(math.Ceil(entry.Ttl.Seconds())))),
+		}
+		return resp, nil
+	}
+
+	resp, err := r.Repository.Fetch(options)
+	resp.Cache = CacheStatus(CacheName + "; fwd=miss")
+	// the stale records are served while the database is not available, they are not cached.
+	if err != nil || resp.Stale {
+		return resp, err
+	}
+
+	if err := r.Dao.Set(entry.Generation, key, resp.Records, r.Ttl); err != nil {
+		log.Printf("Error on caching the records of %v: %v", key, err)
+		return resp, nil
+	}
+
+	resp.Cache += "; stored"
+	return resp, nil
+}
+
+// Invalidate removes the cached records of all the filters, it must be called after the records are written.
+func (r CachedRepository) Invalidate() error {
+	return r.Dao.Invalidate()
+}
+
+// keyOf returns the key of the filter. The dates are normalized to UTC, so that the
+// filters of the same dates in different time zones are identified by the same key.
+func keyOf(options FilterOptions) string {
+	return fmt.Sprintf("%v:%v:%v:%v",
+		options.StartDate.UTC().Format(time.RFC3339Nano), options.EndDate.UTC().Format(time.RFC3339Nano),
+		options.MinCount, options.MaxCount)
+}
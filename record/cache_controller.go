@@ -0,0 +1,35 @@
+package record
+
+import (
+	"log"
+	"net/http"
+)
+
+// Invalidator is used by a CacheController to remove the cached records.
+type Invalidator interface {
+	Invalidate() error
+}
+
+// CacheController is a handler for handling
+// requests coming to "/admin/records/cache" endpoint.
+type CacheController struct {
+	Cache Invalidator
+}
+
+// ServeHTTP handles incoming requests to "/admin/records/cache" endpoint.
+// DELETE requests invalidate the cached records of all the filters,
+// they are sent by the services writing the records.
+func (c CacheController) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodDelete:
+		if err := c.Cache.Invalidate(); err != nil {
+			log.Printf("Error on invalidating the records cache: %v", err)
+			writeResponse(rw, http.StatusInternalServerError, Response{Code: 3, Message: "internal server error occurred."})
+			return
+		}
+
+		writeResponse(rw, http.StatusOK, Response{Code: 0, Message: "the cache is invalidated."})
+	default:
+		writeResponse(rw, http.StatusMethodNotAllowed, Response{Code: 1, Message: "the method is not allowed for this endpoint."})
+	}
+}
@@ -0,0 +1,170 @@
+package record
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockCacheDao struct {
+	GetMock        func(key string) (CacheEntry, error)
+	SetMock        func(generation int64, key string, records []Dto, ttl time.Duration) error
+	InvalidateMock func() error
+}
+
+func (m mockCacheDao) Get(key string) (CacheEntry, error) {
+	return m.GetMock(key)
+}
+
+func (m mockCacheDao) Set(generation int64, key string, records []Dto, ttl time.Duration) error {
+	return m.SetMock(generation, key, records, ttl)
+}
+
+func (m mockCacheDao) Invalidate() error {
+	return m.InvalidateMock()
+}
+
+func TestCachedRepository_Fetch(t *testing.T) {
+	tests := []struct {
+		name      string
+		entry     CacheEntry
+		getErr    error
+		fetchErr  error
+		fetches   int
+		stored    bool
+		cache     CacheStatus
+		errorCode int
+	}{
+		{"hit", CacheEntry{Generation: 2, Found: true, Records: mockData, Ttl: 41500 * time.Millisecond}, nil, nil, 0, false, "records; hit; ttl=42", 0},
+		{"miss", CacheEntry{Generation: 2}, nil, nil, 1, true, "records; fwd=miss; stored", 0},
+		{"failed fetch", CacheEntry{Generation: 2}, nil, errors.New("connection refused"), 1, false, "records; fwd=miss", 3},
+		{"unavailable cache", CacheEntry{}, errors.New("connection refused"), nil, 1, false, "records; fwd=miss; detail=unavailable", 0},
+	}
+
+	for _, test := range tests {
+		fetches, stored := 0, false
+		repository := CachedRepository{
+			Repository: mockService{FetchMock: func(options FilterOptions) (Response, error) {
+				fetches++
+				if test.fetchErr != nil {
+					return Response{Code: 3, Message: "internal server error occurred."}, test.fetchErr
+				}
+				return Response{Code: 0, Message: "Success", Records: mockData}, nil
+			}},
+			Dao: mockCacheDao{
+				GetMock: func(key string) (CacheEntry, error) {
+					return test.entry, test.getErr
+				},
+				SetMock: func(generation int64, key string, records []Dto, ttl time.Duration) error {
+					stored = true
+					if generation != test.entry.Generation || ttl != time.Minute || len(records) != len(mockData) {
+						t.Errorf("returned incorrect entry for %v. got: %v %v %v, expected: %v %v %v",
+							test.name, generation, ttl, len(records), test.entry.Generation, time.Minute, len(mockData))
+					}
+					return nil
+				},
+			},
+			Ttl: time.Minute,
+		}
+
+		got, err := repository.Fetch(FilterOptions{MinCount: 100, MaxCount: 200})
+		if (err != nil) != (test.fetchErr != nil) {
+			t.Errorf("returned incorrect error for %v. got: %v, expected: %v", test.name, err, test.fetchErr)
+		}
+
+		if got.Code != test.errorCode || (test.errorCode == 0 && len(got.Records) != len(mockData)) {
+			t.Errorf("returned incorrect response for %v. got: %+v", test.name, got)
+		}
+
+		if got.Cache != test.cache {
+			t.Errorf("returned incorrect cache status for %v. got: %v, expected: %v", test.name, got.Cache, test.cache)
+		}
+
+		if fetches != test.fetches || stored != test.stored {
+			t.Errorf("returned incorrect fetches for %v. got: %v %v, expected: %v %v", test.name, fetches, stored, test.fetches, test.stored)
+		}
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	istanbul := time.FixedZone("Europe/Istanbul", 3*60*60)
+	utc := FilterOptions{StartDate: time.Date(2016, 1, 26, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2018, 2, 2, 0, 0, 0, 0, time.UTC), MinCount: 2700, MaxCount: 3000}
+	local := FilterOptions{StartDate: utc.StartDate.In(istanbul), EndDate: utc.EndDate.In(istanbul), MinCount: 2700, MaxCount: 3000}
+
+	expected := "2016-01-26T00:00:00Z:2018-02-02T00:00:00Z:2700:3000"
+	if got := keyOf(local); got != expected || keyOf(utc) != expected {
+		t.Errorf("returned incorrect key. got: %v, expected: %v", got, expected)
+	}
+}
+
+func TestCacheController_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		method     string
+		err        error
+		statusCode int
+		expected   string
+	}{
+		{http.MethodDelete, nil, http.StatusOK, `{"code":0,"msg":"the cache is invalidated.","records":null}`},
+		{http.MethodDelete, errors.New("connection refused"), http.StatusInternalServerError, `{"code":3,"msg":"internal server error occurred.","records":null}`},
+		{http.MethodGet, nil, http.StatusMethodNotAllowed, `{"code":1,"msg":"the method is not allowed for this endpoint.","records":null}`},
+	}
+
+	for _, test := range tests {
+		controller := CacheController{Cache: mockCacheDao{InvalidateMock: func() error {
+			return test.err
+		}}}
+
+		rr := httptest.NewRecorder()
+		controller.ServeHTTP(rr, httptest.NewRequest(test.method, "/admin/records/cache", nil))
+
+		if rr.Code != test.statusCode {
+			t.Errorf("returned incorrect status code for %v. got: %v, expected: %v", test.method, rr.Code, test.statusCode)
+		}
+
+		if rr.Body.String() != test.expected {
+			t.Errorf("returned incorrect response body for %v. got: %v, expected: %v", test.method, rr.Body.String(), test.expected)
+		}
+	}
+}
+
+func TestController_ServeHTTPCacheStatus(t *testing.T) {
+	mock := mockService{
+		FetchMock: func(options FilterOptions) (Response, error) {
+			return Response{Code: 0, Message: "Success", Records: mockData, Cache: "records; hit; ttl=42"}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/records", strings.NewReader(`{"startDate":"2017-01-27","endDate":"2017-01-29","minCount":0,"maxCount":1000}`))
+	rr := httptest.NewRecorder()
+	Controller{Repository: mock}.ServeHTTP(rr, req)
+
+	if status := rr.Header().Get("Cache-Status"); status != "records; hit; ttl=42" {
+		t.Errorf("returned incorrect cache status. got: %v, expected: %v", status, "records; hit; ttl=42")
+	}
+}
+
+func TestCachedRepository_FetchStale(t *testing.T) {
+	repository := CachedRepository{
+		Repository: mockService{FetchMock: func(options FilterOptions) (Response, error) {
+			return Response{Code: 0, Message: "Success", Records: mockData, Stale: true, Age: time.Minute}, nil
+		}},
+		Dao: mockCacheDao{
+			GetMock: func(key string) (CacheEntry, error) {
+				return CacheEntry{}, nil
+			},
+			SetMock: func(generation int64, key string, records []Dto, ttl time.Duration) error {
+				t.Errorf("Error on testing: the stale records of %v are cached", key)
+				return nil
+			},
+		},
+		Ttl: time.Minute,
+	}
+
+	got, err := repository.Fetch(FilterOptions{})
+	if err != nil || !got.Stale || got.Cache != "records; fwd=miss" {
+		t.Errorf("returned incorrect response. got: %+v %v, expected: stale records which are not stored", got, err)
+	}
+}
@@ -0,0 +1,86 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreaker while the database is not queried.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker is a Dao which stops querying the wrapped Dao after Threshold consecutive
+// failures, so that a failing database is not hammered by the requests. After Cooldown,
+// a single query is let through; the circuit is closed again if it succeeds. Threshold must be positive.
+type CircuitBreaker struct {
+	Dao       Dao
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+	now      func() time.Time
+}
+
+// Find queries the wrapped Dao unless the circuit is open.
+func (b *CircuitBreaker) Find(options FilterOptions) ([]Dto, error) {
+	now := b.clock()
+
+	b.mu.Lock()
+	if b.failures >= b.Threshold {
+		if b.probing || now.Sub(b.openedAt) < b.Cooldown {
+			retryIn := b.Cooldown - now.Sub(b.openedAt)
+			b.mu.Unlock()
+			return nil, fmt.Errorf("%w: the database is queried again in %v", ErrCircuitOpen, retryIn)
+		}
+
+		// the circuit is half-open, the other queries are rejected until this one completes.
+		// The probe is completed even if the query panics so that the circuit is not kept half-open.
+		b.probing = true
+		defer b.completeProbe()
+	}
+	b.mu.Unlock()
+
+	records, err := b.Dao.Find(options)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.failures++
+		if b.failures >= b.Threshold {
+			if b.failures == b.Threshold {
+				log.Printf("Opening the circuit of the records for %v after %v failures: %v", b.Cooldown, b.failures, err)
+			}
+			// the cooldown starts when the query fails rather than when it is sent,
+			// since a failing query may take as long as its timeout.
+			b.openedAt = b.clock()
+		}
+		return records, err
+	}
+
+	if b.failures >= b.Threshold {
+		log.Printf("Closing the circuit of the records, the database is available again.")
+	}
+	b.failures = 0
+	return records, nil
+}
+
+func (b *CircuitBreaker) completeProbe() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *CircuitBreaker) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+
+	return time.Now()
+}
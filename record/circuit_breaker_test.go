@@ -0,0 +1,146 @@
+package record
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_Find(t *testing.T) {
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	var finds int
+	var findErr error
+	breaker := &CircuitBreaker{
+		Dao: mockDao{FindMock: func() ([]Dto, error) {
+			finds++
+			return nil, findErr
+		}},
+		Threshold: 2,
+		Cooldown:  10 * time.Second,
+		now:       func() time.Time { return now },
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		findErr error
+		finds   int
+		err     error
+	}{
+		{0, errors.New("connection refused"), 1, errors.New("connection refused")},
+		{0, errors.New("connection refused"), 2, errors.New("connection refused")},
+		// the circuit is open after 2 failures.
+		{time.Second, nil, 2, ErrCircuitOpen},
+		// a query is let through after the cooldown, the circuit is opened again as it fails.
+		{10 * time.Second, errors.New("connection refused"), 3, errors.New("connection refused")},
+		{time.Second, nil, 3, ErrCircuitOpen},
+		// the circuit is closed as the query after the cooldown succeeds.
+		{10 * time.Second, nil, 4, nil},
+		{0, errors.New("connection refused"), 5, errors.New("connection refused")},
+		{0, nil, 6, nil},
+	}
+
+	for i, test := range tests {
+		now = now.Add(test.elapsed)
+		findErr = test.findErr
+
+		_, err := breaker.Find(FilterOptions{})
+		if (err == nil) != (test.err == nil) || (errors.Is(test.err, ErrCircuitOpen) && !errors.Is(err, ErrCircuitOpen)) {
+			t.Errorf("returned incorrect error of query %v. got: %v, expected: %v", i, err, test.err)
+		}
+
+		if finds != test.finds {
+			t.Errorf("returned incorrect number of finds after query %v. got: %v, expected: %v", i, finds, test.finds)
+		}
+	}
+}
+
+func TestCircuitBreaker_FindHalfOpen(t *testing.T) {
+	release := make(chan struct{})
+	breaker := &CircuitBreaker{
+		Dao: mockDao{FindMock: func() ([]Dto, error) {
+			<-release
+			return mockData, nil
+		}},
+		Threshold: 1,
+		failures:  1,
+	}
+
+	probed := make(chan error)
+	go func() {
+		_, err := breaker.Find(FilterOptions{})
+		probed <- err
+	}()
+
+	// the other queries are rejected while the circuit is probed.
+	for {
+		breaker.mu.Lock()
+		probing := breaker.probing
+		breaker.mu.Unlock()
+		if probing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, err := breaker.Find(FilterOptions{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("returned incorrect error while probing. got: %v, expected: %v", err, ErrCircuitOpen)
+	}
+
+	close(release)
+	if err := <-probed; err != nil {
+		t.Errorf("returned incorrect error of the probe. got: %v, expected: nil", err)
+	}
+}
+
+func TestCircuitBreaker_FindSlowFailure(t *testing.T) {
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	breaker := &CircuitBreaker{
+		Dao: mockDao{FindMock: func() ([]Dto, error) {
+			// the query fails after its timeout.
+			now = now.Add(30 * time.Second)
+			return nil, errors.New("context deadline exceeded")
+		}},
+		Threshold: 1,
+		Cooldown:  10 * time.Second,
+		now:       func() time.Time { return now },
+	}
+
+	if _, err := breaker.Find(FilterOptions{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Error on testing: %v", err)
+	}
+
+	// the cooldown starts when the query fails, the circuit is still open.
+	now = now.Add(time.Second)
+	if _, err := breaker.Find(FilterOptions{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("returned incorrect error within the cooldown. got: %v, expected: %v", err, ErrCircuitOpen)
+	}
+}
+
+func TestCircuitBreaker_FindProbePanics(t *testing.T) {
+	panics := true
+	breaker := &CircuitBreaker{
+		Dao: mockDao{FindMock: func() ([]Dto, error) {
+			if panics {
+				panic("query panicked")
+			}
+			return mockData, nil
+		}},
+		Threshold: 1,
+		failures:  1,
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("returned incorrect panic of the probe. got: nil, expected: query panicked")
+			}
+		}()
+		breaker.Find(FilterOptions{})
+	}()
+
+	// the circuit is probed again rather than kept half-open.
+	panics = false
+	if _, err := breaker.Find(FilterOptions{}); err != nil {
+		t.Errorf("returned incorrect error after the probe panicked. got: %v, expected: nil", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -31,11 +32,6 @@ type Controller struct{
 }
 
 func (c Controller) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	// the endpoint always returns JSON response.
-	// to notice the client about the content type,
-	// it is a great practice to specify in header.
-	rw.Header().Add("Content-Type", "application/json")
-
 	// for this case, only post method should be handled.
 	// the other methods are not allowed.
 	switch req.Method {
@@ -74,6 +70,13 @@ func (c Controller) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			log.Printf("Error on fetching from the service: %v", err)
 			statusCode = http.StatusInternalServerError
 		}
+		if resp.Cache != "" {
+			rw.Header().Set("Cache-Status", string(resp.Cache))
+		}
+		if resp.Stale {
+			rw.Header().Set("Age", strconv.FormatInt(int64(resp.Age.Seconds()), 10))
+			rw.Header().Set("Warning", `110 - "Response is Stale"`)
+		}
 		c.writeResponse(rw, statusCode, resp)
 	default:
 		c.methodNotAllowed(rw)
@@ -129,8 +132,11 @@ func (c Controller) methodNotAllowed(rw http.ResponseWriter) {
 
 // writeResponse converts the response object to byte slice and writes it to response body.
 func (c Controller) writeResponse(rw http.ResponseWriter, statusCode int, resp Response) {
-	log.Printf("Sending response statusCode: %v, response: %+v", statusCode, resp)
+	writeResponse(rw, statusCode, resp)
+}
 
+// writeResponse converts the response object to byte slice and writes it to response body.
+func writeResponse(rw http.ResponseWriter, statusCode int, resp Response) {
 	respBytes, err := json.Marshal(resp)
 	if err != nil {
 		log.Printf("Error on marshalling to JSON: %v", err)
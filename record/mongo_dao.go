@@ -9,8 +9,10 @@ import (
 
 // MongoDao manages access of the MongoDB database.
 // it is used by a Service to construct a view model.
+// OnError is called with the operation when an error occurs, if it is set.
 type MongoDao struct{
 	Db *mongodb.Connection
+	OnError func(operation string, err error)
 }
 
 // Find fetches the records filtering them according to tha values specified with FilterOptions.
@@ -44,6 +46,7 @@ func (d MongoDao) Find(options FilterOptions) ([]Dto, error) {
 	})
 	if err != nil {
 		log.Printf("Error on finding in collection: %v", err)
+		d.reportError("find", err)
 		return records, err
 	}
 
@@ -51,6 +54,7 @@ func (d MongoDao) Find(options FilterOptions) ([]Dto, error) {
 	err = cursor.All(context.Background(), &bsonRecords)
 	if err != nil {
 		log.Printf("error on iterating over the cursor: %v", err)
+		d.reportError("find", err)
 		return records, err
 	}
 
@@ -59,6 +63,7 @@ func (d MongoDao) Find(options FilterOptions) ([]Dto, error) {
 		bsonBytes, err := bson.Marshal(rec)
 		if err != nil {
 			log.Printf("error on marshalling BSON: %v", err)
+			d.reportError("find", err)
 			return records, err
 		}
 
@@ -67,6 +72,7 @@ func (d MongoDao) Find(options FilterOptions) ([]Dto, error) {
 		err = bson.Unmarshal(bsonBytes, &entity)
 		if err != nil {
 			log.Printf("error on unmarshalling BSON: %v", err)
+			d.reportError("find", err)
 			return records, err
 		}
 
@@ -80,4 +86,11 @@ func (d MongoDao) Find(options FilterOptions) ([]Dto, error) {
 	}
 
 	return records, nil
-}
\ No newline at end of file
+}
+
+// reportError reports the error occurred in the operation to OnError.
+func (d MongoDao) reportError(operation string, err error) {
+	if d.OnError != nil {
+		d.OnError(operation, err)
+	}
+}
@@ -0,0 +1,62 @@
+package record
+
+import (
+	"errors"
+	"sync"
+)
+
+// errQueryPanicked is returned to the coalesced requests if the query they wait for panics.
+var errQueryPanicked = errors.New("the coalesced query panicked")
+
+// query is a query in flight, records and err are set before done is closed.
+type query struct {
+	done    chan struct{}
+	records []Dto
+	err     error
+}
+
+// QueryGroup deduplicates the identical queries in flight. The requests arriving while
+// a query of the same filter is in flight wait for it and share its result, rather than
+// running a query of their own. OnCoalesced is called for each of them, if it is set.
+type QueryGroup struct {
+	OnCoalesced func()
+
+	mu      sync.Mutex
+	queries map[string]*query
+}
+
+// Find runs find for the filter unless a query of the same filter is in flight,
+// in which case it waits for the query and returns its result. The records
+// returned are shared by the coalesced requests, they must not be modified.
+func (g *QueryGroup) Find(options FilterOptions, find func(options FilterOptions) ([]Dto, error)) ([]Dto, error) {
+	key := keyOf(options)
+
+	g.mu.Lock()
+	if q, ok := g.queries[key]; ok {
+		g.mu.Unlock()
+		if g.OnCoalesced != nil {
+			g.OnCoalesced()
+		}
+
+		<-q.done
+		return q.records, q.err
+	}
+
+	if g.queries == nil {
+		g.queries = make(map[string]*query)
+	}
+	q := &query{done: make(chan struct{}), err: errQueryPanicked}
+	g.queries[key] = q
+	g.mu.Unlock()
+
+	// the waiters are released even if the query panics.
+	defer func() {
+		g.mu.Lock()
+		delete(g.queries, key)
+		g.mu.Unlock()
+		close(q.done)
+	}()
+
+	q.records, q.err = find(options)
+	return q.records, q.err
+}
@@ -0,0 +1,86 @@
+package record
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestService_FetchCoalesced(t *testing.T) {
+	var finds, coalesced int32
+	release := make(chan struct{})
+	mock := mockDao{
+		FindMock: func() ([]Dto, error) {
+			atomic.AddInt32(&finds, 1)
+			<-release
+			return mockData, nil
+		},
+	}
+
+	service := Service{Dao: mock, Queries: &QueryGroup{OnCoalesced: func() {
+		atomic.AddInt32(&coalesced, 1)
+	}}}
+	options := FilterOptions{StartDate: time.Date(2016, 1, 26, 0, 0, 0, 0, time.UTC), MinCount: 2700, MaxCount: 3000}
+
+	var wg sync.WaitGroup
+	responses := make([]Response, 10)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = service.Fetch(options)
+		}(i)
+	}
+
+	for atomic.LoadInt32(&coalesced) != int32(len(responses)-1) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if finds != 1 {
+		t.Errorf("returned incorrect number of finds. got: %v, expected: %v", finds, 1)
+	}
+
+	for i, resp := range responses {
+		if resp.Code != 0 || len(resp.Records) != len(mockData) {
+			t.Errorf("returned incorrect response %v. got: %+v", i, resp)
+		}
+	}
+
+	// the queries are not coalesced once they are completed.
+	if _, err := service.Fetch(options); err != nil || finds != 2 {
+		t.Errorf("returned incorrect number of finds after the query. got: %v %v, expected: %v", finds, err, 2)
+	}
+}
+
+func TestQueryGroup_FindPanic(t *testing.T) {
+	joined, coalesced := make(chan struct{}), make(chan error, 1)
+	group := &QueryGroup{OnCoalesced: func() {
+		close(joined)
+	}}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Error on testing: the query did not panic")
+			}
+		}()
+
+		group.Find(FilterOptions{}, func(options FilterOptions) ([]Dto, error) {
+			// another request joins the query before it panics.
+			go func() {
+				_, err := group.Find(FilterOptions{}, nil)
+				coalesced <- err
+			}()
+			<-joined
+			panic("connection reset")
+		})
+	}()
+
+	if err := <-coalesced; !errors.Is(err, errQueryPanicked) {
+		t.Errorf("returned incorrect error. got: %v, expected: %v", err, errQueryPanicked)
+	}
+}
@@ -0,0 +1,82 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"strconv"
+	"time"
+)
+
+// CacheKeyPrefix prefixes the keys of the cached records in the database.
+const CacheKeyPrefix = "records:cache:"
+
+// cacheGenerationKey holds the current generation of the cached records.
+const cacheGenerationKey = CacheKeyPrefix + "generation"
+
+// getCacheScript reads the current generation and the entry of the filter in it,
+// in a single round trip so that the entry is consistent with the generation.
+var getCacheScript = redis.NewScript(`
+local generation = redis.call('GET', KEYS[1]) or '0'
+local key = ARGV[1] .. generation .. ':' .. ARGV[2]
+local records = redis.call('GET', key)
+if not records then
+	return {generation}
+end
+return {generation, records, redis.call('PTTL', key)}
+`)
+
+// RedisCacheDao stores the cached records in the Redis database,
+// so that the cache is shared by the replicas of the application.
+type RedisCacheDao struct {
+	Db *redis.Client
+}
+
+// Get reads the records of the key in the current generation.
+func (d RedisCacheDao) Get(key string) (CacheEntry, error) {
+	reply, err := getCacheScript.Run(context.Background(), d.Db, []string{cacheGenerationKey}, CacheKeyPrefix, key).Slice()
+	if err != nil {
+		return CacheEntry{}, err
+	}
+
+	if len(reply) == 0 {
+		return CacheEntry{}, fmt.Errorf("unexpected reply of the records cache script: %v", reply)
+	}
+
+	generationReply, _ := reply[0].(string)
+	generation, err := strconv.ParseInt(generationReply, 10, 64)
+	if err != nil {
+		return CacheEntry{}, fmt.Errorf("unexpected generation of the records cache: %v", reply[0])
+	}
+
+	entry := CacheEntry{Generation: generation}
+	if len(reply) < 3 {
+		return entry, nil
+	}
+
+	recordsReply, _ := reply[1].(string)
+	if err := json.Unmarshal([]byte(recordsReply), &entry.Records); err != nil {
+		return CacheEntry{}, fmt.Errorf("unexpected records in the cache: %w", err)
+	}
+
+	ttl, _ := reply[2].(int64)
+	entry.Found = true
+	entry.Ttl = time.Duration(ttl) * time.Millisecond
+	return entry, nil
+}
+
+// Set stores the records of the key in the generation, they expire after ttl.
+func (d RedisCacheDao) Set(generation int64, key string, records []Dto, ttl time.Duration) error {
+	value, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	return d.Db.Set(context.Background(), fmt.Sprintf("%v%v:%v", CacheKeyPrefix, generation, key), value, ttl).Err()
+}
+
+// Invalidate starts a new generation, the entries of the previous generations expire by their ttl.
+func (d RedisCacheDao) Invalidate() error {
+	return d.Db.Incr(context.Background(), cacheGenerationKey).Err()
+}
@@ -1,8 +1,20 @@
 package record
 
+import "time"
+
+// CacheStatus is the status of a response served through a cache, in the format of Cache-Status header.
+type CacheStatus string
+
 // Response represents the response payload.
+// Cache is not a part of the payload, it is sent in Cache-Status header if the response is served through a cache.
+// The records are Stale if they are the last records found for the filter while the database is not available,
+// Age is how long ago they are found. They are sent in Age and Warning headers rather than the payload.
 type Response struct{
 	Code int `json:"code"`
 	Message string `json:"msg"`
 	Records []Dto `json:"records"`
+
+	Cache CacheStatus `json:"-"`
+	Stale bool `json:"-"`
+	Age time.Duration `json:"-"`
 }
\ No newline at end of file
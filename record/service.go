@@ -1,6 +1,8 @@
 // Package record
 package record
 
+import "log"
+
 // Dao interface is used in Service to access data.
 type Dao interface{
 	Find(options FilterOptions) ([]Dto, error)
@@ -9,15 +11,40 @@ type Dao interface{
 // Service used by a Controller to interact with a database.
 // it implements Repository interface to abstract
 // the operations behind the scenes from the Controller and make the code easier to test.
+// The identical queries in flight share a single Dao.Find call if Queries is set.
+// If Stale is set, the last records found for a filter are served while the Dao fails.
 type Service struct{
 	Dao Dao
+	Queries *QueryGroup
+	Stale *StaleStore
 }
 
 // Fetch fetching the records from the Dao by filtering via FilterOptions
 // creates a response and returns it.
 func (s Service) Fetch(options FilterOptions) (Response, error) {
-	records, err := s.Dao.Find(options)
+	var records []Dto
+	var err error
+	if s.Queries != nil {
+		records, err = s.Queries.Find(options, s.Dao.Find)
+	} else {
+		records, err = s.Dao.Find(options)
+	}
+
 	if err != nil {
+		if s.Stale != nil {
+			if stale, age, ok := s.Stale.Get(keyOf(options)); ok {
+				log.Printf("Error on finding the records, serving the records found %v ago: %v", age, err)
+				resp := Response{
+					Code:    0,
+					Message: "Success",
+					Records: stale,
+					Stale:   true,
+					Age:     age,
+				}
+				return resp, nil
+			}
+		}
+
 		resp := Response{
 			Code:    3,
 			Message: "internal server error occurred.",
@@ -26,6 +53,10 @@ func (s Service) Fetch(options FilterOptions) (Response, error) {
 		return resp, err
 	}
 
+	if s.Stale != nil {
+		s.Stale.Put(keyOf(options), records)
+	}
+
 	resp := Response{
 		Code:    0,
 		Message: "Success",
@@ -0,0 +1,80 @@
+package record
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// staleEntry is the last records found for a filter.
+type staleEntry struct {
+	key     string
+	records []Dto
+	foundAt time.Time
+}
+
+// StaleStore keeps the last records found for the filters in memory, so that they can be served
+// while the database is not available. It holds MaxEntries filters at most, the filters used least
+// recently are removed first. The records found more than MaxAge ago are not served, unless it is zero.
+type StaleStore struct {
+	MaxEntries int
+	MaxAge     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+// NewStaleStore creates a store of the last records of maxEntries filters found within maxAge.
+func NewStaleStore(maxEntries int, maxAge time.Duration) *StaleStore {
+	return &StaleStore{
+		MaxEntries: maxEntries,
+		MaxAge:     maxAge,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Put stores the records found for the filter of the key.
+func (s *StaleStore) Put(key string, records []Dto) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := &staleEntry{key: key, records: records, foundAt: s.now()}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(entry)
+	for s.order.Len() > s.MaxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*staleEntry).key)
+	}
+}
+
+// Get returns the last records found for the filter of the key and how long ago they are found.
+func (s *StaleStore) Get(key string) ([]Dto, time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, 0, false
+	}
+
+	entry := element.Value.(*staleEntry)
+	age := s.now().Sub(entry.foundAt)
+	if s.MaxAge > 0 && age > s.MaxAge {
+		s.order.Remove(element)
+		delete(s.entries, key)
+		return nil, 0, false
+	}
+
+	s.order.MoveToFront(element)
+	return entry.records, age, true
+}
@@ -0,0 +1,90 @@
+package record
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaleStore(t *testing.T) {
+	now := time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC)
+	store := NewStaleStore(2, time.Hour)
+	store.now = func() time.Time { return now }
+
+	store.Put("a", mockData[:1])
+	store.Put("b", mockData[:2])
+	now = now.Add(time.Minute)
+
+	// "a" is used after "b", "b" is removed first.
+	if records, age, ok := store.Get("a"); !ok || len(records) != 1 || age != time.Minute {
+		t.Errorf("returned incorrect entry of a. got: %v %v %v, expected: 1 record found a minute ago", records, age, ok)
+	}
+
+	store.Put("c", mockData)
+	if _, _, ok := store.Get("b"); ok {
+		t.Errorf("returned incorrect entry of b. got: %v, expected: the entry is removed", ok)
+	}
+
+	now = now.Add(time.Hour)
+	if records, _, ok := store.Get("c"); !ok || len(records) != len(mockData) {
+		t.Errorf("returned incorrect entry of c. got: %v %v, expected: %v records", records, ok, len(mockData))
+	}
+
+	if _, _, ok := store.Get("a"); ok {
+		t.Errorf("returned incorrect entry of a. got: %v, expected: the entry is expired", ok)
+	}
+}
+
+func TestService_FetchStale(t *testing.T) {
+	var findErr error
+	service := Service{
+		Dao: mockDao{FindMock: func() ([]Dto, error) {
+			return mockData, findErr
+		}},
+		Stale: NewStaleStore(10, 0),
+	}
+	found := FilterOptions{MinCount: 100}
+
+	if got, err := service.Fetch(found); err != nil || got.Stale {
+		t.Errorf("returned incorrect response. got: %+v %v, expected: fresh records", got, err)
+	}
+
+	findErr = ErrCircuitOpen
+	got, err := service.Fetch(found)
+	if err != nil || got.Code != 0 || !got.Stale || len(got.Records) != len(mockData) {
+		t.Errorf("returned incorrect response. got: %+v %v, expected: stale records", got, err)
+	}
+
+	// the filters which are not found before can not be served.
+	got, err = service.Fetch(FilterOptions{MinCount: 200})
+	if !errors.Is(err, ErrCircuitOpen) || got.Code != 3 {
+		t.Errorf("returned incorrect response. got: %+v %v, expected: code 3", got, err)
+	}
+}
+
+func TestController_ServeHTTPStale(t *testing.T) {
+	mock := mockService{
+		FetchMock: func(options FilterOptions) (Response, error) {
+			return Response{Code: 0, Message: "Success", Records: mockData, Stale: true, Age: 90500 * time.Millisecond}, nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/records", strings.NewReader(`{"startDate":"2017-01-27","endDate":"2017-01-29","minCount":0,"maxCount":1000}`))
+	rr := httptest.NewRecorder()
+	Controller{Repository: mock}.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("returned incorrect status code. got: %v, expected: %v", rr.Code, http.StatusOK)
+	}
+
+	if age := rr.Header().Get("Age"); age != "90" {
+		t.Errorf("returned incorrect age. got: %v, expected: %v", age, "90")
+	}
+
+	if warning := rr.Header().Get("Warning"); warning != `110 - "Response is Stale"` {
+		t.Errorf("returned incorrect warning. got: %v, expected: %v", warning, `110 - "Response is Stale"`)
+	}
+}